@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/sentry"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Daily fiat amount of every ticker to order for today, after checking that the available balance of each quote
+// currency on each exchange covers them. Handled by BalanceCheckMode otherwise
+//
+// Fills of a crashed run today, as per the journal, are already paid for and are excluded from the required balance.
+// Unspent fiat amounts of previous days, carriedForwardAmounts, are added to the daily fiat amount of tickers turned on,
+// dailyFiatBudgets, see getDailyFiatBudgets
+func getDailyFiatAmounts(ctx context.Context, doneTickers map[string]bool, dailyFiatBudgets, carriedForwardAmounts map[string]float64) (map[string]float64, error) {
+	c := config.Get()
+
+	dailyFiatAmounts := make(map[string]float64, len(c.CryptoTickers))
+	for ticker := range c.CryptoTickers {
+		dailyFiatAmounts[ticker] = dailyFiatBudgets[ticker]
+		if dailyFiatAmounts[ticker] > 0 {
+			dailyFiatAmounts[ticker] += carriedForwardAmounts[ticker]
+		}
+	}
+
+	// Tickers to order for today, grouped by exchange then by quote currency. Simulated orders are not placed on the
+	// exchange
+	tickersByExchange := make(map[string]map[string][]string)
+	fiatSpent := make(map[string]float64)
+	for ticker, dailyFiatAmount := range dailyFiatAmounts {
+		if dailyFiatAmount <= 0 || doneTickers[ticker] || !c.IsDueToday(ticker) || c.IsSimulatedOrder(ticker) {
+			continue
+		}
+		if entry := getJournalEntry(ticker); entry != nil {
+			fiatSpent[ticker] = entry.FiatSpent
+		}
+		exchangeName := c.GetExchange(ticker)
+		if _, ok := tickersByExchange[exchangeName]; !ok {
+			tickersByExchange[exchangeName] = make(map[string][]string)
+		}
+		quoteCurrency := c.GetQuoteCurrency(ticker)
+		tickersByExchange[exchangeName][quoteCurrency] = append(tickersByExchange[exchangeName][quoteCurrency], ticker)
+	}
+
+	for exchangeName, tickersByCurrency := range tickersByExchange {
+		if err := checkAvailableBalances(ctx, exchangeName, tickersByCurrency, dailyFiatAmounts, fiatSpent); err != nil {
+			return nil, err
+		}
+	}
+
+	return dailyFiatAmounts, nil
+}
+
+// Checks the available balances of the exchange against the daily fiat amounts of its tickers, grouped by quote
+// currency, adjusting dailyFiatAmounts as per BalanceCheckMode
+func checkAvailableBalances(ctx context.Context, exchangeName string, tickersByCurrency map[string][]string, dailyFiatAmounts, fiatSpent map[string]float64) error {
+	location := "cmd.checkAvailableBalances"
+	c := config.Get()
+
+	availableBalances, err := util.Retry(ctx, fmt.Sprintf("GetAvailableBalances - %v", exchangeName), func() (map[string]float64, error) {
+		return exchange.GetByName(exchangeName).GetAvailableBalances(ctx)
+	})
+	if err != nil {
+		logger.Error(location, "Error getting available balances of '%s'", err, exchangeName)
+		return err
+	}
+	for quoteCurrency, tickers := range tickersByCurrency {
+		// Trading fees are charged on top of the fiat amount
+		required := float64(0)
+		for _, ticker := range tickers {
+			required += requiredBalance(ticker, dailyFiatAmounts[ticker], fiatSpent[ticker])
+		}
+		available := availableBalances[quoteCurrency]
+		if available >= required {
+			continue
+		}
+
+		logger.Warn(location, "Available %s balance of %v on '%s' is insufficient for %v, handling with mode '%s'", quoteCurrency, available, exchangeName, required, c.OrderMetadata.BalanceCheckMode)
+		switch c.OrderMetadata.BalanceCheckMode {
+		case config.BalanceCheckModeScale:
+			ratio := available / required
+			for _, ticker := range tickers {
+				dailyFiatAmounts[ticker] = fiatSpent[ticker] + (dailyFiatAmounts[ticker]-fiatSpent[ticker])*ratio
+				logger.Warn(location, "'%s' Scaled daily fiat amount down to %v", ticker, dailyFiatAmounts[ticker])
+			}
+		case config.BalanceCheckModeSkip:
+			// Highest priority first, keeping every ticker that still fits in the balance left
+			sortTickersByPriority(tickers)
+			for _, ticker := range tickers {
+				tickerRequired := requiredBalance(ticker, dailyFiatAmounts[ticker], fiatSpent[ticker])
+				if tickerRequired <= available {
+					available -= tickerRequired
+					continue
+				}
+				logger.Warn(location, "'%s' Skipped for insufficient %s balance", ticker, quoteCurrency)
+				dailyFiatAmounts[ticker] = fiatSpent[ticker]
+			}
+		default:
+			err := fmt.Errorf("insufficient %s balance on %s, available: %v, required: %v", quoteCurrency, exchangeName, available, required)
+			logger.Error(location, "Aborting run", err)
+			sentry.CaptureErr(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Balance required for the rest of the daily fiat amount, including the trading fees of the ticker's exchange
+func requiredBalance(ticker string, dailyFiatAmount, fiatSpent float64) float64 {
+	return math.Max(dailyFiatAmount-fiatSpent, 0) * (1 + exchange.Get(ticker).GetMakerTradingFee())
+}
+
+// Highest priority first as per TickerPriorities, then by name
+func sortTickersByPriority(tickers []string) {
+	priorities := config.Get().OrderMetadata.TickerPriorities
+	sort.Slice(tickers, func(i, k int) bool {
+		pi, pk := priorities[tickers[i]], priorities[tickers[k]]
+		if pi != pk {
+			return pi < pk
+		}
+		return tickers[i] < tickers[k]
+	})
+}
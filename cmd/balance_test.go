@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/journal"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getDailyFiatAmounts(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+
+	// BTC & ETH are both quoted in SGD, requiring (1 + 2) * 1.002 = 3.006 SGD in total
+	balancesResponder := func(sgd string) {
+		responder := httpmock.NewStringResponder(http.StatusOK, `[
+			{"type": "exchange", "currency": "SGD", "amount": "`+sgd+`", "available": "`+sgd+`"},
+			{"type": "exchange", "currency": "USD", "amount": "100", "available": "100"}
+		]`)
+		httpmock.RegisterResponder(http.MethodPost, gemini.BalancesURI, responder)
+	}
+
+	tests := []struct {
+		name         string
+		isSandboxEnv bool
+		exchanges    map[string]string
+		mode         string
+		priorities   map[string]int
+		doneTickers  map[string]bool
+		carried      map[string]float64
+		setup        func() func()
+		want         map[string]float64
+		wantErr      bool
+	}{
+		{
+			name:         "ok_sandbox",
+			isSandboxEnv: true,
+			setup: func() func() {
+				balancesResponder("3.01")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name:         "ok_sandbox_simulated",
+			isSandboxEnv: true,
+			exchanges:    map[string]string{"BTC": config.ExchangeKraken, "ETH": config.ExchangeKraken},
+			setup: func() func() {
+				return func() {}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name: "ok_sufficient",
+			setup: func() func() {
+				balancesResponder("3.01")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name:        "ok_all_done",
+			doneTickers: map[string]bool{"BTC": true, "ETH": true},
+			setup: func() func() {
+				return func() {}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name:        "ok_done_ticker_excluded",
+			doneTickers: map[string]bool{"ETH": true},
+			setup: func() func() {
+				balancesResponder("1.01")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name:    "ok_carried_forward",
+			carried: map[string]float64{"BTC": 0.5},
+			setup: func() func() {
+				// (1.5 + 2) * 1.002 = 3.507 SGD
+				balancesResponder("3.51")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 1.5, "ETH": 2},
+		},
+		{
+			name: "ok_scale",
+			mode: config.BalanceCheckModeScale,
+			setup: func() func() {
+				balancesResponder("1.503")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 0.5, "ETH": 1},
+		},
+		{
+			name: "ok_scale_journaled_fills_excluded",
+			mode: config.BalanceCheckModeScale,
+			setup: func() func() {
+				// 1 SGD of ETH is already spent, requiring (1 + 1) * 1.002 = 2.004 SGD
+				_ = journal.Get().Put(&journal.Entry{Ticker: "ETH", CreatedForDay: config.TestNowDate, FiatSpent: 1})
+				balancesResponder("1.002")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 0.5, "ETH": 1.5},
+		},
+		{
+			name:       "ok_skip",
+			mode:       config.BalanceCheckModeSkip,
+			priorities: map[string]int{"ETH": 1, "BTC": 2},
+			setup: func() func() {
+				balancesResponder("2.5")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 0, "ETH": 2},
+		},
+		{
+			name: "ok_skip_default_priority",
+			mode: config.BalanceCheckModeSkip,
+			setup: func() func() {
+				balancesResponder("1.5")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{"BTC": 1, "ETH": 0},
+		},
+		{
+			name: "error_abort",
+			setup: func() func() {
+				balancesResponder("3")
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "error_balances",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, gemini.BalancesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
+			config.TestInit(&config.ConfigUpdateable{
+				IsSandboxEnv:     util.PtrOf(tt.isSandboxEnv),
+				Exchanges:        tt.exchanges,
+				BalanceCheckMode: tt.mode,
+				TickerPriorities: tt.priorities,
+			}, &config.TestNow)
+
+			teardown := tt.setup()
+			got, err := getDailyFiatAmounts(ctx, tt.doneTickers, config.Get().OrderMetadata.DailyFiatAmount, tt.carried)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, len(tt.want), len(got))
+			for ticker, want := range tt.want {
+				assert.InDelta(t, want, got[ticker], 1e-9, ticker)
+			}
+			teardown()
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"math"
+
+	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Unspent daily fiat amounts of previous days that have not expired, oldest first, of every ticker with a carry
+// forward cap. Carrying forward is best effort, the run carries on without it should the db fail
+//
+// Simulated orders are not carried forward, see config.IsSimulatedOrder
+func getCarryForwards() map[string][]*db.CarryForward {
+	location := "cmd.getCarryForwards"
+	c := config.Get()
+	carryForwards := make(map[string][]*db.CarryForward)
+	if len(c.OrderMetadata.CarryForward.Caps) == 0 {
+		return carryForwards
+	}
+
+	today := config.GetTime().GetTodayDate()
+	rows, err := db.Get().GetRemainingCarryForwardsSince(today.AddDate(0, 0, -c.OrderMetadata.CarryForward.ExpiryDays))
+	if err != nil {
+		logger.Error(location, "Error getting carry forwards, carrying on without them", err)
+		return carryForwards
+	}
+
+	tickers := carryForwardTickers()
+	for _, row := range rows {
+		ticker, ok := tickers[row.Ticker]
+		// Unspent amounts of today are only carried from tomorrow onwards
+		if !ok || !row.CreatedForDay.Before(today) || c.IsSimulatedOrder(ticker) {
+			continue
+		}
+		carryForwards[ticker] = append(carryForwards[ticker], row)
+	}
+
+	return carryForwards
+}
+
+// Fiat amount carried forward to today of every ticker, up to its cap
+func getCarriedForwardAmounts(carryForwards map[string][]*db.CarryForward) map[string]float64 {
+	c := config.Get()
+	carriedForwardAmounts := make(map[string]float64, len(carryForwards))
+	for ticker, rows := range carryForwards {
+		remaining := float64(0)
+		for _, row := range rows {
+			remaining += row.Remaining
+		}
+		carriedForwardAmounts[ticker] = math.Min(remaining, c.OrderMetadata.CarryForward.Caps[ticker])
+	}
+	return carriedForwardAmounts
+}
+
+// Records today's unspent daily fiat amount of every ticker with a carry forward cap, and spends the carried forward
+// amounts oldest first if more than the daily fiat amount was spent
+//
+// Tickers turned off for the day, by the balance check or a spend cap, carry their whole daily fiat amount forward.
+// Tickers done before this run are left as is, their carry forwards are already accounted for. So are tickers not
+// scheduled for today & simulated orders
+func updateCarryForwards(postOrders *treemap.Map, doneTickers map[string]bool, carryForwards map[string][]*db.CarryForward, dailyFiatBudgets map[string]float64) {
+	location := "cmd.updateCarryForwards"
+	c := config.Get()
+	if len(c.OrderMetadata.CarryForward.Caps) == 0 {
+		return
+	}
+
+	today := config.GetTime().GetTodayDate()
+	rows := make([]*db.CarryForward, 0)
+	for ticker := range c.OrderMetadata.CarryForward.Caps {
+		if doneTickers[ticker] || !c.IsDueToday(ticker) || c.IsSimulatedOrder(ticker) {
+			continue
+		}
+		// Switched off in the config, there is no daily fiat amount to carry forward
+		dailyFiatAmount := math.Max(dailyFiatBudgets[ticker], 0)
+
+		spent := float64(0)
+		if v, ok := postOrders.Get(ticker); ok {
+			postOrder := v.(PostOrder)
+			spent = postOrder.AvgExecutionPrice * postOrder.ExecutedAmount
+		}
+
+		// Today's row is upserted even when nothing is left unspent, so that a rerun today spending what an earlier run
+		// left unspent zeroes it instead of carrying it forward twice
+		unspent := roundFiatAmount(math.Max(dailyFiatAmount-spent, 0))
+		if unspent > 0 {
+			logger.Info(location, "'%s' Carrying %v forward", ticker, unspent)
+		}
+		rows = append(rows, &db.CarryForward{
+			Ticker:        exchange.Pair(ticker),
+			CreatedForDay: today,
+			Amount:        unspent,
+			Remaining:     unspent,
+			CreatedAt:     config.GetTime().Now(),
+			UpdatedAt:     config.GetTime().Now(),
+		})
+
+		overspent := roundFiatAmount(spent - dailyFiatAmount)
+		for _, row := range carryForwards[ticker] {
+			if overspent <= 0 {
+				break
+			}
+			used := math.Min(row.Remaining, overspent)
+			overspent = roundFiatAmount(overspent - used)
+			row.Remaining = roundFiatAmount(row.Remaining - used)
+			row.UpdatedAt = config.GetTime().Now()
+			rows = append(rows, row)
+			logger.Info(location, "'%s' Spent %v carried forward from %v", ticker, used, row.CreatedForDay)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	if err := db.Get().BulkUpsertCarryForwards(rows); err != nil {
+		logger.Error(location, "Error upserting carry forwards", err)
+	}
+}
+
+// Sets the fiat amount carried forward to today on every ticker's order
+func addCarriedForwardAmounts(postOrders *treemap.Map, carriedForwardAmounts map[string]float64) {
+	it := postOrders.Iterator()
+	for it.Next() {
+		ticker, postOrder := it.Key().(string), it.Value().(PostOrder)
+		postOrder.CarriedForward = carriedForwardAmounts[ticker]
+		postOrders.Put(ticker, postOrder)
+	}
+}
+
+// Tickers with a carry forward cap, keyed by their db representation
+func carryForwardTickers() map[string]string {
+	c := config.Get()
+	tickers := make(map[string]string, len(c.OrderMetadata.CarryForward.Caps))
+	for ticker := range c.OrderMetadata.CarryForward.Caps {
+		tickers[exchange.Pair(ticker)] = ticker
+	}
+	return tickers
+}
+
+// Fiat amounts are kept to the cent so that float errors do not leave dust in the ledger
+func roundFiatAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/golang/mock/gomock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getCarryForwards(t *testing.T) {
+	yesterday := config.TestNowDate.AddDate(0, 0, -1)
+	expiry := config.TestNowDate.AddDate(0, 0, -7)
+
+	tests := []struct {
+		name         string
+		isSandboxEnv bool
+		exchanges    map[string]string
+		caps         map[string]float64
+		setup        func(*mocks.MockOrderRepository)
+		want         map[string][]*db.CarryForward
+		wantAmounts  map[string]float64
+	}{
+		{
+			// ETH is simulated on Kraken while BTC is placed on the Gemini sandbox
+			name:         "ok_simulated_skipped",
+			isSandboxEnv: true,
+			exchanges:    map[string]string{"ETH": config.ExchangeKraken},
+			caps:         map[string]float64{"BTC": 1, "ETH": 1},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetRemainingCarryForwardsSince(expiry).Return([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+					{Ticker: "ethsgd", CreatedForDay: yesterday, Amount: 2, Remaining: 2},
+				}, nil)
+			},
+			want: map[string][]*db.CarryForward{
+				"BTC": {
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+				},
+			},
+			wantAmounts: map[string]float64{"BTC": 1},
+		},
+		{
+			name:        "ok_no_caps",
+			setup:       func(orderDB *mocks.MockOrderRepository) {},
+			want:        map[string][]*db.CarryForward{},
+			wantAmounts: map[string]float64{},
+		},
+		{
+			name: "ok_capped",
+			caps: map[string]float64{"BTC": 1},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetRemainingCarryForwardsSince(expiry).Return([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: expiry, Amount: 1, Remaining: 0.5},
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+					// not carried yet
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 1, Remaining: 1},
+					// without a cap
+					{Ticker: "ethsgd", CreatedForDay: yesterday, Amount: 2, Remaining: 2},
+				}, nil)
+			},
+			want: map[string][]*db.CarryForward{
+				"BTC": {
+					{Ticker: "btcsgd", CreatedForDay: expiry, Amount: 1, Remaining: 0.5},
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+				},
+			},
+			wantAmounts: map[string]float64{"BTC": 1},
+		},
+		{
+			name: "ok_db_error",
+			caps: map[string]float64{"BTC": 1},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetRemainingCarryForwardsSince(expiry).Return(nil, errors.New("error"))
+			},
+			want:        map[string][]*db.CarryForward{},
+			wantAmounts: map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.TestInit(&config.ConfigUpdateable{
+				IsSandboxEnv:     util.PtrOf(tt.isSandboxEnv),
+				Exchanges:        tt.exchanges,
+				CarryForwardCaps: tt.caps,
+			}, &config.TestNow)
+			ctrl := gomock.NewController(t)
+			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+			db.Set(mockOrderDB)
+			tt.setup(mockOrderDB)
+
+			got := getCarryForwards()
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantAmounts, getCarriedForwardAmounts(got))
+		})
+	}
+}
+
+func Test_updateCarryForwards(t *testing.T) {
+	yesterday := config.TestNowDate.AddDate(0, 0, -1)
+	twoDaysAgo := config.TestNowDate.AddDate(0, 0, -2)
+
+	tests := []struct {
+		name             string
+		isSandboxEnv     bool
+		exchanges        map[string]string
+		postOrders       map[string]PostOrder
+		doneTickers      map[string]bool
+		carryForwards    map[string][]*db.CarryForward
+		dailyFiatBudgets map[string]float64
+		setup            func(*mocks.MockOrderRepository)
+	}{
+		{
+			name: "ok_unspent",
+			// BTC is partially filled & ETH is not filled at all
+			postOrders: map[string]PostOrder{
+				"BTC": {AvgExecutionPrice: 1000, ExecutedAmount: 0.0004},
+			},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards(gomock.InAnyOrder([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 0.6, Remaining: 0.6, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+					{Ticker: "ethsgd", CreatedForDay: config.TestNowDate, Amount: 2, Remaining: 2, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+				})).Return(nil)
+			},
+		},
+		{
+			name: "ok_spent_oldest_first",
+			postOrders: map[string]PostOrder{
+				"BTC": {AvgExecutionPrice: 1000, ExecutedAmount: 0.0018},
+				"ETH": {AvgExecutionPrice: 1000, ExecutedAmount: 0.002},
+			},
+			carryForwards: map[string][]*db.CarryForward{
+				"BTC": {
+					{Ticker: "btcsgd", CreatedForDay: twoDaysAgo, Amount: 1, Remaining: 0.5},
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+				},
+			},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards(gomock.InAnyOrder([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 0, Remaining: 0, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+					{Ticker: "btcsgd", CreatedForDay: twoDaysAgo, Amount: 1, Remaining: 0, UpdatedAt: config.TestNow},
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 0.7, UpdatedAt: config.TestNow},
+					{Ticker: "ethsgd", CreatedForDay: config.TestNowDate, Amount: 0, Remaining: 0, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+				})).Return(nil)
+			},
+		},
+		{
+			// An earlier run today left both unspent, this rerun spends all of it
+			name: "ok_rerun_zeroes_today",
+			postOrders: map[string]PostOrder{
+				"BTC": {AvgExecutionPrice: 1000, ExecutedAmount: 0.001},
+				"ETH": {AvgExecutionPrice: 1000, ExecutedAmount: 0.002},
+			},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards(gomock.InAnyOrder([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 0, Remaining: 0, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+					{Ticker: "ethsgd", CreatedForDay: config.TestNowDate, Amount: 0, Remaining: 0, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+				})).Return(nil)
+			},
+		},
+		{
+			// BTC is switched off in the config & ETH is turned off for the day, e.g. by the balance check
+			name:             "ok_turned_off",
+			dailyFiatBudgets: map[string]float64{"BTC": 0, "ETH": 2},
+			carryForwards: map[string][]*db.CarryForward{
+				"BTC": {
+					{Ticker: "btcsgd", CreatedForDay: yesterday, Amount: 1, Remaining: 1},
+				},
+			},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards(gomock.InAnyOrder([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 0, Remaining: 0, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+					{Ticker: "ethsgd", CreatedForDay: config.TestNowDate, Amount: 2, Remaining: 2, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+				})).Return(nil)
+			},
+		},
+		{
+			// ETH is simulated on Kraken while BTC is placed on the Gemini sandbox
+			name:         "ok_simulated_skipped",
+			isSandboxEnv: true,
+			exchanges:    map[string]string{"ETH": config.ExchangeKraken},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards([]*db.CarryForward{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate, Amount: 1, Remaining: 1, CreatedAt: config.TestNow, UpdatedAt: config.TestNow},
+				}).Return(nil)
+			},
+		},
+		{
+			name:        "ok_done_tickers_skipped",
+			doneTickers: map[string]bool{"BTC": true, "ETH": true},
+			setup:       func(orderDB *mocks.MockOrderRepository) {},
+		},
+		{
+			name: "ok_db_error",
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().BulkUpsertCarryForwards(gomock.Any()).Return(errors.New("error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.TestInit(&config.ConfigUpdateable{
+				IsSandboxEnv:     util.PtrOf(tt.isSandboxEnv),
+				Exchanges:        tt.exchanges,
+				CarryForwardCaps: map[string]float64{"BTC": 2, "ETH": 2},
+			}, &config.TestNow)
+			ctrl := gomock.NewController(t)
+			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+			db.Set(mockOrderDB)
+			tt.setup(mockOrderDB)
+
+			postOrders := treemap.NewWithStringComparator()
+			for ticker, postOrder := range tt.postOrders {
+				postOrders.Put(ticker, postOrder)
+			}
+			dailyFiatBudgets := tt.dailyFiatBudgets
+			if dailyFiatBudgets == nil {
+				dailyFiatBudgets = config.Get().OrderMetadata.DailyFiatAmount
+			}
+			updateCarryForwards(postOrders, tt.doneTickers, tt.carryForwards, dailyFiatBudgets)
+		})
+	}
+}
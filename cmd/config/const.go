@@ -8,8 +8,38 @@ const (
 	cryptoTickers_EnvKey             envKey = "CRYPTO_TICKERS"
 	geminiApiKey_EnvKey              envKey = "GEMINI_API_KEY"
 	geminiApiSecret_EnvKey           envKey = "GEMINI_API_SECRET"
+	geminiBaseUrl_EnvKey             envKey = "GEMINI_BASE_URL"
+	geminiRateLimits_EnvKey          envKey = "GEMINI_RATE_LIMITS"
+	geminiRateLimitWait_EnvKey       envKey = "GEMINI_RATE_LIMIT_WAIT_SECONDS"
+	geminiRequestTimeout_EnvKey      envKey = "GEMINI_REQUEST_TIMEOUT_SECONDS"
+	geminiNonceMode_EnvKey           envKey = "GEMINI_NONCE_MODE"
+	geminiNoncePath_EnvKey           envKey = "GEMINI_NONCE_PATH"
+	geminiOrderUpdates_EnvKey        envKey = "GEMINI_ORDER_UPDATES"
+	geminiMarketData_EnvKey          envKey = "GEMINI_MARKET_DATA"
 	dailyFiatAmounts_EnvKey          envKey = "DAILY_FIAT_AMOUNTS"
 	orderPriceToBidPriceRatio_EnvKey envKey = "ORDER_PRICE_TO_BID_PRICE_RATIO"
+	pricingStrategies_EnvKey         envKey = "PRICING_STRATEGIES"
+	repriceBidMoveRatio_EnvKey       envKey = "REPRICE_BID_MOVE_RATIO"
+	balanceCheckMode_EnvKey          envKey = "BALANCE_CHECK_MODE"
+	tickerPriorities_EnvKey          envKey = "TICKER_PRIORITIES"
+	fallbackStrategies_EnvKey        envKey = "FALLBACK_STRATEGIES"
+	carryForwardCaps_EnvKey          envKey = "CARRY_FORWARD_CAPS"
+	carryForwardExpiryDays_EnvKey    envKey = "CARRY_FORWARD_EXPIRY_DAYS"
+	monthlyFiatCaps_EnvKey           envKey = "MONTHLY_FIAT_CAPS"
+	globalDailyFiatCap_EnvKey        envKey = "GLOBAL_DAILY_FIAT_CAP"
+	globalMonthlyFiatCap_EnvKey      envKey = "GLOBAL_MONTHLY_FIAT_CAP"
+	schedules_EnvKey                 envKey = "SCHEDULES"
+	exchanges_EnvKey                 envKey = "EXCHANGES"
+	krakenApiKey_EnvKey              envKey = "KRAKEN_API_KEY"
+	krakenApiSecret_EnvKey           envKey = "KRAKEN_API_SECRET"
+	krakenRequestTimeout_EnvKey      envKey = "KRAKEN_REQUEST_TIMEOUT_SECONDS"
+	krakenNoncePath_EnvKey           envKey = "KRAKEN_NONCE_PATH"
+	reportingCurrency_EnvKey         envKey = "REPORTING_CURRENCY"
+	budgetCurrency_EnvKey            envKey = "BUDGET_CURRENCY"
+	fxRateProvider_EnvKey            envKey = "FX_RATE_PROVIDER"
+	fxRates_EnvKey                   envKey = "FX_RATES"
+	fxRatesPath_EnvKey               envKey = "FX_RATES_PATH"
+	fxApiUrl_EnvKey                  envKey = "FX_API_URL"
 
 	googleServiceAccountEmail_EnvKey      envKey = "GOOGLE_SERVICE_ACCOUNT_EMAIL"
 	googleServiceAccountPrivateKey_EnvKey envKey = "GOOGLE_SERVICE_ACCOUNT_PRIVATE_KEY"
@@ -26,14 +56,125 @@ const (
 	dbApiUrl_EnvKey   envKey = "DB_API_URL"
 	dbApiKey_EnvKey   envKey = "DB_API_KEY"
 	sentryDsn_EnvKey  envKey = "SENTRY_DSN"
+
+	journalStore_EnvKey envKey = "JOURNAL_STORE"
+	journalPath_EnvKey  envKey = "JOURNAL_PATH"
+
+	runMode_EnvKey               envKey = "RUN_MODE"
+	daemonRunTimes_EnvKey        envKey = "DAEMON_RUN_TIMES"
+	daemonTimezone_EnvKey        envKey = "DAEMON_TIMEZONE"
+	daemonStatusAddr_EnvKey      envKey = "DAEMON_STATUS_ADDR"
+	daemonShutdownTimeout_EnvKey envKey = "DAEMON_SHUTDOWN_TIMEOUT_SECONDS"
 )
 
 const (
 	production = "production" // ! NOT TO BE USED. To determine if env is production or not
 )
 
+const (
+	defaultJournalPath            = "journal.json"
+	defaultCarryForwardExpiryDays = 7
+	defaultDaemonShutdownTimeout  = 25 // seconds, within the usual grace period of container platforms
+	defaultFxApiUrl               = "https://open.er-api.com/v6/latest"
+	defaultGeminiRateLimitWait    = 30 // seconds, for a request to be let through by the rate limiter
+	defaultGeminiRequestTimeout   = 60 // seconds, for a request to complete, including its wait for the rate limiter
+	defaultGeminiNoncePath        = "gemini_nonce.json"
+	defaultKrakenRequestTimeout   = 60 // seconds, for a request to complete
+	defaultKrakenNoncePath        = "kraken_nonce.json"
+	defaultRepriceBidMoveRatio    = 0.002 // of the best bid, since the live order was placed
+)
+
+// Within the limits recommended by Gemini, i.e. 1 request per second to public endpoints, 5 to private endpoints
+var defaultGeminiRateLimits = GeminiRateLimits{
+	Public:  RateLimit{PerSecond: 1, Burst: 5},
+	Private: RateLimit{PerSecond: 5, Burst: 10},
+}
+
+// How the binary runs, defaults to RunModeOneShot
+const (
+	RunModeOneShot = "oneshot" // runs once then exits, scheduled externally
+	RunModeDaemon  = "daemon"  // stays alive, running every ticker at its DAEMON_RUN_TIMES
+)
+
+// Where the journal is stored, defaults to JournalStoreDb
+const (
+	JournalStoreDb   = "db"   // in the Journal table of the db, survives ephemeral filesystems, e.g. of Heroku dynos
+	JournalStoreFile = "file" // in the json file at JOURNAL_PATH, e.g. for local runs
+)
+
+// Nonces of private Gemini requests, defaults to NonceModeIncreasing
+const (
+	NonceModeIncreasing = "increasing"  // strictly increasing, its high-water mark is persisted to GEMINI_NONCE_PATH
+	NonceModeTimeWindow = "time_window" // current time in seconds, the API key must be created with a time based nonce
+)
+
+// How updates of live Gemini orders are received, defaults to OrderUpdatesWebsocket
+const (
+	OrderUpdatesWebsocket = "websocket" // pushed by the order events websocket, polled only while it is disconnected
+	OrderUpdatesPolling   = "polling"   // polled once every order window
+)
+
+// How Gemini market data, i.e. best bid & ask and the order book, is received, defaults to MarketDataWebsocket
+const (
+	MarketDataWebsocket = "websocket" // streamed by the market data websocket, polled from /v1/book while it is disconnected
+	MarketDataPolling   = "polling"   // polled from /v2/ticker & /v1/book whenever needed
+)
+
+// Exchange a ticker is bought on, selectable per ticker, defaults to ExchangeGemini
+const (
+	ExchangeGemini = "gemini"
+	ExchangeKraken = "kraken"
+)
+
+// Currency of DAILY_FIAT_AMOUNTS, defaults to BudgetCurrencyQuote
+const (
+	BudgetCurrencyQuote     = "quote"     // quote currency of each ticker, e.g. SGD for BTC/SGD
+	BudgetCurrencyReporting = "reporting" // REPORTING_CURRENCY, converted into the quote currency before sizing the order
+)
+
+// Source of the FX rates into the reporting currency, defaults to FxRateProviderHttp
+const (
+	FxRateProviderStatic = "static" // FX_RATES
+	FxRateProviderFile   = "file"   // FX_RATES_PATH, a JSON file of the same format as FX_RATES
+	FxRateProviderHttp   = "http"   // FX_API_URL, latest rates of an open.er-api.com compatible api
+)
+
+// Limit price strategies selectable per ticker, defaults to PricingStrategyBidRatio
+const (
+	PricingStrategyBidRatio      = "bid_ratio"       // best bid * ORDER_PRICE_TO_BID_PRICE_RATIO
+	PricingStrategyMidPrice      = "mid_price"       // mid of best bid and best ask
+	PricingStrategyAskMinusTicks = "ask_minus_ticks" // best ask less N price increments
+	PricingStrategyBookDepth     = "book_depth"      // bid level with enough resting volume ahead of the order
+)
+
+// Execution selectable per ticker once AfterWindows order windows are unfilled, there is no fallback by default
+const (
+	FallbackStrategyIocAtAsk        = "ioc_at_ask"       // immediate-or-cancel order at the best ask
+	FallbackStrategyAggressiveLimit = "aggressive_limit" // limit order at the best bid, raised by Step every window
+	FallbackStrategySkipAndCarry    = "skip_and_carry"   // stop for the day, carrying the unspent amount forward
+)
+
+// Days a ticker is bought on, selectable per ticker, defaults to every day
+const (
+	ScheduleDaily        = "daily"
+	ScheduleWeekdays     = "weekdays"       // on the given weekdays only
+	ScheduleEveryNDays   = "every_n_days"   // every N days from START_DATE
+	ScheduleFirstOfMonth = "first_of_month" // on the 1st of every month
+	ScheduleCron         = "cron"           // on days the cron expression fires, in UTC
+)
+
+// What to do when the available fiat balance of a quote currency cannot cover the daily fiat amounts of its tickers
+const (
+	BalanceCheckModeAbort = "abort" // abort the run, default
+	BalanceCheckModeScale = "scale" // scale down the daily fiat amounts proportionally
+	BalanceCheckModeSkip  = "skip"  // skip tickers of lowest priority, see TICKER_PRIORITIES
+)
+
 // These 2 variables determine the looping logic for leaving orders open, querying, cancelling and re-create order with a different bid price
 const (
 	OrderOpenThenCancelWindowCount  = 23 // outer loop
 	OrderOpenQueryStatusWindowCount = 60 // inner loop
 )
+
+// Times an order is re-created within an order window when the exchange cancels it as soon as it is created
+const OrderRecreateCount = 5
@@ -0,0 +1,30 @@
+package config
+
+// Exchange the ticker is bought on
+func (c *Config) GetExchange(ticker string) string {
+	if exchange, ok := c.OrderMetadata.Exchanges[ticker]; ok {
+		return exchange
+	}
+	return ExchangeGemini
+}
+
+// Quote currency of the trading pair the ticker is bought with, e.g. SGD
+func (c *Config) GetQuoteCurrency(ticker string) string {
+	return c.QuoteCurrencies[ticker]
+}
+
+// Whether orders of the ticker are simulated instead of placed, i.e. outside of production on an exchange without a
+// sandbox. Gemini has a sandbox, which orders are placed on outside of production instead
+func (c *Config) IsSimulatedOrder(ticker string) bool {
+	return c.IsSandboxEnv && c.GetExchange(ticker) != ExchangeGemini
+}
+
+// Whether any crypto ticker is bought on the exchange
+func (c *Config) IsBoughtOn(exchange string) bool {
+	for ticker := range c.CryptoTickers {
+		if c.GetExchange(ticker) == exchange {
+			return true
+		}
+	}
+	return false
+}
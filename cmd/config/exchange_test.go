@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_GetExchange(t *testing.T) {
+	TestInit(&ConfigUpdateable{Exchanges: map[string]string{"ETH": ExchangeKraken}}, nil)
+	c := Get()
+
+	assert.Equal(t, ExchangeGemini, c.GetExchange("BTC"))
+	assert.Equal(t, ExchangeKraken, c.GetExchange("ETH"))
+	assert.True(t, c.IsBoughtOn(ExchangeGemini))
+	assert.True(t, c.IsBoughtOn(ExchangeKraken))
+
+	TestInit(nil, nil)
+	assert.Equal(t, "SGD", Get().GetQuoteCurrency("BTC"))
+	assert.False(t, Get().IsBoughtOn(ExchangeKraken))
+}
+
+func TestConfig_IsSimulatedOrder(t *testing.T) {
+	TestInit(&ConfigUpdateable{Exchanges: map[string]string{"ETH": ExchangeKraken}}, nil)
+	assert.False(t, Get().IsSimulatedOrder("BTC"))
+	assert.True(t, Get().IsSimulatedOrder("ETH"))
+
+	isSandboxEnv := false
+	TestInit(&ConfigUpdateable{IsSandboxEnv: &isSandboxEnv, Exchanges: map[string]string{"ETH": ExchangeKraken}}, nil)
+	assert.False(t, Get().IsSimulatedOrder("ETH"))
+}
@@ -0,0 +1,14 @@
+package config
+
+// Currency the spend of the ticker is reported in, i.e. the quote currency of the ticker if no reporting currency is set
+func (c *Config) GetReportingCurrency(ticker string) string {
+	if c.Fx.ReportingCurrency != "" {
+		return c.Fx.ReportingCurrency
+	}
+	return c.GetQuoteCurrency(ticker)
+}
+
+// Whether DAILY_FIAT_AMOUNTS are in the reporting currency, to be converted into the quote currency of each ticker
+func (c *Config) IsBudgetInReportingCurrency() bool {
+	return c.Fx.BudgetCurrency == BudgetCurrencyReporting
+}
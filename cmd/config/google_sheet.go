@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Cell range of the ticker's row for the given day, e.g. for fills of previous days recorded late. Rows of scheduled
+// tickers only advance on the days they are due
+func (c *Config) GetCellRange(ticker string, day time.Time) *sheets.GridRange {
+	cellRange := c.GoogleSheet.CellRanges[ticker]
+	today := GetTime().GetTodayDate()
+	if cellRange == nil || day.Equal(today) {
+		return cellRange
+	}
+	startDate := mustParseStrToTime(startDate_EnvKey, c.GoogleSheet.startDate)
+	offset := int(day.Sub(today).Hours() / 24)
+	if schedule, ok := c.OrderMetadata.Schedules[ticker]; ok {
+		offset = schedule.countDueDays(startDate, day) - schedule.countDueDays(startDate, today)
+	}
+	shifted := *cellRange
+	shifted.StartRowIndex += int64(offset)
+	shifted.EndRowIndex += int64(offset)
+	return &shifted
+}
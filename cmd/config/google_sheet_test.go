@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestConfig_GetCellRange(t *testing.T) {
+	TestInit(&ConfigUpdateable{Schedules: map[string]Schedule{"ETH": {Name: ScheduleEveryNDays, Days: 2}}}, &TestNow)
+	c := Get()
+	tests := []struct {
+		name   string
+		ticker string
+		day    time.Time
+		want   *sheets.GridRange
+	}{
+		{
+			name:   "ok - today",
+			ticker: "BTC",
+			day:    TestNowDate,
+			want:   &sheets.GridRange{StartRowIndex: 2, EndRowIndex: 3, StartColumnIndex: 4, EndColumnIndex: 8},
+		},
+		{
+			name:   "ok - previous day",
+			ticker: "BTC",
+			day:    TestNowDate.AddDate(0, 0, -2),
+			want:   &sheets.GridRange{StartRowIndex: 0, EndRowIndex: 1, StartColumnIndex: 4, EndColumnIndex: 8},
+		},
+		{
+			name:   "ok - previous day of scheduled ticker",
+			ticker: "ETH",
+			day:    TestNowDate.AddDate(0, 0, -2),
+			want:   &sheets.GridRange{StartRowIndex: 2, EndRowIndex: 3, StartColumnIndex: 8, EndColumnIndex: 12},
+		},
+		{
+			name:   "ok - unknown ticker",
+			ticker: "SOL",
+			day:    TestNowDate,
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.GetCellRange(tt.ticker, tt.day))
+		})
+	}
+	// today's cell ranges are left as is
+	assert.Equal(t, int64(2), c.GoogleSheet.CellRanges["BTC"].StartRowIndex)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"strings"
+	"time"
 )
 
 func initConfig() *Config {
@@ -12,20 +13,169 @@ func initConfig() *Config {
 	config.IsSandboxEnv = env != string(production)
 
 	cryptoTickers := mustRetrieveConfigFromEnv(cryptoTickers_EnvKey)
-	cryptoTickerSlice := mustTransformArrayStringToArray(cryptoTickers)
+	tradingPairSlice := mustTransformArrayStringToArray(cryptoTickers)
+	cryptoTickerSlice, quoteCurrencies := mustSplitTradingPairs(cryptoTickers_EnvKey, tradingPairSlice)
 	config.CryptoTickers = mustTransformSliceToMap(cryptoTickers_EnvKey, cryptoTickerSlice)
+	config.QuoteCurrencies = quoteCurrencies
 
 	geminiApiKey := mustRetrieveConfigFromEnv(geminiApiKey_EnvKey)
 	config.GeminiApi.ApiKey = geminiApiKey
 
 	geminiApiSecret := mustRetrieveConfigFromEnv(geminiApiSecret_EnvKey)
 	config.GeminiApi.ApiSecret = geminiApiSecret
+	config.GeminiApi.BaseUrl = retrieveConfigFromEnv(geminiBaseUrl_EnvKey)
+
+	config.GeminiApi.RateLimits = defaultGeminiRateLimits
+	if geminiRateLimits := retrieveConfigFromEnv(geminiRateLimits_EnvKey); geminiRateLimits != "" {
+		config.GeminiApi.RateLimits = mustTransformJsonStringToRateLimits(geminiRateLimits_EnvKey, geminiRateLimits, defaultGeminiRateLimits)
+	}
+
+	config.GeminiApi.RateLimitWait = defaultGeminiRateLimitWait * time.Second
+	if geminiRateLimitWait := retrieveConfigFromEnv(geminiRateLimitWait_EnvKey); geminiRateLimitWait != "" {
+		config.GeminiApi.RateLimitWait = time.Duration(mustParseStrToType[int](geminiRateLimitWait_EnvKey, geminiRateLimitWait, reflect.Int)) * time.Second
+	}
+
+	config.GeminiApi.RequestTimeout = defaultGeminiRequestTimeout * time.Second
+	if geminiRequestTimeout := retrieveConfigFromEnv(geminiRequestTimeout_EnvKey); geminiRequestTimeout != "" {
+		config.GeminiApi.RequestTimeout = time.Duration(mustParseStrToType[int](geminiRequestTimeout_EnvKey, geminiRequestTimeout, reflect.Int)) * time.Second
+	}
+
+	config.GeminiApi.NonceMode = NonceModeIncreasing
+	if geminiNonceMode := retrieveConfigFromEnv(geminiNonceMode_EnvKey); geminiNonceMode != "" {
+		mustValidateNonceMode(geminiNonceMode_EnvKey, geminiNonceMode)
+		config.GeminiApi.NonceMode = geminiNonceMode
+	}
+
+	config.GeminiApi.NoncePath = defaultGeminiNoncePath
+	if geminiNoncePath := retrieveConfigFromEnv(geminiNoncePath_EnvKey); geminiNoncePath != "" {
+		config.GeminiApi.NoncePath = geminiNoncePath
+	}
+
+	config.GeminiApi.OrderUpdates = OrderUpdatesWebsocket
+	if geminiOrderUpdates := retrieveConfigFromEnv(geminiOrderUpdates_EnvKey); geminiOrderUpdates != "" {
+		mustValidateOrderUpdates(geminiOrderUpdates_EnvKey, geminiOrderUpdates)
+		config.GeminiApi.OrderUpdates = geminiOrderUpdates
+	}
+
+	config.GeminiApi.MarketData = MarketDataWebsocket
+	if geminiMarketData := retrieveConfigFromEnv(geminiMarketData_EnvKey); geminiMarketData != "" {
+		mustValidateMarketData(geminiMarketData_EnvKey, geminiMarketData)
+		config.GeminiApi.MarketData = geminiMarketData
+	}
 
 	dailyFiatAmounts := mustRetrieveConfigFromEnv(dailyFiatAmounts_EnvKey)
 	config.OrderMetadata.DailyFiatAmount = mustTransformJsonStringToMappedCryptoTickers[float64](dailyFiatAmounts_EnvKey, config, dailyFiatAmounts)
 
 	orderPriceToBidPriceRatio := mustRetrieveConfigFromEnv(orderPriceToBidPriceRatio_EnvKey)
-	config.OrderMetadata.OrderPriceToBidPriceRatio = mustParseStrToType(orderPriceToBidPriceRatio_EnvKey, orderPriceToBidPriceRatio, reflect.Float64)
+	config.OrderMetadata.OrderPriceToBidPriceRatio = mustParseStrToType[float64](orderPriceToBidPriceRatio_EnvKey, orderPriceToBidPriceRatio, reflect.Float64)
+
+	config.OrderMetadata.RepriceBidMoveRatio = defaultRepriceBidMoveRatio
+	if repriceBidMoveRatio := retrieveConfigFromEnv(repriceBidMoveRatio_EnvKey); repriceBidMoveRatio != "" {
+		config.OrderMetadata.RepriceBidMoveRatio = mustParseStrToType[float64](repriceBidMoveRatio_EnvKey, repriceBidMoveRatio, reflect.Float64)
+		mustValidateRepriceBidMoveRatio(repriceBidMoveRatio_EnvKey, config.OrderMetadata.RepriceBidMoveRatio)
+	}
+
+	if pricingStrategies := retrieveConfigFromEnv(pricingStrategies_EnvKey); pricingStrategies != "" {
+		config.OrderMetadata.PricingStrategies = mustTransformJsonStringToMappedCryptoTickers[PricingStrategy](pricingStrategies_EnvKey, config, pricingStrategies)
+		mustValidatePricingStrategies(pricingStrategies_EnvKey, config.OrderMetadata.PricingStrategies)
+	}
+
+	if fallbackStrategies := retrieveConfigFromEnv(fallbackStrategies_EnvKey); fallbackStrategies != "" {
+		config.OrderMetadata.FallbackStrategies = mustTransformJsonStringToMappedCryptoTickers[FallbackStrategy](fallbackStrategies_EnvKey, config, fallbackStrategies)
+		mustValidateFallbackStrategies(fallbackStrategies_EnvKey, config.OrderMetadata.FallbackStrategies)
+	}
+
+	if carryForwardCaps := retrieveConfigFromEnv(carryForwardCaps_EnvKey); carryForwardCaps != "" {
+		config.OrderMetadata.CarryForward.Caps = mustTransformJsonStringToMappedCryptoTickers[float64](carryForwardCaps_EnvKey, config, carryForwardCaps)
+		mustValidateFiatCaps(carryForwardCaps_EnvKey, config.OrderMetadata.CarryForward.Caps)
+	}
+
+	config.OrderMetadata.CarryForward.ExpiryDays = defaultCarryForwardExpiryDays
+	if carryForwardExpiryDays := retrieveConfigFromEnv(carryForwardExpiryDays_EnvKey); carryForwardExpiryDays != "" {
+		config.OrderMetadata.CarryForward.ExpiryDays = mustParseStrToType[int](carryForwardExpiryDays_EnvKey, carryForwardExpiryDays, reflect.Int)
+	}
+
+	if monthlyFiatCaps := retrieveConfigFromEnv(monthlyFiatCaps_EnvKey); monthlyFiatCaps != "" {
+		config.OrderMetadata.SpendCaps.MonthlyFiatCaps = mustTransformJsonStringToMappedCryptoTickers[float64](monthlyFiatCaps_EnvKey, config, monthlyFiatCaps)
+		mustValidateFiatCaps(monthlyFiatCaps_EnvKey, config.OrderMetadata.SpendCaps.MonthlyFiatCaps)
+	}
+
+	if globalDailyFiatCap := retrieveConfigFromEnv(globalDailyFiatCap_EnvKey); globalDailyFiatCap != "" {
+		config.OrderMetadata.SpendCaps.GlobalDailyFiatCap = mustParseStrToType[float64](globalDailyFiatCap_EnvKey, globalDailyFiatCap, reflect.Float64)
+		mustValidateFiatCap(globalDailyFiatCap_EnvKey, config.OrderMetadata.SpendCaps.GlobalDailyFiatCap)
+	}
+
+	if globalMonthlyFiatCap := retrieveConfigFromEnv(globalMonthlyFiatCap_EnvKey); globalMonthlyFiatCap != "" {
+		config.OrderMetadata.SpendCaps.GlobalMonthlyFiatCap = mustParseStrToType[float64](globalMonthlyFiatCap_EnvKey, globalMonthlyFiatCap, reflect.Float64)
+		mustValidateFiatCap(globalMonthlyFiatCap_EnvKey, config.OrderMetadata.SpendCaps.GlobalMonthlyFiatCap)
+	}
+
+	if schedules := retrieveConfigFromEnv(schedules_EnvKey); schedules != "" {
+		config.OrderMetadata.Schedules = mustTransformJsonStringToMappedCryptoTickers[Schedule](schedules_EnvKey, config, schedules)
+		mustValidateSchedules(schedules_EnvKey, config.OrderMetadata.Schedules)
+	}
+
+	if exchanges := retrieveConfigFromEnv(exchanges_EnvKey); exchanges != "" {
+		config.OrderMetadata.Exchanges = mustTransformJsonStringToMappedCryptoTickers[string](exchanges_EnvKey, config, exchanges)
+		mustValidateExchanges(exchanges_EnvKey, config.OrderMetadata.Exchanges)
+	}
+
+	if config.IsBoughtOn(ExchangeKraken) {
+		krakenApiKey := mustRetrieveConfigFromEnv(krakenApiKey_EnvKey)
+		config.KrakenApi.ApiKey = krakenApiKey
+
+		krakenApiSecret := mustRetrieveConfigFromEnv(krakenApiSecret_EnvKey)
+		config.KrakenApi.ApiSecret = krakenApiSecret
+	}
+
+	config.KrakenApi.RequestTimeout = defaultKrakenRequestTimeout * time.Second
+	if krakenRequestTimeout := retrieveConfigFromEnv(krakenRequestTimeout_EnvKey); krakenRequestTimeout != "" {
+		config.KrakenApi.RequestTimeout = time.Duration(mustParseStrToType[int](krakenRequestTimeout_EnvKey, krakenRequestTimeout, reflect.Int)) * time.Second
+	}
+
+	config.KrakenApi.NoncePath = defaultKrakenNoncePath
+	if krakenNoncePath := retrieveConfigFromEnv(krakenNoncePath_EnvKey); krakenNoncePath != "" {
+		config.KrakenApi.NoncePath = krakenNoncePath
+	}
+
+	config.Fx.ReportingCurrency = strings.ToUpper(retrieveConfigFromEnv(reportingCurrency_EnvKey))
+
+	config.Fx.BudgetCurrency = BudgetCurrencyQuote
+	if budgetCurrency := retrieveConfigFromEnv(budgetCurrency_EnvKey); budgetCurrency != "" {
+		mustValidateBudgetCurrency(budgetCurrency_EnvKey, config, budgetCurrency)
+		config.Fx.BudgetCurrency = budgetCurrency
+	}
+
+	if config.Fx.ReportingCurrency != "" {
+		config.Fx.RateProvider = FxRateProviderHttp
+		if fxRateProvider := retrieveConfigFromEnv(fxRateProvider_EnvKey); fxRateProvider != "" {
+			mustValidateFxRateProvider(fxRateProvider_EnvKey, fxRateProvider)
+			config.Fx.RateProvider = fxRateProvider
+		}
+
+		switch config.Fx.RateProvider {
+		case FxRateProviderStatic:
+			fxRates := mustRetrieveConfigFromEnv(fxRates_EnvKey)
+			config.Fx.Rates = mustTransformJsonStringToFxRates(fxRates_EnvKey, fxRates)
+		case FxRateProviderFile:
+			config.Fx.RatesPath = mustRetrieveConfigFromEnv(fxRatesPath_EnvKey)
+		case FxRateProviderHttp:
+			config.Fx.ApiUrl = defaultFxApiUrl
+			if fxApiUrl := retrieveConfigFromEnv(fxApiUrl_EnvKey); fxApiUrl != "" {
+				config.Fx.ApiUrl = fxApiUrl
+			}
+		}
+	}
+
+	config.OrderMetadata.BalanceCheckMode = BalanceCheckModeAbort
+	if balanceCheckMode := retrieveConfigFromEnv(balanceCheckMode_EnvKey); balanceCheckMode != "" {
+		mustValidateBalanceCheckMode(balanceCheckMode_EnvKey, balanceCheckMode)
+		config.OrderMetadata.BalanceCheckMode = balanceCheckMode
+	}
+
+	if tickerPriorities := retrieveConfigFromEnv(tickerPriorities_EnvKey); tickerPriorities != "" {
+		config.OrderMetadata.TickerPriorities = mustTransformJsonStringToMappedCryptoTickers[int](tickerPriorities_EnvKey, config, tickerPriorities)
+	}
 
 	googleServiceAccountEmail := mustRetrieveConfigFromEnv(googleServiceAccountEmail_EnvKey)
 	config.GoogleSheet.ServiceAccountEmail = googleServiceAccountEmail
@@ -69,13 +219,47 @@ func initConfig() *Config {
 	sentryDsn := mustRetrieveConfigFromEnv(sentryDsn_EnvKey)
 	config.Sentry.Dsn = sentryDsn
 
+	config.Journal.Store = JournalStoreDb
+	if journalStore := retrieveConfigFromEnv(journalStore_EnvKey); journalStore != "" {
+		mustValidateJournalStore(journalStore_EnvKey, journalStore)
+		config.Journal.Store = journalStore
+	}
+
+	config.Journal.Path = defaultJournalPath
+	if journalPath := retrieveConfigFromEnv(journalPath_EnvKey); journalPath != "" {
+		config.Journal.Path = journalPath
+	}
+
+	if runMode := retrieveConfigFromEnv(runMode_EnvKey); runMode != "" {
+		mustValidateRunMode(runMode_EnvKey, runMode)
+		config.Daemon.IsEnabled = runMode == RunModeDaemon
+	}
+
+	config.Daemon.Location = time.UTC
+	if daemonTimezone := retrieveConfigFromEnv(daemonTimezone_EnvKey); daemonTimezone != "" {
+		config.Daemon.Location = mustLoadLocation(daemonTimezone_EnvKey, daemonTimezone)
+	}
+
+	if config.Daemon.IsEnabled {
+		daemonRunTimes := mustRetrieveConfigFromEnv(daemonRunTimes_EnvKey)
+		config.Daemon.RunTimes = mustTransformJsonStringToMappedCryptoTickers[string](daemonRunTimes_EnvKey, config, daemonRunTimes)
+		mustValidateDaemonRunTimes(daemonRunTimes_EnvKey, config, config.Daemon.RunTimes)
+	}
+
+	config.Daemon.StatusAddr = retrieveConfigFromEnv(daemonStatusAddr_EnvKey)
+
+	config.Daemon.ShutdownTimeout = defaultDaemonShutdownTimeout * time.Second
+	if daemonShutdownTimeout := retrieveConfigFromEnv(daemonShutdownTimeout_EnvKey); daemonShutdownTimeout != "" {
+		config.Daemon.ShutdownTimeout = time.Duration(mustParseStrToType[int](daemonShutdownTimeout_EnvKey, daemonShutdownTimeout, reflect.Int)) * time.Second
+	}
+
 	return config
 }
 
 func addTimeRelatedConfigs(config *Config) {
 	config.GoogleSheet.differenceInDays = mustGetDifferenceInDaysFromStartDate(config)
 
-	config.GoogleSheet.rowRanges = formRowRanges(&config.GoogleSheet)
+	config.GoogleSheet.rowRanges = formRowRanges(config)
 
 	config.GoogleSheet.CellRanges = formCellRanges(&config.GoogleSheet)
 }
@@ -18,9 +18,12 @@ func Test_initConfig(t *testing.T) {
 		s := fmt.Sprintf("%02d/%02d/%04d", twoDaysBefore.Day(), twoDaysBefore.Month(), twoDaysBefore.Year())
 
 		os.Setenv(string(env_EnvKey), "sandbox")
-		os.Setenv(string(cryptoTickers_EnvKey), "BTC,ETH")
+		os.Setenv(string(cryptoTickers_EnvKey), "BTC/SGD,ETH/SGD")
 		os.Setenv(string(geminiApiKey_EnvKey), "gemini_api_key")
 		os.Setenv(string(geminiApiSecret_EnvKey), "gemini_api_secret")
+		os.Setenv(string(geminiNoncePath_EnvKey), TestNoncePath)
+		os.Setenv(string(krakenNoncePath_EnvKey), TestKrakenNoncePath)
+		os.Setenv(string(geminiRateLimits_EnvKey), `{"public":{"perSecond":0,"burst":0},"private":{"perSecond":0,"burst":0}}`)
 		os.Setenv(string(dailyFiatAmounts_EnvKey), `{"BTC":1,"ETH":2}`)
 		os.Setenv(string(orderPriceToBidPriceRatio_EnvKey), "0.999")
 		os.Setenv(string(googleServiceAccountEmail_EnvKey), "google_service_account_email")
@@ -8,6 +8,12 @@ func MustInit() {
 	addTimeRelatedConfigs(config)
 }
 
+// Re-evaluates today's date & the configs depending on it, for every run of a long-running process
+func RefreshTime() {
+	timeInit(nil)
+	addTimeRelatedConfigs(config)
+}
+
 func Get() *Config {
 	return config
 }
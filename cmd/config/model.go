@@ -1,28 +1,130 @@
 package config
 
-import "google.golang.org/api/sheets/v4"
+import (
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
 
 // Public vars are to be used in the application
 //
 // Private vars are only declared in env config, and not used elsewhere
 type Config struct {
-	IsSandboxEnv  bool
-	CryptoTickers map[string]bool
-	OrderMetadata OrderMetadata
-	GeminiApi     GeminiApi
-	GoogleSheet   GoogleSheet
-	Db            Db
-	Sentry        Sentry
+	IsSandboxEnv    bool
+	CryptoTickers   map[string]bool
+	QuoteCurrencies map[string]string // of the trading pair of each crypto ticker, e.g. SGD for BTC/SGD
+	OrderMetadata   OrderMetadata
+	GeminiApi       GeminiApi
+	KrakenApi       KrakenApi
+	GoogleSheet     GoogleSheet
+	Db              Db
+	Sentry          Sentry
+	Journal         Journal
+	Daemon          Daemon
+	Fx              Fx
 }
 
 type OrderMetadata struct {
 	DailyFiatAmount           map[string]float64
 	OrderPriceToBidPriceRatio float64
+	RepriceBidMoveRatio       float64 // a live order is repriced once the streamed best bid moves by more, 0 to never reprice
+	PricingStrategies         map[string]PricingStrategy
+	BalanceCheckMode          string
+	TickerPriorities          map[string]int // lower value is of higher priority, defaults to 0
+	FallbackStrategies        map[string]FallbackStrategy
+	CarryForward              CarryForward
+	SpendCaps                 SpendCaps
+	Schedules                 map[string]Schedule // tickers without a schedule are bought every day
+	Exchanges                 map[string]string   // tickers without an exchange are bought on ExchangeGemini
+}
+
+// Limits on the fiat spent, including trading fees, on top of the daily fiat amounts. There is no limit if unset
+type SpendCaps struct {
+	MonthlyFiatCaps      map[string]float64 // per ticker per calendar month
+	GlobalDailyFiatCap   float64            // across all crypto tickers
+	GlobalMonthlyFiatCap float64            // across all crypto tickers
+}
+
+// Only tickers with a cap carry their unspent daily fiat amount forward, up to the cap
+//
+// Unspent amounts older than ExpiryDays are no longer carried
+type CarryForward struct {
+	Caps       map[string]float64
+	ExpiryDays int
+}
+
+// Weekdays is only used by ScheduleWeekdays, as 3-letter names, e.g. "mon"
+//
+// Days is only used by ScheduleEveryNDays, Cron is only used by ScheduleCron as a standard 5-field expression
+type Schedule struct {
+	Name     string   `json:"name"`
+	Weekdays []string `json:"weekdays"`
+	Days     int      `json:"days"`
+	Cron     string   `json:"cron"`
+}
+
+// Ticks is only used by PricingStrategyAskMinusTicks
+//
+// Depth is only used by PricingStrategyBookDepth, as a multiple of the order's fiat amount
+type PricingStrategy struct {
+	Name  string  `json:"name"`
+	Ticks int     `json:"ticks"`
+	Depth float64 `json:"depth"`
+}
+
+// Step & MaxPremium are ratios of the best bid. MaxPremium bounds the order price of every fallback strategy
+//
+// Step is only used by FallbackStrategyAggressiveLimit
+type FallbackStrategy struct {
+	Name         string  `json:"name"`
+	AfterWindows int     `json:"afterWindows"`
+	Step         float64 `json:"step"`
+	MaxPremium   float64 `json:"maxPremium"`
+}
+
+// Spend is normalised into ReportingCurrency, if set, with the rates of RateProvider. Otherwise it is reported in the
+// quote currency of each ticker
+//
+// Rates is only used by FxRateProviderStatic, RatesPath by FxRateProviderFile and ApiUrl by FxRateProviderHttp
+type Fx struct {
+	ReportingCurrency string
+	BudgetCurrency    string
+	RateProvider      string
+	Rates             map[string]float64 // keyed by currency pair, e.g. 1.35 for USD/SGD
+	RatesPath         string
+	ApiUrl            string
 }
 
 type GeminiApi struct {
-	ApiKey    string
-	ApiSecret string
+	ApiKey         string
+	ApiSecret      string
+	BaseUrl        string // overrides the URL of the live or sandbox API if set, e.g. of a local fake exchange
+	RateLimits     GeminiRateLimits
+	RateLimitWait  time.Duration // max wait of a request for the rate limiter, before failing
+	RequestTimeout time.Duration // max duration of a request, including its wait for the rate limiter
+	NonceMode      string
+	NoncePath      string // only used by NonceModeIncreasing
+	OrderUpdates   string
+	MarketData     string
+}
+
+// Requests are rate limited separately for public & private endpoints, no limit is applied if PerSecond is 0
+type GeminiRateLimits struct {
+	Public  RateLimit `json:"public"`
+	Private RateLimit `json:"private"`
+}
+
+type RateLimit struct {
+	PerSecond float64 `json:"perSecond"`
+	Burst     int     `json:"burst"`
+}
+
+// Only required if a ticker is bought on ExchangeKraken
+type KrakenApi struct {
+	ApiKey         string
+	ApiSecret      string
+	RequestTimeout time.Duration // max duration of a request
+	NoncePath      string        // high-water mark of the increasing nonces
 }
 
 // Public vars are to be used in the application
@@ -55,3 +157,18 @@ type Db struct {
 type Sentry struct {
 	Dsn string
 }
+
+// Should be on persistent storage for runs to be resumable after a crash
+type Journal struct {
+	Store string
+	Path  string // only used by JournalStoreFile
+}
+
+// Only used by RunModeDaemon. RunTimes are local times of Location, in the format of HH:MM
+type Daemon struct {
+	IsEnabled       bool
+	RunTimes        map[string]string
+	Location        *time.Location
+	StatusAddr      string // serves the state of the runs if set, e.g. ":8080"
+	ShutdownTimeout time.Duration
+}
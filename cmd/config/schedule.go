@@ -0,0 +1,79 @@
+package config
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Whether the ticker is to be bought today, as per its schedule
+func (c *Config) IsDueToday(ticker string) bool {
+	schedule, ok := c.OrderMetadata.Schedules[ticker]
+	if !ok {
+		return true
+	}
+	startDate := mustParseStrToTime(startDate_EnvKey, c.GoogleSheet.startDate)
+	return schedule.isDue(GetTime().GetTodayDate(), startDate)
+}
+
+// day is a date, i.e. at midnight UTC. ScheduleEveryNDays counts from startDate
+func (s Schedule) isDue(day, startDate time.Time) bool {
+	switch s.Name {
+	case ScheduleWeekdays:
+		for _, weekday := range s.Weekdays {
+			if weekdays[weekday] == day.Weekday() {
+				return true
+			}
+		}
+		return false
+	case ScheduleEveryNDays:
+		if day.Before(startDate) {
+			return false
+		}
+		return int(day.Sub(startDate).Hours()/24)%s.Days == 0
+	case ScheduleFirstOfMonth:
+		return day.Day() == 1
+	case ScheduleCron:
+		schedule, err := parseCron(s.Cron)
+		if err != nil {
+			return false
+		}
+		// Due if it fires at any time of the day
+		return schedule.Next(day.Add(-time.Second)).Before(day.AddDate(0, 0, 1))
+	default:
+		return true
+	}
+}
+
+// Number of days the schedule is due within [startDate, day)
+func (s Schedule) countDueDays(startDate, day time.Time) int {
+	count := 0
+	for d := startDate; d.Before(day); d = d.AddDate(0, 0, 1) {
+		if s.isDue(d, startDate) {
+			count++
+		}
+	}
+	return count
+}
+
+// Standard 5-field cron expression, evaluated in UTC unless specified with CRON_TZ
+func parseCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok && spec.Location == time.Local {
+		spec.Location = time.UTC
+	}
+	return schedule, nil
+}
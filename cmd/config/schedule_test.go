@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_isDue(t *testing.T) {
+	startDate := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC) // Friday
+	tests := []struct {
+		name     string
+		schedule Schedule
+		day      time.Time
+		want     bool
+	}{
+		{
+			name:     "ok_daily",
+			schedule: Schedule{Name: ScheduleDaily},
+			day:      TestNowDate,
+			want:     true,
+		},
+		{
+			name:     "ok_weekdays_due",
+			schedule: Schedule{Name: ScheduleWeekdays, Weekdays: []string{"fri", "sun"}},
+			day:      TestNowDate, // Sunday
+			want:     true,
+		},
+		{
+			name:     "ok_weekdays_not_due",
+			schedule: Schedule{Name: ScheduleWeekdays, Weekdays: []string{"mon"}},
+			day:      TestNowDate,
+			want:     false,
+		},
+		{
+			name:     "ok_every_n_days_due",
+			schedule: Schedule{Name: ScheduleEveryNDays, Days: 2},
+			day:      TestNowDate,
+			want:     true,
+		},
+		{
+			name:     "ok_every_n_days_not_due",
+			schedule: Schedule{Name: ScheduleEveryNDays, Days: 3},
+			day:      TestNowDate,
+			want:     false,
+		},
+		{
+			name:     "ok_every_n_days_before_start_date",
+			schedule: Schedule{Name: ScheduleEveryNDays, Days: 1},
+			day:      startDate.AddDate(0, 0, -1),
+			want:     false,
+		},
+		{
+			name:     "ok_first_of_month_due",
+			schedule: Schedule{Name: ScheduleFirstOfMonth},
+			day:      startDate,
+			want:     true,
+		},
+		{
+			name:     "ok_first_of_month_not_due",
+			schedule: Schedule{Name: ScheduleFirstOfMonth},
+			day:      TestNowDate,
+			want:     false,
+		},
+		{
+			name:     "ok_cron_due",
+			schedule: Schedule{Name: ScheduleCron, Cron: "30 23 3,17 * *"},
+			day:      TestNowDate,
+			want:     true,
+		},
+		{
+			name:     "ok_cron_not_due",
+			schedule: Schedule{Name: ScheduleCron, Cron: "0 0 * * 1-5"},
+			day:      TestNowDate,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.schedule.isDue(tt.day, startDate))
+		})
+	}
+}
+
+func TestSchedule_countDueDays(t *testing.T) {
+	startDate := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	day := time.Date(2024, time.December, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 31, Schedule{Name: ScheduleDaily}.countDueDays(startDate, day))
+	assert.Equal(t, 11, Schedule{Name: ScheduleEveryNDays, Days: 3}.countDueDays(startDate, day))
+	assert.Equal(t, 2, Schedule{Name: ScheduleFirstOfMonth}.countDueDays(startDate, day))
+	assert.Equal(t, 8, Schedule{Name: ScheduleWeekdays, Weekdays: []string{"mon", "thu"}}.countDueDays(startDate, day))
+}
+
+func TestConfig_IsDueToday(t *testing.T) {
+	TestInit(&ConfigUpdateable{
+		Schedules: map[string]Schedule{
+			"ETH": {Name: ScheduleWeekdays, Weekdays: []string{"mon"}},
+		},
+	}, &TestNow)
+	assert.True(t, Get().IsDueToday("BTC"))
+	assert.False(t, Get().IsDueToday("ETH"))
+}
+
+func Test_formRowRanges(t *testing.T) {
+	TestInit(&ConfigUpdateable{
+		Schedules: map[string]Schedule{
+			"ETH": {Name: ScheduleWeekdays, Weekdays: []string{"fri"}},
+		},
+	}, &TestNow)
+	// BTC is due every day since 01/11/2024, ETH only on Friday 01/11/2024
+	assert.Equal(t, map[string]int{"BTC": 3, "ETH": 3}, formRowRanges(Get()))
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
@@ -8,13 +10,26 @@ import (
 )
 
 type ConfigUpdateable struct {
-	IsSandboxEnv    *bool
-	DailyFiatAmount map[string]float64
+	IsSandboxEnv       *bool
+	DailyFiatAmount    map[string]float64
+	PricingStrategies  map[string]PricingStrategy
+	BalanceCheckMode   string
+	TickerPriorities   map[string]int
+	FallbackStrategies map[string]FallbackStrategy
+	CarryForwardCaps   map[string]float64
+	SpendCaps          *SpendCaps
+	Schedules          map[string]Schedule
+	Exchanges          map[string]string
+	QuoteCurrencies    map[string]string
+	Fx                 *Fx
+	GeminiBaseUrl      string
 }
 
 var TestNow = time.Date(2024, time.November, 3, 14, 30, 0, 0, time.UTC)
 var TestNowDate = time.Date(2024, time.November, 3, 0, 0, 0, 0, time.UTC)
 var TestNowDateStr = "03/11/2024"
+var TestNoncePath = filepath.Join(os.TempDir(), defaultGeminiNoncePath)
+var TestKrakenNoncePath = filepath.Join(os.TempDir(), defaultKrakenNoncePath)
 
 func TestInit(u *ConfigUpdateable, now *time.Time) {
 	logger.Init()
@@ -24,9 +39,24 @@ func TestInit(u *ConfigUpdateable, now *time.Time) {
 			"BTC": true,
 			"ETH": true,
 		},
+		QuoteCurrencies: map[string]string{
+			"BTC": "SGD",
+			"ETH": "SGD",
+		},
 		GeminiApi: GeminiApi{
-			ApiKey:    "gemini_api_key",
-			ApiSecret: "gemini_api_secret",
+			ApiKey:         "gemini_api_key",
+			ApiSecret:      "gemini_api_secret",
+			RateLimits:     GeminiRateLimits{}, // not rate limited in unit tests
+			RateLimitWait:  defaultGeminiRateLimitWait * time.Second,
+			RequestTimeout: defaultGeminiRequestTimeout * time.Second,
+			NonceMode:      NonceModeIncreasing,
+			NoncePath:      TestNoncePath, // not to leave nonce files behind in the package directories
+			OrderUpdates:   OrderUpdatesWebsocket,
+			MarketData:     MarketDataWebsocket,
+		},
+		KrakenApi: KrakenApi{
+			RequestTimeout: defaultKrakenRequestTimeout * time.Second,
+			NoncePath:      TestKrakenNoncePath, // not to leave nonce files behind in the package directories
 		},
 		OrderMetadata: OrderMetadata{
 			DailyFiatAmount: map[string]float64{
@@ -34,6 +64,11 @@ func TestInit(u *ConfigUpdateable, now *time.Time) {
 				"ETH": 2,
 			},
 			OrderPriceToBidPriceRatio: 0.999,
+			RepriceBidMoveRatio:       defaultRepriceBidMoveRatio,
+			BalanceCheckMode:          BalanceCheckModeAbort,
+			CarryForward: CarryForward{
+				ExpiryDays: defaultCarryForwardExpiryDays,
+			},
 		},
 		GoogleSheet: GoogleSheet{
 			ServiceAccountEmail:      "google_service_account_email",
@@ -80,6 +115,17 @@ func TestInit(u *ConfigUpdateable, now *time.Time) {
 		Sentry: Sentry{
 			Dsn: "sentry_dsn",
 		},
+		Journal: Journal{
+			Store: JournalStoreDb,
+			Path:  defaultJournalPath,
+		},
+		Daemon: Daemon{
+			Location:        time.UTC,
+			ShutdownTimeout: defaultDaemonShutdownTimeout * time.Second,
+		},
+		Fx: Fx{
+			BudgetCurrency: BudgetCurrencyQuote,
+		},
 	}
 
 	if u != nil {
@@ -89,6 +135,39 @@ func TestInit(u *ConfigUpdateable, now *time.Time) {
 		if u.DailyFiatAmount != nil {
 			config.OrderMetadata.DailyFiatAmount = u.DailyFiatAmount
 		}
+		if u.PricingStrategies != nil {
+			config.OrderMetadata.PricingStrategies = u.PricingStrategies
+		}
+		if u.BalanceCheckMode != "" {
+			config.OrderMetadata.BalanceCheckMode = u.BalanceCheckMode
+		}
+		if u.TickerPriorities != nil {
+			config.OrderMetadata.TickerPriorities = u.TickerPriorities
+		}
+		if u.FallbackStrategies != nil {
+			config.OrderMetadata.FallbackStrategies = u.FallbackStrategies
+		}
+		if u.CarryForwardCaps != nil {
+			config.OrderMetadata.CarryForward.Caps = u.CarryForwardCaps
+		}
+		if u.SpendCaps != nil {
+			config.OrderMetadata.SpendCaps = *u.SpendCaps
+		}
+		if u.Schedules != nil {
+			config.OrderMetadata.Schedules = u.Schedules
+		}
+		if u.Exchanges != nil {
+			config.OrderMetadata.Exchanges = u.Exchanges
+		}
+		if u.QuoteCurrencies != nil {
+			config.QuoteCurrencies = u.QuoteCurrencies
+		}
+		if u.Fx != nil {
+			config.Fx = *u.Fx
+		}
+		if u.GeminiBaseUrl != "" {
+			config.GeminiApi.BaseUrl = u.GeminiBaseUrl
+		}
 	}
 
 	timeInit(now)
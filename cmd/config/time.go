@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// Dates are recorded as DD/MM/YYYY, e.g. START_DATE & the dates in google sheets
+const dateLayout = "02/01/2006"
+
 type Time struct {
 	now time.Time
 }
@@ -31,8 +34,16 @@ func (t Time) GetTodayDate() time.Time {
 	return time.Date(t.now.Year(), t.now.Month(), t.now.Day(), 0, 0, 0, 0, time.UTC)
 }
 
+func (t Time) GetMonthStartDate() time.Time {
+	return time.Date(t.now.Year(), t.now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
 func (t Time) GetNowDateString() string {
-	return t.now.Format("02/01/2006")
+	return FormatDate(t.now)
+}
+
+func FormatDate(day time.Time) string {
+	return day.Format(dateLayout)
 }
 
 func (t Time) Now() time.Time {
@@ -36,6 +36,13 @@ func TestTime_GetTodayDate(t *testing.T) {
 	}
 }
 
+func TestTime_GetMonthStartDate(t *testing.T) {
+	tr := Time{
+		now: TestNow,
+	}
+	assert.Equal(t, time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC), tr.GetMonthStartDate())
+}
+
 func TestTime_GetNowDateString(t *testing.T) {
 	type fields struct {
 		now time.Time
@@ -57,7 +57,28 @@ func mustTransformSliceToMap[T string](key envKey, s []T) map[T]bool {
 	return m
 }
 
-func mustTransformJsonStringToMappedCryptoTickers[T float64 | int | string](key envKey, config *Config, s string) map[string]T {
+// Trading pairs are of the form BTC/SGD, return the crypto tickers & their quote currencies keyed by crypto ticker
+func mustSplitTradingPairs(key envKey, s []string) ([]string, map[string]string) {
+	location := "config.mustSplitTradingPairs"
+	cryptoTickers := make([]string, len(s))
+	quoteCurrencies := make(map[string]string, len(s))
+	for i, tradingPair := range s {
+		cryptoTicker, quoteCurrency, ok := strings.Cut(strings.TrimSpace(tradingPair), "/")
+		if !ok || cryptoTicker == "" || quoteCurrency == "" || strings.Contains(quoteCurrency, "/") {
+			errStr := fmt.Sprintf("Trading pair '%s' is invalid for key '%s', expected e.g. BTC/SGD", tradingPair, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+		cryptoTickers[i] = cryptoTicker
+		quoteCurrencies[cryptoTicker] = strings.ToUpper(quoteCurrency)
+	}
+	return cryptoTickers, quoteCurrencies
+}
+
+type mappedCryptoTickerValue interface {
+	float64 | int | string | PricingStrategy | FallbackStrategy | Schedule
+}
+
+func mustTransformJsonStringToMappedCryptoTickers[T mappedCryptoTickerValue](key envKey, config *Config, s string) map[string]T {
 	location := "config.mustTransformJsonStringToMappedCryptoTickers"
 	m := make(map[string]T)
 	if err := json.Unmarshal([]byte(s), &m); err != nil {
@@ -69,7 +90,7 @@ func mustTransformJsonStringToMappedCryptoTickers[T float64 | int | string](key
 	return m
 }
 
-func mustCheckIfCryptoTickerExist[T float64 | int | string](key envKey, config *Config, m map[string]T) {
+func mustCheckIfCryptoTickerExist[T mappedCryptoTickerValue](key envKey, config *Config, m map[string]T) {
 	location := "config.mustCheckIfCryptoTickerExist"
 	for cryptoTicker := range m {
 		if _, ok := config.CryptoTickers[cryptoTicker]; !ok {
@@ -79,8 +100,259 @@ func mustCheckIfCryptoTickerExist[T float64 | int | string](key envKey, config *
 	}
 }
 
+func mustValidatePricingStrategies(key envKey, m map[string]PricingStrategy) {
+	location := "config.mustValidatePricingStrategies"
+	for cryptoTicker, strategy := range m {
+		switch strategy.Name {
+		case PricingStrategyBidRatio, PricingStrategyMidPrice:
+		case PricingStrategyAskMinusTicks:
+			if strategy.Ticks < 0 {
+				errStr := fmt.Sprintf("Ticks of pricing strategy for crypto ticker '%s' cannot be negative for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+		case PricingStrategyBookDepth:
+			if strategy.Depth <= 0 {
+				errStr := fmt.Sprintf("Depth of pricing strategy for crypto ticker '%s' must be positive for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+		default:
+			errStr := fmt.Sprintf("Pricing strategy '%s' for crypto ticker '%s' is invalid for key '%s'", strategy.Name, cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+func mustValidateFallbackStrategies(key envKey, m map[string]FallbackStrategy) {
+	location := "config.mustValidateFallbackStrategies"
+	for cryptoTicker, strategy := range m {
+		switch strategy.Name {
+		case FallbackStrategySkipAndCarry:
+		case FallbackStrategyIocAtAsk, FallbackStrategyAggressiveLimit:
+			if strategy.Name == FallbackStrategyAggressiveLimit && strategy.Step <= 0 {
+				errStr := fmt.Sprintf("Step of fallback strategy for crypto ticker '%s' must be positive for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+			// Priced no higher than the best bid otherwise, at which an immediate or cancel order never fills
+			if strategy.MaxPremium <= 0 {
+				errStr := fmt.Sprintf("Max premium of fallback strategy for crypto ticker '%s' must be positive for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+		default:
+			errStr := fmt.Sprintf("Fallback strategy '%s' for crypto ticker '%s' is invalid for key '%s'", strategy.Name, cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+		if strategy.AfterWindows < 0 || strategy.AfterWindows >= OrderOpenThenCancelWindowCount {
+			errStr := fmt.Sprintf("After windows of fallback strategy for crypto ticker '%s' must be within [0, %d) for key '%s'", cryptoTicker, OrderOpenThenCancelWindowCount, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+func mustValidateSchedules(key envKey, m map[string]Schedule) {
+	location := "config.mustValidateSchedules"
+	for cryptoTicker, schedule := range m {
+		switch schedule.Name {
+		case ScheduleDaily, ScheduleFirstOfMonth:
+		case ScheduleWeekdays:
+			if len(schedule.Weekdays) == 0 {
+				errStr := fmt.Sprintf("Weekdays of schedule for crypto ticker '%s' cannot be empty for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+			for _, weekday := range schedule.Weekdays {
+				if _, ok := weekdays[weekday]; !ok {
+					errStr := fmt.Sprintf("Weekday '%s' of schedule for crypto ticker '%s' is invalid for key '%s'", weekday, cryptoTicker, key)
+					logger.Panic(location, errStr, errors.New(errStr))
+				}
+			}
+		case ScheduleEveryNDays:
+			if schedule.Days <= 0 {
+				errStr := fmt.Sprintf("Days of schedule for crypto ticker '%s' must be positive for key '%s'", cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+		case ScheduleCron:
+			if _, err := parseCron(schedule.Cron); err != nil {
+				errStr := fmt.Sprintf("Cron expression '%s' of schedule for crypto ticker '%s' is invalid for key '%s'", schedule.Cron, cryptoTicker, key)
+				logger.Panic(location, errStr, errors.New(errStr))
+			}
+		default:
+			errStr := fmt.Sprintf("Schedule '%s' for crypto ticker '%s' is invalid for key '%s'", schedule.Name, cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+func mustValidateExchanges(key envKey, m map[string]string) {
+	location := "config.mustValidateExchanges"
+	for cryptoTicker, exchange := range m {
+		switch exchange {
+		case ExchangeGemini, ExchangeKraken:
+		default:
+			errStr := fmt.Sprintf("Exchange '%s' for crypto ticker '%s' is invalid for key '%s'", exchange, cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+// BudgetCurrencyReporting requires the reporting currency to be set
+func mustValidateBudgetCurrency(key envKey, config *Config, budgetCurrency string) {
+	location := "config.mustValidateBudgetCurrency"
+	switch budgetCurrency {
+	case BudgetCurrencyQuote:
+	case BudgetCurrencyReporting:
+		if config.Fx.ReportingCurrency == "" {
+			errStr := fmt.Sprintf("Budget currency '%s' requires '%s' for key '%s'", budgetCurrency, reportingCurrency_EnvKey, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	default:
+		errStr := fmt.Sprintf("Budget currency '%s' is invalid for key '%s'", budgetCurrency, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateFxRateProvider(key envKey, fxRateProvider string) {
+	location := "config.mustValidateFxRateProvider"
+	switch fxRateProvider {
+	case FxRateProviderStatic, FxRateProviderFile, FxRateProviderHttp:
+	default:
+		errStr := fmt.Sprintf("FX rate provider '%s' is invalid for key '%s'", fxRateProvider, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+// FX rates are keyed by currency pair, e.g. {"USD/SGD":1.35}, and must be positive
+func mustTransformJsonStringToFxRates(key envKey, s string) map[string]float64 {
+	location := "config.mustTransformJsonStringToFxRates"
+	m := make(map[string]float64)
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		errStr := fmt.Sprintf("Unable to unmarshal '%s'", key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+	rates := make(map[string]float64, len(m))
+	for currencyPair, rate := range m {
+		from, to, ok := strings.Cut(currencyPair, "/")
+		if !ok || from == "" || to == "" || rate <= 0 {
+			errStr := fmt.Sprintf("FX rate %v of '%s' is invalid for key '%s'", rate, currencyPair, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+		rates[strings.ToUpper(currencyPair)] = rate
+	}
+	return rates
+}
+
+func mustValidateNonceMode(key envKey, mode string) {
+	location := "config.mustValidateNonceMode"
+	switch mode {
+	case NonceModeIncreasing, NonceModeTimeWindow:
+	default:
+		errStr := fmt.Sprintf("Nonce mode '%s' is invalid for key '%s'", mode, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateJournalStore(key envKey, store string) {
+	location := "config.mustValidateJournalStore"
+	switch store {
+	case JournalStoreDb, JournalStoreFile:
+	default:
+		errStr := fmt.Sprintf("Journal store '%s' is invalid for key '%s'", store, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateOrderUpdates(key envKey, orderUpdates string) {
+	location := "config.mustValidateOrderUpdates"
+	switch orderUpdates {
+	case OrderUpdatesWebsocket, OrderUpdatesPolling:
+	default:
+		errStr := fmt.Sprintf("Order updates '%s' is invalid for key '%s'", orderUpdates, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateMarketData(key envKey, marketData string) {
+	location := "config.mustValidateMarketData"
+	switch marketData {
+	case MarketDataWebsocket, MarketDataPolling:
+	default:
+		errStr := fmt.Sprintf("Market data '%s' is invalid for key '%s'", marketData, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateRepriceBidMoveRatio(key envKey, ratio float64) {
+	location := "config.mustValidateRepriceBidMoveRatio"
+	if ratio < 0 || ratio >= 1 {
+		errStr := fmt.Sprintf("Reprice bid move ratio '%v' is invalid for key '%s'", ratio, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateRunMode(key envKey, mode string) {
+	location := "config.mustValidateRunMode"
+	switch mode {
+	case RunModeOneShot, RunModeDaemon:
+	default:
+		errStr := fmt.Sprintf("Run mode '%s' is invalid for key '%s'", mode, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+// Every crypto ticker must have a run time
+func mustValidateDaemonRunTimes(key envKey, config *Config, m map[string]string) {
+	location := "config.mustValidateDaemonRunTimes"
+	for cryptoTicker := range config.CryptoTickers {
+		runTime, ok := m[cryptoTicker]
+		if !ok {
+			errStr := fmt.Sprintf("Crypto ticker '%s' is missing a run time for key '%s'", cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+		if _, err := time.Parse("15:04", runTime); err != nil {
+			errStr := fmt.Sprintf("Run time '%s' for crypto ticker '%s' is invalid for key '%s'", runTime, cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+func mustLoadLocation(key envKey, s string) *time.Location {
+	location := "config.mustLoadLocation"
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		errStr := fmt.Sprintf("Unable to load timezone '%s' for key '%s'", s, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+	return loc
+}
+
+func mustValidateFiatCaps(key envKey, m map[string]float64) {
+	location := "config.mustValidateFiatCaps"
+	for cryptoTicker, fiatCap := range m {
+		if fiatCap <= 0 {
+			errStr := fmt.Sprintf("Cap for crypto ticker '%s' must be positive for key '%s'", cryptoTicker, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+}
+
+func mustValidateFiatCap(key envKey, fiatCap float64) {
+	location := "config.mustValidateFiatCap"
+	if fiatCap <= 0 {
+		errStr := fmt.Sprintf("Cap must be positive for key '%s'", key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
+func mustValidateBalanceCheckMode(key envKey, mode string) {
+	location := "config.mustValidateBalanceCheckMode"
+	switch mode {
+	case BalanceCheckModeAbort, BalanceCheckModeScale, BalanceCheckModeSkip:
+	default:
+		errStr := fmt.Sprintf("Balance check mode '%s' is invalid for key '%s'", mode, key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+}
+
 // TODO: refactor this
-func mustParseStrToType[T float64](key envKey, s string, t reflect.Kind) T {
+func mustParseStrToType[T float64 | int](key envKey, s string, t reflect.Kind) T {
 	location := "config.mustParseStrToType"
 	switch t {
 	case reflect.Float64:
@@ -90,6 +362,13 @@ func mustParseStrToType[T float64](key envKey, s string, t reflect.Kind) T {
 			logger.Panic(location, errStr, errors.New(errStr))
 		}
 		return T(f)
+	case reflect.Int:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			errStr := fmt.Sprintf("Unable to parse key '%s', value: '%s' of type '%s'", key, s, t)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+		return T(i)
 	default:
 		errStr := fmt.Sprintf("Type '%s' is not allowed for key '%s'", t, key)
 		logger.Panic(location, errStr, errors.New(errStr))
@@ -112,7 +391,7 @@ func mustGetDifferenceInDaysFromStartDate(config *Config) int {
 
 func mustParseStrToTime(key envKey, s string) time.Time {
 	location := "config.mustParseStrToTime"
-	t, err := time.Parse("02/01/2006", s)
+	t, err := time.Parse(dateLayout, s)
 	if err != nil {
 		errStr := fmt.Sprintf("Unable to parse date string '%s' for key '%s'", s, key)
 		logger.Panic(location, errStr, errors.New(errStr))
@@ -120,10 +399,16 @@ func mustParseStrToTime(key envKey, s string) time.Time {
 	return t
 }
 
-func formRowRanges(c *GoogleSheet) map[string]int {
+// Rows of scheduled tickers only advance on the days they are due
+func formRowRanges(c *Config) map[string]int {
 	rowRanges := make(map[string]int)
-	for ticker, startRow := range c.startRows {
-		rowRanges[ticker] = startRow + c.differenceInDays
+	for ticker, startRow := range c.GoogleSheet.startRows {
+		if schedule, ok := c.OrderMetadata.Schedules[ticker]; ok {
+			startDate := mustParseStrToTime(startDate_EnvKey, c.GoogleSheet.startDate)
+			rowRanges[ticker] = startRow + schedule.countDueDays(startDate, GetTime().GetTodayDate())
+			continue
+		}
+		rowRanges[ticker] = startRow + c.GoogleSheet.differenceInDays
 	}
 	return rowRanges
 }
@@ -155,3 +440,21 @@ func formCellRanges(config *GoogleSheet) map[string]*sheets.GridRange {
 
 	return cellRanges
 }
+
+// Rate limits not given are left as the defaults, e.g. {"private":{"perSecond":2,"burst":4}}. A rate of 0 disables the
+// limit, bursts of a limit must be positive otherwise
+func mustTransformJsonStringToRateLimits(key envKey, s string, defaults GeminiRateLimits) GeminiRateLimits {
+	location := "config.mustTransformJsonStringToRateLimits"
+	rateLimits := defaults
+	if err := json.Unmarshal([]byte(s), &rateLimits); err != nil {
+		errStr := fmt.Sprintf("Unable to unmarshal '%s'", key)
+		logger.Panic(location, errStr, errors.New(errStr))
+	}
+	for name, rateLimit := range map[string]RateLimit{"public": rateLimits.Public, "private": rateLimits.Private} {
+		if rateLimit.PerSecond < 0 || (rateLimit.PerSecond > 0 && rateLimit.Burst <= 0) {
+			errStr := fmt.Sprintf("Rate limit '%s' of %+v is invalid for key '%s'", name, rateLimit, key)
+			logger.Panic(location, errStr, errors.New(errStr))
+		}
+	}
+	return rateLimits
+}
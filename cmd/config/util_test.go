@@ -84,6 +84,23 @@ func Test_mustTransformSliceToMap(t *testing.T) {
 	})
 }
 
+func Test_mustSplitTradingPairs(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		cryptoTickers, quoteCurrencies := mustSplitTradingPairs("key", []string{"BTC/SGD", " SOL/usd"})
+		assert.Equal(t, []string{"BTC", "SOL"}, cryptoTickers)
+		assert.Equal(t, map[string]string{"BTC": "SGD", "SOL": "USD"}, quoteCurrencies)
+	})
+	t.Run("panic - missing quote currency", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Trading pair 'BTC' is invalid for key 'key', expected e.g. BTC/SGD")
+		mustSplitTradingPairs("key", []string{"BTC"})
+	})
+	t.Run("panic - empty crypto ticker", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Trading pair '/SGD' is invalid for key 'key', expected e.g. BTC/SGD")
+		mustSplitTradingPairs("key", []string{"/SGD"})
+	})
+}
+
 func Test_mustTransformJsonStringToMappedCryptoTickers(t *testing.T) {
 	t.Run("ok - float64", func(t *testing.T) {
 		config.CryptoTickers = map[string]bool{
@@ -121,19 +138,303 @@ func Test_mustTransformJsonStringToMappedCryptoTickers(t *testing.T) {
 	})
 }
 
+func Test_mustValidatePricingStrategies(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidatePricingStrategies("key", map[string]PricingStrategy{
+			"BTC": {Name: PricingStrategyBidRatio},
+			"ETH": {Name: PricingStrategyAskMinusTicks, Ticks: 2},
+			"SOL": {Name: PricingStrategyBookDepth, Depth: 5},
+		})
+	})
+	t.Run("panic - invalid name", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Pricing strategy 'unknown' for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidatePricingStrategies("key", map[string]PricingStrategy{
+			"BTC": {Name: "unknown"},
+		})
+	})
+	t.Run("panic - non positive depth", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Depth of pricing strategy for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidatePricingStrategies("key", map[string]PricingStrategy{
+			"BTC": {Name: PricingStrategyBookDepth},
+		})
+	})
+}
+
+func Test_mustValidateFallbackStrategies(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: FallbackStrategyIocAtAsk, AfterWindows: 20, MaxPremium: 0.01},
+			"ETH": {Name: FallbackStrategyAggressiveLimit, AfterWindows: 10, Step: 0.001, MaxPremium: 0.005},
+			"SOL": {Name: FallbackStrategySkipAndCarry, AfterWindows: 0},
+		})
+	})
+	t.Run("panic - invalid name", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Fallback strategy 'unknown' for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: "unknown"},
+		})
+	})
+	t.Run("panic - non positive step", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Step of fallback strategy for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: FallbackStrategyAggressiveLimit, MaxPremium: 0.005},
+		})
+	})
+	t.Run("panic - after windows out of range", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "After windows of fallback strategy for crypto ticker 'BTC' must be within [0, 23) for key 'key'")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: FallbackStrategyIocAtAsk, AfterWindows: 23, MaxPremium: 0.01},
+		})
+	})
+	t.Run("panic - negative max premium", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Max premium of fallback strategy for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: FallbackStrategyIocAtAsk, MaxPremium: -0.01},
+		})
+	})
+	t.Run("panic - zero max premium", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Max premium of fallback strategy for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidateFallbackStrategies("key", map[string]FallbackStrategy{
+			"BTC": {Name: FallbackStrategyAggressiveLimit, Step: 0.001},
+		})
+	})
+}
+
+func Test_mustValidateSchedules(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateSchedules("key", map[string]Schedule{
+			"BTC": {Name: ScheduleWeekdays, Weekdays: []string{"mon", "thu"}},
+			"ETH": {Name: ScheduleCron, Cron: "0 0 1,15 * *"},
+		})
+	})
+	t.Run("panic - invalid weekday", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Weekday 'monday' of schedule for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateSchedules("key", map[string]Schedule{"BTC": {Name: ScheduleWeekdays, Weekdays: []string{"monday"}}})
+	})
+	t.Run("panic - non positive days", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Days of schedule for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidateSchedules("key", map[string]Schedule{"BTC": {Name: ScheduleEveryNDays}})
+	})
+	t.Run("panic - invalid cron", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Cron expression '* *' of schedule for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateSchedules("key", map[string]Schedule{"BTC": {Name: ScheduleCron, Cron: "* *"}})
+	})
+	t.Run("panic - invalid schedule", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Schedule 'hourly' for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateSchedules("key", map[string]Schedule{"BTC": {Name: "hourly"}})
+	})
+}
+
+func Test_mustValidateExchanges(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateExchanges("key", map[string]string{"BTC": ExchangeGemini, "ETH": ExchangeKraken})
+	})
+	t.Run("panic - invalid exchange", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Exchange 'binance' for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateExchanges("key", map[string]string{"BTC": "binance"})
+	})
+}
+
+func Test_mustValidateBudgetCurrency(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateBudgetCurrency("key", &Config{}, BudgetCurrencyQuote)
+		mustValidateBudgetCurrency("key", &Config{Fx: Fx{ReportingCurrency: "SGD"}}, BudgetCurrencyReporting)
+	})
+	t.Run("panic - missing reporting currency", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Budget currency 'reporting' requires 'REPORTING_CURRENCY' for key 'key'")
+		mustValidateBudgetCurrency("key", &Config{}, BudgetCurrencyReporting)
+	})
+	t.Run("panic - invalid budget currency", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Budget currency 'usd' is invalid for key 'key'")
+		mustValidateBudgetCurrency("key", &Config{}, "usd")
+	})
+}
+
+func Test_mustValidateFxRateProvider(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateFxRateProvider("key", FxRateProviderFile)
+	})
+	t.Run("panic - invalid fx rate provider", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "FX rate provider 'ecb' is invalid for key 'key'")
+		mustValidateFxRateProvider("key", "ecb")
+	})
+}
+
+func Test_mustTransformJsonStringToFxRates(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		val := mustTransformJsonStringToFxRates("key", `{"usd/sgd":1.35,"EUR/SGD":1.45}`)
+		assert.Equal(t, map[string]float64{"USD/SGD": 1.35, "EUR/SGD": 1.45}, val)
+	})
+	t.Run("panic - invalid currency pair", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "FX rate 1.35 of 'USDSGD' is invalid for key 'key'")
+		mustTransformJsonStringToFxRates("key", `{"USDSGD":1.35}`)
+	})
+	t.Run("panic - non positive rate", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "FX rate 0 of 'USD/SGD' is invalid for key 'key'")
+		mustTransformJsonStringToFxRates("key", `{"USD/SGD":0}`)
+	})
+}
+
+func Test_mustValidateNonceMode(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateNonceMode("key", NonceModeTimeWindow)
+	})
+	t.Run("panic - invalid mode", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Nonce mode 'random' is invalid for key 'key'")
+		mustValidateNonceMode("key", "random")
+	})
+}
+
+func Test_mustValidateJournalStore(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateJournalStore("key", JournalStoreFile)
+	})
+	t.Run("panic - invalid store", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Journal store 'random' is invalid for key 'key'")
+		mustValidateJournalStore("key", "random")
+	})
+}
+
+func Test_mustValidateOrderUpdates(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateOrderUpdates("key", OrderUpdatesPolling)
+	})
+	t.Run("panic - invalid order updates", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Order updates 'random' is invalid for key 'key'")
+		mustValidateOrderUpdates("key", "random")
+	})
+}
+
+func Test_mustValidateMarketData(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateMarketData("key", MarketDataPolling)
+	})
+	t.Run("panic - invalid market data", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Market data 'random' is invalid for key 'key'")
+		mustValidateMarketData("key", "random")
+	})
+}
+
+func Test_mustValidateRepriceBidMoveRatio(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateRepriceBidMoveRatio("key", 0)
+	})
+	t.Run("panic - negative ratio", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Reprice bid move ratio '-0.1' is invalid for key 'key'")
+		mustValidateRepriceBidMoveRatio("key", -0.1)
+	})
+	t.Run("panic - ratio of 1", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Reprice bid move ratio '1' is invalid for key 'key'")
+		mustValidateRepriceBidMoveRatio("key", 1)
+	})
+}
+
+func Test_mustValidateRunMode(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateRunMode("key", RunModeDaemon)
+	})
+	t.Run("panic - invalid mode", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Run mode 'cron' is invalid for key 'key'")
+		mustValidateRunMode("key", "cron")
+	})
+}
+
+func Test_mustValidateDaemonRunTimes(t *testing.T) {
+	config := &Config{CryptoTickers: map[string]bool{"BTC": true, "ETH": true}}
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateDaemonRunTimes("key", config, map[string]string{"BTC": "08:00", "ETH": "23:59"})
+	})
+	t.Run("panic - missing run time", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Crypto ticker 'ETH' is missing a run time for key 'key'")
+		mustValidateDaemonRunTimes("key", config, map[string]string{"BTC": "08:00"})
+	})
+	t.Run("panic - invalid run time", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Run time '24:00' for crypto ticker 'BTC' is invalid for key 'key'")
+		mustValidateDaemonRunTimes("key", config, map[string]string{"BTC": "24:00", "ETH": "08:00"})
+	})
+}
+
+func Test_mustLoadLocation(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		loc := mustLoadLocation("key", "Asia/Singapore")
+		assert.Equal(t, "Asia/Singapore", loc.String())
+	})
+	t.Run("panic - invalid timezone", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Unable to load timezone 'Mars/Olympus' for key 'key'")
+		mustLoadLocation("key", "Mars/Olympus")
+	})
+}
+
+func Test_mustValidateFiatCaps(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateFiatCaps("key", map[string]float64{"BTC": 10})
+	})
+	t.Run("panic - non positive cap", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Cap for crypto ticker 'BTC' must be positive for key 'key'")
+		mustValidateFiatCaps("key", map[string]float64{"BTC": 0})
+	})
+}
+
+func Test_mustValidateFiatCap(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateFiatCap("key", 10)
+	})
+	t.Run("panic - non positive cap", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Cap must be positive for key 'key'")
+		mustValidateFiatCap("key", -1)
+	})
+}
+
+func Test_mustValidateBalanceCheckMode(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		mustValidateBalanceCheckMode("key", BalanceCheckModeScale)
+	})
+	t.Run("panic - invalid mode", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Balance check mode 'unknown' is invalid for key 'key'")
+		mustValidateBalanceCheckMode("key", "unknown")
+	})
+}
+
 func Test_mustParseStrToType(t *testing.T) {
 	t.Run("ok - float64", func(t *testing.T) {
 		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
-		val := mustParseStrToType("key", "1.23", reflect.Float64)
+		val := mustParseStrToType[float64]("key", "1.23", reflect.Float64)
 		assert.Equal(t, float64(1.23), val)
 	})
+	t.Run("ok - int", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		val := mustParseStrToType[int]("key", "7", reflect.Int)
+		assert.Equal(t, 7, val)
+	})
+	t.Run("panic - unable to parse int", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Unable to parse key 'key', value: '1.5' of type 'int'")
+		mustParseStrToType[int]("key", "1.5", reflect.Int)
+	})
 	t.Run("panic - unable to parse float", func(t *testing.T) {
 		defer util.RecoverAndGraceFullyExitTestHelper(t, "Unable to parse key 'key', value: 'a' of type 'float64'")
-		mustParseStrToType("key", "a", reflect.Float64)
+		mustParseStrToType[float64]("key", "a", reflect.Float64)
 	})
 	t.Run("panic - invalid type", func(t *testing.T) {
 		defer util.RecoverAndGraceFullyExitTestHelper(t, "Type is not allowed 'string' for key 'key'")
-		mustParseStrToType("key", "a", reflect.String)
+		mustParseStrToType[float64]("key", "a", reflect.String)
 	})
 }
 
@@ -247,3 +548,23 @@ func Test_mustParseColStringToInt(t *testing.T) {
 		})
 	}
 }
+
+func Test_mustTransformJsonStringToRateLimits(t *testing.T) {
+	t.Run("ok - defaults kept", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		val := mustTransformJsonStringToRateLimits("key", `{"private":{"perSecond":2,"burst":4}}`, defaultGeminiRateLimits)
+		assert.Equal(t, GeminiRateLimits{
+			Public:  defaultGeminiRateLimits.Public,
+			Private: RateLimit{PerSecond: 2, Burst: 4},
+		}, val)
+	})
+	t.Run("ok - disabled", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "")
+		val := mustTransformJsonStringToRateLimits("key", `{"public":{"perSecond":0,"burst":0}}`, defaultGeminiRateLimits)
+		assert.Equal(t, GeminiRateLimits{Private: defaultGeminiRateLimits.Private}, val)
+	})
+	t.Run("panic - non positive burst", func(t *testing.T) {
+		defer util.RecoverAndGraceFullyExitTestHelper(t, "Rate limit 'public' of {PerSecond:1 Burst:0} is invalid for key 'key'")
+		mustTransformJsonStringToRateLimits("key", `{"public":{"perSecond":1,"burst":0}}`, defaultGeminiRateLimits)
+	})
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/sentry"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+	"github.com/robfig/cron/v3"
+)
+
+// State of a run of the daemon, FinishedAt is nil while running
+type RunState struct {
+	Tickers    []string   `json:"tickers"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Err        string     `json:"error,omitempty"`
+}
+
+type DaemonState struct {
+	LastRun  *RunState                    `json:"lastRun"`
+	NextRuns map[string]time.Time         `json:"nextRuns"` // keyed by run time
+	Retries  map[string]util.RetryMetrics `json:"retries"`  // of the exchange calls since the daemon started, keyed by call
+}
+
+type daemon struct {
+	scheduler *cron.Cron
+	entries   map[string]cron.EntryID // keyed by run time
+	runMu     sync.Mutex              // runs are serialised, see run
+	stateMu   sync.Mutex
+	lastRun   *RunState
+
+	cancelRuns   context.CancelFunc // interrupts the run in progress, see shutdown
+	shuttingDown atomic.Bool        // no run is started once set, e.g. one due while another was in progress
+}
+
+// Entry point of RunModeDaemon, runs every ticker at its run time until SIGINT or SIGTERM
+//
+// On shutdown, the run in progress is given until the shutdown timeout to complete, it is interrupted & its live orders
+// are cancelled otherwise for the run to be resumed from the journal by the next one. Returns ErrInterrupted once shut
+// down
+func RunDaemon(ctx context.Context) error {
+	location := "cmd.RunDaemon"
+	c := config.Get()
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Not cancelled by the signal, runs are only interrupted once the shutdown timeout is up
+	runCtx, cancelRuns := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRuns()
+
+	d := &daemon{
+		scheduler:  cron.New(cron.WithLocation(c.Daemon.Location)),
+		entries:    make(map[string]cron.EntryID),
+		cancelRuns: cancelRuns,
+	}
+	for runTime, tickers := range groupTickersByRunTime(c.Daemon.RunTimes) {
+		spec, err := formCronSpec(runTime)
+		if err != nil {
+			logger.Error(location, "Invalid run time '%s'", err, runTime)
+			return err
+		}
+		entryID, err := d.scheduler.AddFunc(spec, func() {
+			d.run(runCtx, tickers)
+		})
+		if err != nil {
+			logger.Error(location, "Failed to schedule run time '%s'", err, runTime)
+			return err
+		}
+		d.entries[runTime] = entryID
+	}
+
+	if c.Daemon.StatusAddr != "" {
+		server := &http.Server{Addr: c.Daemon.StatusAddr, Handler: d.statusHandler()}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(location, "Status server stopped", err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	d.scheduler.Start()
+	logger.Info(location, "Daemon started, next runs: %v", d.state().NextRuns)
+
+	<-signalCtx.Done()
+	logger.Info(location, "Shutting down...")
+	if !d.shutdown(runCtx, c.Daemon.ShutdownTimeout, c.CryptoTickers) {
+		logger.Warn(location, "Run in progress did not flush in time, to be resumed from the journal by the next one")
+	}
+
+	return ErrInterrupted
+}
+
+// Waits for the run in progress, if any, to complete. It is interrupted if it does not within the timeout, then given
+// another timeout to cancel its live orders & flush its fills, so that the daemon does not exit midway through the flush.
+// Live orders left journaled after that are cancelled
+//
+// Returns whether there is no run left in progress
+func (d *daemon) shutdown(ctx context.Context, timeout time.Duration, tickers map[string]bool) bool {
+	location := "cmd.daemon.shutdown"
+	d.shuttingDown.Store(true)
+	stopped := d.scheduler.Stop()
+	select {
+	case <-stopped.Done():
+		logger.Info(location, "No run in progress, tearing down...")
+		return true
+	case <-time.After(timeout):
+		logger.Warn(location, "Run in progress did not complete in %v, interrupting it", timeout)
+		if d.cancelRuns != nil {
+			d.cancelRuns()
+		}
+	}
+	select {
+	case <-stopped.Done():
+		logger.Info(location, "Run in progress completed, tearing down...")
+		return true
+	case <-time.After(timeout):
+		logger.Warn(location, "Run in progress did not flush in %v, cancelling its live orders", timeout)
+		cancelLiveJournaledOrders(context.WithoutCancel(ctx), tickers)
+		return false
+	}
+}
+
+// Runs are serialised, a run due while another is in progress starts once it completes, so that a run does not have
+// its date changed by another
+func (d *daemon) run(ctx context.Context, tickers map[string]bool) {
+	location := "cmd.daemon.run"
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+	if ctx.Err() != nil || d.shuttingDown.Load() {
+		return
+	}
+
+	config.RefreshTime()
+	runState := &RunState{Tickers: sortedTickers(tickers), StartedAt: config.GetTime().Now()}
+	d.setLastRun(runState)
+
+	err := RunTickers(ctx, tickers)
+
+	finishedAt := time.Now()
+	d.stateMu.Lock()
+	runState.FinishedAt = &finishedAt
+	if err != nil {
+		runState.Err = err.Error()
+	}
+	d.stateMu.Unlock()
+	if errors.Is(err, ErrInterrupted) {
+		logger.Warn(location, "Run of %v is interrupted", runState.Tickers)
+	} else if err != nil {
+		logger.Error(location, "Run of %v failed", err, runState.Tickers)
+		sentry.CaptureErr(err)
+	}
+}
+
+func (d *daemon) setLastRun(runState *RunState) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.lastRun = runState
+}
+
+func (d *daemon) state() DaemonState {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	state := DaemonState{NextRuns: make(map[string]time.Time, len(d.entries)), Retries: util.GetRetryMetrics()}
+	if d.lastRun != nil {
+		lastRun := *d.lastRun
+		state.LastRun = &lastRun
+	}
+	for runTime, entryID := range d.entries {
+		state.NextRuns[runTime] = d.scheduler.Entry(entryID).Next
+	}
+	return state
+}
+
+// Serves the state as json on /status
+func (d *daemon) statusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(d.state())
+	})
+	return mux
+}
+
+// Tickers of the same run time are run together
+func groupTickersByRunTime(runTimes map[string]string) map[string]map[string]bool {
+	tickersByRunTime := make(map[string]map[string]bool)
+	for ticker, runTime := range runTimes {
+		if _, ok := tickersByRunTime[runTime]; !ok {
+			tickersByRunTime[runTime] = make(map[string]bool)
+		}
+		tickersByRunTime[runTime][ticker] = true
+	}
+	return tickersByRunTime
+}
+
+// Daily cron spec of a run time in the format of HH:MM
+func formCronSpec(runTime string) (string, error) {
+	t, err := time.Parse("15:04", runTime)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()), nil
+}
+
+func sortedTickers(tickers map[string]bool) []string {
+	s := make([]string, 0, len(tickers))
+	for ticker := range tickers {
+		s = append(s, ticker)
+	}
+	sort.Strings(s)
+	return s
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_groupTickersByRunTime(t *testing.T) {
+	got := groupTickersByRunTime(map[string]string{"BTC": "08:00", "ETH": "20:30", "SOL": "08:00"})
+	assert.Equal(t, map[string]map[string]bool{
+		"08:00": {"BTC": true, "SOL": true},
+		"20:30": {"ETH": true},
+	}, got)
+}
+
+func Test_formCronSpec(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		got, err := formCronSpec("08:05")
+		assert.NoError(t, err)
+		assert.Equal(t, "5 8 * * *", got)
+	})
+	t.Run("error", func(t *testing.T) {
+		_, err := formCronSpec("8am")
+		assert.Error(t, err)
+	})
+}
+
+func Test_daemon_statusHandler(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	d := &daemon{
+		scheduler: cron.New(cron.WithLocation(loc)),
+		entries:   make(map[string]cron.EntryID),
+	}
+	entryID, err := d.scheduler.AddFunc("0 8 * * *", func() {})
+	assert.NoError(t, err)
+	d.entries["08:00"] = entryID
+	d.scheduler.Start()
+	defer d.scheduler.Stop()
+
+	startedAt := time.Date(2024, time.November, 3, 0, 0, 0, 0, time.UTC)
+	d.setLastRun(&RunState{Tickers: []string{"BTC"}, StartedAt: startedAt, Err: "error"})
+
+	rec := httptest.NewRecorder()
+	d.statusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var got DaemonState
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, &RunState{Tickers: []string{"BTC"}, StartedAt: startedAt, Err: "error"}, got.LastRun)
+	next := got.NextRuns["08:00"].In(loc)
+	assert.Equal(t, 8, next.Hour())
+	assert.Equal(t, 0, next.Minute())
+	assert.NotNil(t, got.Retries)
+}
+
+func Test_daemon_shutdown(t *testing.T) {
+	ctx := util.TestContext()
+	config.TestInit(nil, &config.TestNow)
+
+	tests := []struct {
+		name            string
+		runTime         time.Duration // of the run in progress
+		isInterruptible bool          // whether the run in progress stops once interrupted
+		want            bool
+		wantInterrupted bool
+	}{
+		{
+			name:            "ok_completed_in_time",
+			runTime:         25 * time.Millisecond,
+			isInterruptible: true,
+			want:            true,
+		},
+		{
+			name:            "ok_completed_after_interrupting",
+			runTime:         time.Second,
+			isInterruptible: true,
+			want:            true,
+			wantInterrupted: true,
+		},
+		{
+			name:            "ok_not_flushed_in_time",
+			runTime:         time.Second,
+			want:            false,
+			wantInterrupted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
+			runCtx, cancelRuns := context.WithCancel(ctx)
+			defer cancelRuns()
+			d := &daemon{
+				scheduler:  cron.New(),
+				entries:    make(map[string]cron.EntryID),
+				cancelRuns: cancelRuns,
+			}
+			started := make(chan struct{})
+			var once sync.Once
+			_, err := d.scheduler.AddFunc("@every 1s", func() {
+				once.Do(func() { close(started) })
+				if !tt.isInterruptible {
+					time.Sleep(tt.runTime)
+					return
+				}
+				select {
+				case <-runCtx.Done():
+				case <-time.After(tt.runTime):
+				}
+			})
+			assert.NoError(t, err)
+			d.scheduler.Start()
+			<-started
+
+			assert.Equal(t, tt.want, d.shutdown(ctx, 75*time.Millisecond, config.Get().CryptoTickers))
+			assert.Equal(t, tt.wantInterrupted, runCtx.Err() != nil)
+		})
+	}
+}
@@ -1,37 +1,58 @@
 package cmd
 
 import (
-	"strings"
+	"context"
+	"time"
 
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
-	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
-func formRows(postOrders *treemap.Map) []*db.Order {
+// Rows are created for the day of the fills. Fiat deposits are recorded both in the quote currency of the ticker & in the
+// reporting currency, at the current rate. The reporting currency fields are left empty if the fiat deposit cannot be
+// converted, to be converted when read instead
+func formRows(ctx context.Context, day time.Time, postOrders *treemap.Map) []*db.Order {
+	location := "cmd.formRows"
 	orders := make([]*db.Order, postOrders.Size())
 	i := 0
 	it := postOrders.Iterator()
 	for it.Next() {
 		ticker, postOrder := it.Key().(string), it.Value().(PostOrder)
+		reportingCurrency := config.Get().GetReportingCurrency(ticker)
+		fiatDepositInReportingCurrency, fxRate, err := toReportingCurrency(ctx, ticker, postOrder.ActualFiatDeposit)
+		if err != nil {
+			logger.Warn(location, "'%s' Unable to convert fiat deposit into reporting currency, leaving it empty, err: %v", ticker, err)
+			reportingCurrency, fiatDepositInReportingCurrency, fxRate = "", 0, 0
+		}
 		orders[i] = &db.Order{
-			Ticker:            strings.ToLower(gemini.AppendTickerWithQuoteCurrency(ticker)),
-			CreatedForDay:     config.GetTime().GetTodayDate(),
-			FiatDepositInSGD:  postOrder.ActualFiatDeposit,
-			PricePerCoinInSGD: postOrder.AvgExecutionPrice,
-			CoinAmount:        postOrder.ExecutedAmount,
-			CreatedAt:         config.GetTime().Now(),
-			UpdatedAt:         config.GetTime().Now(),
+			Ticker:                         exchange.Pair(ticker),
+			CreatedForDay:                  day,
+			QuoteCurrency:                  config.Get().GetQuoteCurrency(ticker),
+			FiatDeposit:                    postOrder.ActualFiatDeposit,
+			PricePerCoin:                   postOrder.AvgExecutionPrice,
+			CoinAmount:                     postOrder.ExecutedAmount,
+			Fee:                            postOrder.Fee,
+			FeeCurrency:                    postOrder.FeeCurrency,
+			ReportingCurrency:              reportingCurrency,
+			FxRate:                         fxRate,
+			FiatDepositInReportingCurrency: fiatDepositInReportingCurrency,
+			CreatedAt:                      config.GetTime().Now(),
+			UpdatedAt:                      config.GetTime().Now(),
 		}
 		i++
 	}
 	return orders
 }
 
-func bulkInsertIntoDB(postOrders *treemap.Map) error {
-	orders := formRows(postOrders)
-	if err := db.Get().BulkInsert(orders); err != nil {
+func bulkUpsertIntoDB(ctx context.Context, day time.Time, postOrders *treemap.Map) error {
+	orders := formRows(ctx, day, postOrders)
+	if len(orders) == 0 {
+		return nil
+	}
+	if err := db.Get().BulkUpsert(orders); err != nil {
 		return err
 	}
 	return nil
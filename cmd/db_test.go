@@ -6,6 +6,8 @@ import (
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,32 +20,83 @@ func Test_formRows(t *testing.T) {
 			ActualFiatDeposit: 1.002,
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
+			Fee:               0.002,
+			FeeCurrency:       "SGD",
 		})
 		postOrders.Put("ETH", PostOrder{
 			ActualFiatDeposit: 2.004,
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
+			Fee:               0.004,
+			FeeCurrency:       "SGD",
 		})
-		got := formRows(postOrders)
+		got := formRows(util.TestContext(), config.GetTime().GetTodayDate(), postOrders)
 		assert.Equal(t, []*db.Order{
 			{
-				Ticker:            "btcsgd",
-				CreatedForDay:     config.GetTime().GetTodayDate(),
-				FiatDepositInSGD:  1.002,
-				PricePerCoinInSGD: 1000,
-				CoinAmount:        1,
-				CreatedAt:         config.GetTime().Now(),
-				UpdatedAt:         config.GetTime().Now(),
+				Ticker:                         "btcsgd",
+				CreatedForDay:                  config.GetTime().GetTodayDate(),
+				QuoteCurrency:                  "SGD",
+				FiatDeposit:                    1.002,
+				PricePerCoin:                   1000,
+				CoinAmount:                     1,
+				Fee:                            0.002,
+				FeeCurrency:                    "SGD",
+				ReportingCurrency:              "SGD",
+				FxRate:                         1,
+				FiatDepositInReportingCurrency: 1.002,
+				CreatedAt:                      config.GetTime().Now(),
+				UpdatedAt:                      config.GetTime().Now(),
 			},
 			{
-				Ticker:            "ethsgd",
-				CreatedForDay:     config.GetTime().GetTodayDate(),
-				FiatDepositInSGD:  2.004,
-				PricePerCoinInSGD: 1000,
-				CoinAmount:        1,
-				CreatedAt:         config.GetTime().Now(),
-				UpdatedAt:         config.GetTime().Now(),
+				Ticker:                         "ethsgd",
+				CreatedForDay:                  config.GetTime().GetTodayDate(),
+				QuoteCurrency:                  "SGD",
+				FiatDeposit:                    2.004,
+				PricePerCoin:                   1000,
+				CoinAmount:                     1,
+				Fee:                            0.004,
+				FeeCurrency:                    "SGD",
+				ReportingCurrency:              "SGD",
+				FxRate:                         1,
+				FiatDepositInReportingCurrency: 2.004,
+				CreatedAt:                      config.GetTime().Now(),
+				UpdatedAt:                      config.GetTime().Now(),
 			},
 		}, got)
 	})
+	t.Run("ok - reporting currency", func(t *testing.T) {
+		defer config.TestInit(nil, nil)
+		config.TestInit(&config.ConfigUpdateable{Fx: &config.Fx{ReportingCurrency: "USD", BudgetCurrency: config.BudgetCurrencyQuote}}, nil)
+		fx.Set(fx.NewStaticProvider(map[string]float64{"USD/SGD": 1.25}))
+		defer fx.Set(nil)
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{
+			ActualFiatDeposit: 1.002,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1,
+		})
+		got := formRows(util.TestContext(), config.GetTime().GetTodayDate(), postOrders)
+		assert.Equal(t, "SGD", got[0].QuoteCurrency)
+		assert.Equal(t, 1.002, got[0].FiatDeposit)
+		assert.Equal(t, "USD", got[0].ReportingCurrency)
+		assert.Equal(t, 0.8, got[0].FxRate)
+		assert.InDelta(t, 0.8016, got[0].FiatDepositInReportingCurrency, 1e-9)
+	})
+
+	t.Run("ok - fx rate not found", func(t *testing.T) {
+		defer config.TestInit(nil, nil)
+		config.TestInit(&config.ConfigUpdateable{Fx: &config.Fx{ReportingCurrency: "EUR", BudgetCurrency: config.BudgetCurrencyQuote}}, nil)
+		fx.Set(fx.NewStaticProvider(nil))
+		defer fx.Set(nil)
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{ActualFiatDeposit: 1.002})
+		got := formRows(util.TestContext(), config.GetTime().GetTodayDate(), postOrders)
+		// recorded without the reporting currency, to be converted when read instead
+		assert.Equal(t, 1.002, got[0].FiatDeposit)
+		assert.Equal(t, "", got[0].ReportingCurrency)
+		assert.Equal(t, float64(0), got[0].FxRate)
+		assert.Equal(t, float64(0), got[0].FiatDepositInReportingCurrency)
+	})
 }
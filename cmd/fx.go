@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Daily fiat amount of every ticker as configured, in the quote currency of the ticker. Amounts configured in the
+// reporting currency are converted at the current rate, so that the order is sized in the currency it is paid in
+func getDailyFiatBudgets(ctx context.Context) (map[string]float64, error) {
+	location := "cmd.getDailyFiatBudgets"
+	c := config.Get()
+
+	dailyFiatBudgets := make(map[string]float64, len(c.CryptoTickers))
+	for ticker := range c.CryptoTickers {
+		dailyFiatAmount := c.OrderMetadata.DailyFiatAmount[ticker]
+		if !c.IsBudgetInReportingCurrency() || dailyFiatAmount <= 0 {
+			dailyFiatBudgets[ticker] = dailyFiatAmount
+			continue
+		}
+
+		converted, rate, err := fx.Convert(ctx, dailyFiatAmount, c.GetReportingCurrency(ticker), c.GetQuoteCurrency(ticker))
+		if err != nil {
+			logger.Error(location, "'%s' Error converting daily fiat amount of %v %s", err, ticker, dailyFiatAmount, c.GetReportingCurrency(ticker))
+			return nil, err
+		}
+		logger.Info(location, "'%s' Daily fiat amount of %v %s is %v %s at rate %v", ticker, dailyFiatAmount, c.GetReportingCurrency(ticker), converted, c.GetQuoteCurrency(ticker), rate)
+		dailyFiatBudgets[ticker] = roundFiatAmount(converted)
+	}
+	return dailyFiatBudgets, nil
+}
+
+// Fiat amount of the ticker, in its quote currency, in the reporting currency. Returns the converted amount & the rate
+func toReportingCurrency(ctx context.Context, ticker string, fiatAmount float64) (float64, float64, error) {
+	c := config.Get()
+	return fx.Convert(ctx, fiatAmount, c.GetQuoteCurrency(ticker), c.GetReportingCurrency(ticker))
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getDailyFiatBudgets(t *testing.T) {
+	tests := []struct {
+		name    string
+		fx      config.Fx
+		rates   map[string]float64
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name: "ok_quote_currency",
+			fx:   config.Fx{BudgetCurrency: config.BudgetCurrencyQuote},
+			want: map[string]float64{"BTC": 1, "ETH": 2},
+		},
+		{
+			name:  "ok_reporting_currency",
+			fx:    config.Fx{ReportingCurrency: "USD", BudgetCurrency: config.BudgetCurrencyReporting},
+			rates: map[string]float64{"USD/SGD": 1.35},
+			want:  map[string]float64{"BTC": 1.35, "ETH": 2.7},
+		},
+		{
+			name:    "error_rate_not_found",
+			fx:      config.Fx{ReportingCurrency: "USD", BudgetCurrency: config.BudgetCurrencyReporting},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.TestInit(&config.ConfigUpdateable{Fx: &tt.fx}, &config.TestNow)
+			fx.Set(fx.NewStaticProvider(tt.rates))
+
+			got, err := getDailyFiatBudgets(util.TestContext())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/google_sheets"
@@ -9,49 +12,79 @@ import (
 	"google.golang.org/api/sheets/v4"
 )
 
-func formBatchUpdateRequest(sheetID int64, postOrders *treemap.Map) *sheets.BatchUpdateSpreadsheetRequest {
-	cellRanges := config.Get().GoogleSheet.CellRanges
+// Fiat amounts are shown in the quote currency of the ticker, e.g. 1,000.00 SGD
+func fiatNumberFormat(quoteCurrency string) *sheets.NumberFormat {
+	return &sheets.NumberFormat{Type: "NUMBER", Pattern: fmt.Sprintf(`#,##0.00######" %s"`, quoteCurrency)}
+}
 
+// Per ticker, the values of its row for the day are updated, then the format of its fiat amounts, i.e. the fiat deposit
+// & price per coin, which is left to the sheet for every other cell
+func formBatchUpdateRequest(sheetID int64, day time.Time, postOrders *treemap.Map) *sheets.BatchUpdateSpreadsheetRequest {
 	req := &sheets.BatchUpdateSpreadsheetRequest{
-		Requests: make([]*sheets.Request, postOrders.Size()),
+		Requests: make([]*sheets.Request, 0, 2*postOrders.Size()),
 	}
-	i := 0
 	it := postOrders.Iterator()
 	for it.Next() {
 		ticker, postOrder := it.Key().(string), it.Value().(PostOrder)
-		cellRange := cellRanges[ticker]
+		cellRange := config.Get().GetCellRange(ticker, day)
 		cellRange.SheetId = sheetID
 
-		req.Requests[i] = &sheets.Request{
+		values := []*sheets.CellData{ // per row, i.e. per ticker
+			{UserEnteredValue: &sheets.ExtendedValue{StringValue: util.PtrOf(config.FormatDate(day))}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.ActualFiatDeposit)}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.AvgExecutionPrice)}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.ExecutedAmount)}},
+		}
+		// Carried forward amount is only written to column ranges wide enough for it
+		if cellRange.EndColumnIndex-cellRange.StartColumnIndex > int64(len(values)) {
+			values = append(values, &sheets.CellData{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.CarriedForward)}})
+		}
+
+		fiatFormat := &sheets.CellFormat{NumberFormat: fiatNumberFormat(config.Get().GetQuoteCurrency(ticker))}
+		req.Requests = append(req.Requests, &sheets.Request{
 			UpdateCells: &sheets.UpdateCellsRequest{
 				Range: cellRange,
 				Rows: []*sheets.RowData{
 					{
-						Values: []*sheets.CellData{ // per row, i.e. per ticker
-							{UserEnteredValue: &sheets.ExtendedValue{StringValue: util.PtrOf(config.GetTime().GetNowDateString())}},
-							{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.ActualFiatDeposit)}},
-							{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.AvgExecutionPrice)}},
-							{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(postOrder.ExecutedAmount)}},
-						},
+						Values: values,
 					},
 				},
 				Fields: "userEnteredValue",
 			},
-		}
-		i++
+		}, &sheets.Request{
+			UpdateCells: &sheets.UpdateCellsRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    cellRange.StartRowIndex,
+					EndRowIndex:      cellRange.EndRowIndex,
+					StartColumnIndex: cellRange.StartColumnIndex + 1,
+					EndColumnIndex:   cellRange.StartColumnIndex + 3,
+				},
+				Rows: []*sheets.RowData{
+					{
+						Values: []*sheets.CellData{{UserEnteredFormat: fiatFormat}, {UserEnteredFormat: fiatFormat}},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		})
 	}
 
 	return req
 }
 
-func batchUpdate(postOrders *treemap.Map) error {
+func batchUpdate(day time.Time, postOrders *treemap.Map) error {
 	location := "cmd.batchUpdate"
+	if postOrders.Size() == 0 {
+		logger.Info(location, "No orders to update")
+		return nil
+	}
 	sheetID, err := google_sheets.Get().GetSheetID()
 	if err != nil {
 		logger.Error(location, "Getting google sheets sheet ID", err)
 		return err
 	}
-	googleSheetsReq := formBatchUpdateRequest(sheetID, postOrders)
+	googleSheetsReq := formBatchUpdateRequest(sheetID, day, postOrders)
 	err = google_sheets.Get().BatchUpdate(googleSheetsReq)
 	if err != nil {
 		logger.Error(location, "Batch updating google sheets", err)
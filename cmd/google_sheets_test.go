@@ -14,6 +14,7 @@ func Test_formBatchUpdateRequest(t *testing.T) {
 	config.TestInit(nil, &config.TestNow)
 
 	t.Run("ok", func(t *testing.T) {
+		sgdFormat := &sheets.CellFormat{NumberFormat: &sheets.NumberFormat{Type: "NUMBER", Pattern: `#,##0.00######" SGD"`}}
 		sheetID := 1234
 		postOrders := treemap.NewWithStringComparator()
 		postOrders.Put("BTC", PostOrder{
@@ -26,7 +27,7 @@ func Test_formBatchUpdateRequest(t *testing.T) {
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
 		})
-		got := formBatchUpdateRequest(int64(sheetID), postOrders)
+		got := formBatchUpdateRequest(int64(sheetID), config.TestNowDate, postOrders)
 		assert.Equal(t, &sheets.BatchUpdateSpreadsheetRequest{
 			Requests: []*sheets.Request{
 				{
@@ -51,6 +52,23 @@ func Test_formBatchUpdateRequest(t *testing.T) {
 						Fields: "userEnteredValue",
 					},
 				},
+				{
+					UpdateCells: &sheets.UpdateCellsRequest{
+						Range: &sheets.GridRange{
+							SheetId:          1234,
+							StartRowIndex:    2,
+							EndRowIndex:      3,
+							StartColumnIndex: 5,
+							EndColumnIndex:   7,
+						},
+						Rows: []*sheets.RowData{
+							{
+								Values: []*sheets.CellData{{UserEnteredFormat: sgdFormat}, {UserEnteredFormat: sgdFormat}},
+							},
+						},
+						Fields: "userEnteredFormat.numberFormat",
+					},
+				},
 				{
 					UpdateCells: &sheets.UpdateCellsRequest{
 						Range: &sheets.GridRange{
@@ -73,7 +91,59 @@ func Test_formBatchUpdateRequest(t *testing.T) {
 						Fields: "userEnteredValue",
 					},
 				},
+				{
+					UpdateCells: &sheets.UpdateCellsRequest{
+						Range: &sheets.GridRange{
+							SheetId:          1234,
+							StartRowIndex:    3,
+							EndRowIndex:      4,
+							StartColumnIndex: 9,
+							EndColumnIndex:   11,
+						},
+						Rows: []*sheets.RowData{
+							{
+								Values: []*sheets.CellData{{UserEnteredFormat: sgdFormat}, {UserEnteredFormat: sgdFormat}},
+							},
+						},
+						Fields: "userEnteredFormat.numberFormat",
+					},
+				},
 			},
 		}, got)
 	})
+	t.Run("ok - carried forward column", func(t *testing.T) {
+		defer config.TestInit(nil, &config.TestNow)
+		config.Get().GoogleSheet.CellRanges["BTC"].EndColumnIndex = 9
+
+		sheetID := 1234
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{
+			ActualFiatDeposit: 1.503,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1.5,
+			CarriedForward:    0.5,
+		})
+		got := formBatchUpdateRequest(int64(sheetID), config.TestNowDate, postOrders)
+		assert.Equal(t, []*sheets.CellData{
+			{UserEnteredValue: &sheets.ExtendedValue{StringValue: &config.TestNowDateStr}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(1.503)}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1000))}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(1.5)}},
+			{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(0.5)}},
+		}, got.Requests[0].UpdateCells.Rows[0].Values)
+	})
+	t.Run("ok - quote currency", func(t *testing.T) {
+		defer config.TestInit(nil, &config.TestNow)
+		config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "SGD", "ETH": "USD"}}, &config.TestNow)
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("ETH", PostOrder{
+			ActualFiatDeposit: 2.004,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1,
+		})
+		got := formBatchUpdateRequest(1234, config.TestNowDate, postOrders)
+		assert.Len(t, got.Requests, 2)
+		assert.Equal(t, `#,##0.00######" USD"`, got.Requests[1].UpdateCells.Rows[0].Values[0].UserEnteredFormat.NumberFormat.Pattern)
+	})
 }
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/journal"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Records the progress of a ticker's order loop. liveOrder is nil in between order windows
+//
+// Journal errors are logged but do not stop the order loop
+func journalProgress(ticker string, liveOrder *exchange.Order, orderOpenThenCancelWindowCounter, orderOpenQueryStatusWindowCounter int, fills *OrderFills) {
+	putJournalEntry(&journal.Entry{
+		Ticker:                            ticker,
+		OrderOpenThenCancelWindowCounter:  orderOpenThenCancelWindowCounter,
+		OrderOpenQueryStatusWindowCounter: orderOpenQueryStatusWindowCounter,
+		ExecutedAmount:                    fills.ExecutedAmount,
+		FiatSpent:                         fills.FiatSpent,
+		OrderIDs:                          fills.OrderIDs,
+	}, liveOrder)
+}
+
+// Records the client order id of the order about to be created, before it is sent. It is replaced by journalProgress
+// once the order is known
+func journalPendingOrder(ticker, clientOrderID string, orderOpenThenCancelWindowCounter int, fills *OrderFills) {
+	putJournalEntry(&journal.Entry{
+		Ticker:                           ticker,
+		ClientOrderID:                    clientOrderID,
+		OrderOpenThenCancelWindowCounter: orderOpenThenCancelWindowCounter,
+		ExecutedAmount:                   fills.ExecutedAmount,
+		FiatSpent:                        fills.FiatSpent,
+		OrderIDs:                         fills.OrderIDs,
+	}, nil)
+}
+
+// Order loop of the ticker has ended, the entry is kept until the fills are written to the db
+func journalCompleted(ticker string, orderOpenThenCancelWindowCounter int, fills *OrderFills) {
+	putJournalEntry(&journal.Entry{
+		Ticker:                           ticker,
+		OrderOpenThenCancelWindowCounter: orderOpenThenCancelWindowCounter,
+		ExecutedAmount:                   fills.ExecutedAmount,
+		FiatSpent:                        fills.FiatSpent,
+		OrderIDs:                         fills.OrderIDs,
+		FeeAmount:                        fills.FeeAmount,
+		FeeCurrency:                      fills.FeeCurrency,
+		IsCompleted:                      true,
+	}, nil)
+}
+
+func putJournalEntry(entry *journal.Entry, liveOrder *exchange.Order) {
+	location := "cmd.putJournalEntry"
+	entry.CreatedForDay = config.GetTime().GetTodayDate()
+	entry.UpdatedAt = config.GetTime().Now()
+	if liveOrder != nil {
+		entry.OrderID = liveOrder.OrderID
+		entry.ClientOrderID = liveOrder.ClientOrderID
+	}
+	if err := journal.Get().Put(entry); err != nil {
+		logger.Error(location, "'%s' Failed to journal progress", err, entry.Ticker)
+	}
+}
+
+// Only returns the entry if it is created for today
+func getJournalEntry(ticker string) *journal.Entry {
+	location := "cmd.getJournalEntry"
+	entry, err := journal.Get().Get(ticker)
+	if err != nil {
+		logger.Error(location, "'%s' Failed to get journal entry", err, ticker)
+		return nil
+	}
+	if entry == nil || !entry.CreatedForDay.Equal(config.GetTime().GetTodayDate()) {
+		return nil
+	}
+	return entry
+}
+
+func deleteJournalEntry(ticker string) {
+	location := "cmd.deleteJournalEntry"
+	if err := journal.Get().Delete(ticker); err != nil {
+		logger.Error(location, "'%s' Failed to delete journal entry", err, ticker)
+	}
+}
+
+// Cancels live orders left behind by crashed runs of previous days, then records their fills for the day they were
+// created for, unless already recorded
+//
+// Entries are kept if cancelling or recording fails, to be retried in the next run
+func cancelStaleJournaledOrders(ctx context.Context) {
+	location := "cmd.cancelStaleJournaledOrders"
+	entries, err := journal.Get().GetAll()
+	if err != nil {
+		logger.Error(location, "Failed to get journal entries", err)
+		return
+	}
+	today := config.GetTime().GetTodayDate()
+	for _, entry := range entries {
+		if entry.CreatedForDay.Equal(today) {
+			continue
+		}
+		fills := &OrderFills{
+			ExecutedAmount: entry.ExecutedAmount,
+			FiatSpent:      entry.FiatSpent,
+			OrderIDs:       entry.OrderIDs,
+			FeeAmount:      entry.FeeAmount,
+			FeeCurrency:    entry.FeeCurrency,
+		}
+		if entry.OrderID != "" {
+			logger.Warn(location, "'%s' Cancelling order '%s' left behind on %v", entry.Ticker, entry.OrderID, entry.CreatedForDay)
+			cancelledOrder, err := util.Retry(ctx, fmt.Sprintf("CancelOrder - %v", entry.Ticker), func() (*exchange.Order, error) {
+				return exchange.Get(entry.Ticker).CancelOrder(ctx, entry.OrderID)
+			})
+			if err != nil {
+				logger.Error(location, "'%s' Failed to cancel stale order '%s'", err, entry.Ticker, entry.OrderID)
+				continue
+			}
+			fills.add(cancelledOrder)
+		}
+		if fills.ExecutedAmount > 0 {
+			logger.Warn(location, "'%s' Recording fills left behind on %v: %v coins for %v", entry.Ticker, entry.CreatedForDay, fills.ExecutedAmount, fills.FiatSpent)
+			if err := recordStaleFills(ctx, entry.Ticker, entry.CreatedForDay, fills); err != nil {
+				logger.Error(location, "'%s' Failed to record fills left behind on %v", err, entry.Ticker, entry.CreatedForDay)
+				continue
+			}
+		}
+		deleteJournalEntry(entry.Ticker)
+	}
+}
+
+// Writes the fills to google sheets & the db under the day they are of, skipped if the day is already recorded, e.g.
+// by a run that crashed right after its db write
+func recordStaleFills(ctx context.Context, ticker string, day time.Time, fills *OrderFills) error {
+	location := "cmd.recordStaleFills"
+	rows, err := db.Get().GetOrdersCreatedForDay(day)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.Ticker == exchange.Pair(ticker) {
+			logger.Info(location, "'%s' Fills of %v are already recorded", ticker, day)
+			return nil
+		}
+	}
+	postOrders := treemap.NewWithStringComparator()
+	postOrders.Put(ticker, formPostOrderData(ticker, fills))
+	if err := batchUpdate(day, postOrders); err != nil {
+		return err
+	}
+	return bulkUpsertIntoDB(ctx, day, postOrders)
+}
+
+// Cancels live orders of the tickers' runs today that cannot complete, e.g. on shutdown. Entries are kept for the
+// orders' partial fills to be picked up when the runs are resumed
+func cancelLiveJournaledOrders(ctx context.Context, tickers map[string]bool) {
+	location := "cmd.cancelLiveJournaledOrders"
+	entries, err := journal.Get().GetAll()
+	if err != nil {
+		logger.Error(location, "Failed to get journal entries", err)
+		return
+	}
+	today := config.GetTime().GetTodayDate()
+	for _, entry := range entries {
+		if !entry.CreatedForDay.Equal(today) || entry.OrderID == "" || !tickers[entry.Ticker] {
+			continue
+		}
+		logger.Warn(location, "'%s' Cancelling live order '%s'", entry.Ticker, entry.OrderID)
+		if _, err := util.Retry(ctx, fmt.Sprintf("CancelOrder - %v", entry.Ticker), func() (*exchange.Order, error) {
+			return exchange.Get(entry.Ticker).CancelOrder(ctx, entry.OrderID)
+		}); err != nil {
+			logger.Error(location, "'%s' Failed to cancel live order '%s'", err, entry.Ticker, entry.OrderID)
+		}
+	}
+}
+
+// To be called once today's fills of the tickers are written to the db
+func clearJournal(tickers map[string]bool) {
+	location := "cmd.clearJournal"
+	entries, err := journal.Get().GetAll()
+	if err != nil {
+		logger.Error(location, "Failed to get journal entries", err)
+		return
+	}
+	today := config.GetTime().GetTodayDate()
+	for _, entry := range entries {
+		if entry.CreatedForDay.Equal(today) && tickers[entry.Ticker] {
+			deleteJournalEntry(entry.Ticker)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/golang/mock/gomock"
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/google_sheets"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/journal"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/mocks"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/sheets/v4"
+)
+
+func setTestJournal(t *testing.T) {
+	journal.Set(journal.NewFileJournal(filepath.Join(t.TempDir(), "journal.json")))
+}
+
+func Test_handlerCexApiCalls_resume(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+
+	t.Run("ok_completed", func(t *testing.T) {
+		setTestJournal(t)
+		_ = journal.Get().Put(&journal.Entry{
+			Ticker:         "BTC",
+			CreatedForDay:  config.TestNowDate,
+			ExecutedAmount: 0.001,
+			FiatSpent:      1,
+			IsCompleted:    true,
+		})
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+		got, ok := postOrderMap.m.Get("BTC")
+		assert.True(t, ok)
+		assert.InDelta(t, 1.002, got.(PostOrder).ActualFiatDeposit, 1e-9)
+		assert.InDelta(t, 1000, got.(PostOrder).AvgExecutionPrice, 1e-9)
+		assert.InDelta(t, 0.001, got.(PostOrder).ExecutedAmount, 1e-12)
+	})
+
+	t.Run("ok_live_order_filled", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		_ = journal.Get().Put(&journal.Entry{
+			Ticker:                            "BTC",
+			CreatedForDay:                     config.TestNowDate,
+			OrderID:                           "106817811",
+			OrderOpenThenCancelWindowCounter:  2,
+			OrderOpenQueryStatusWindowCounter: 30,
+			ExecutedAmount:                    0.00025,
+			FiatSpent:                         0.25,
+		})
+
+		responder := httpmock.NewStringResponder(http.StatusOK, `{
+			"tick_size": 1E-8,
+			"quote_increment": 0.01,
+			"min_order_size": "0.00001"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `{
+				"order_id": "106817811",
+				"avg_execution_price": "1000",
+				"is_live": false,
+				"is_cancelled": false,
+				"executed_amount": "0.00075"
+		}`)
+		httpmock.RegisterResponder(http.MethodPost, gemini.OrderStatusURI, responder)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		info := httpmock.GetCallCountInfo()
+		assert.Equal(t, 0, info[http.MethodPost+" "+gemini.NewOrderURI])
+		got, ok := postOrderMap.m.Get("BTC")
+		assert.True(t, ok)
+		assert.InDelta(t, 1.002, got.(PostOrder).ActualFiatDeposit, 1e-9)
+		assert.InDelta(t, 0.001, got.(PostOrder).ExecutedAmount, 1e-12)
+
+		entry, err := journal.Get().Get("BTC")
+		assert.NoError(t, err)
+		assert.True(t, entry.IsCompleted)
+		assert.Empty(t, entry.OrderID)
+	})
+
+	t.Run("ok_pending_order_filled", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		// Crashed after sending the order of the 2nd window, before it was journaled
+		_ = journal.Get().Put(&journal.Entry{
+			Ticker:                           "BTC",
+			CreatedForDay:                    config.TestNowDate,
+			ClientOrderID:                    "20241103_143000_btcsgd_w2_a0",
+			OrderOpenThenCancelWindowCounter: 2,
+			ExecutedAmount:                   0.00025,
+			FiatSpent:                        0.25,
+			OrderIDs:                         []string{"106817810"},
+		})
+
+		responder := httpmock.NewStringResponder(http.StatusOK, `{
+			"tick_size": 1E-8,
+			"quote_increment": 0.01,
+			"min_order_size": "0.00001"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `[{
+			"order_id": "106817811",
+			"client_order_id": "20241103_143000_btcsgd_w2_a0",
+			"symbol": "btcsgd",
+			"side": "buy",
+			"avg_execution_price": "1000",
+			"is_live": false,
+			"is_cancelled": false,
+			"executed_amount": "0.00075"
+		}]`)
+		httpmock.RegisterResponder(http.MethodPost, gemini.ActiveOrdersURI, responder)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		info := httpmock.GetCallCountInfo()
+		assert.Equal(t, 0, info[http.MethodPost+" "+gemini.NewOrderURI])
+		got, ok := postOrderMap.m.Get("BTC")
+		assert.True(t, ok)
+		assert.InDelta(t, 0.001, got.(PostOrder).ExecutedAmount, 1e-12)
+
+		entry, err := journal.Get().Get("BTC")
+		assert.NoError(t, err)
+		assert.True(t, entry.IsCompleted)
+		assert.Equal(t, []string{"106817810", "106817811"}, entry.OrderIDs)
+	})
+
+	t.Run("ok_stale_entry_ignored", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		_ = journal.Get().Put(&journal.Entry{
+			Ticker:         "BTC",
+			CreatedForDay:  config.TestNowDate.AddDate(0, 0, -1),
+			ExecutedAmount: 0.001,
+			FiatSpent:      1,
+			IsCompleted:    true,
+		})
+		responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		assert.Equal(t, 0, postOrderMap.m.Size())
+	})
+}
+
+func Test_cancelStaleJournaledOrders(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+	yesterday := config.TestNowDate.AddDate(0, 0, -1)
+
+	cancelledResponder := func(executedAmount string) httpmock.Responder {
+		return httpmock.NewStringResponder(http.StatusOK, fmt.Sprintf(`{
+			"order_id": "106817811",
+			"is_live": false,
+			"is_cancelled": true,
+			"avg_execution_price": "1000",
+			"executed_amount": "%s"
+		}`, executedAmount))
+	}
+
+	tests := []struct {
+		name        string
+		staleFills  OrderFills
+		setup       func(*mocks.MockGoogleSheetsRepository, *mocks.MockOrderRepository) func()
+		wantTickers []string
+	}{
+		{
+			name: "ok",
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, cancelledResponder("0"))
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantTickers: []string{"ETH"},
+		},
+		{
+			name:       "ok_fills_recorded",
+			staleFills: OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817810"}},
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, cancelledResponder("0.002"))
+				orderDB.EXPECT().GetOrdersCreatedForDay(yesterday).Return(nil, nil)
+				gs.EXPECT().GetSheetID().Return(int64(1234), nil)
+				gs.EXPECT().BatchUpdate(gomock.Any()).DoAndReturn(func(req *sheets.BatchUpdateSpreadsheetRequest) error {
+					// row of yesterday, one above today's
+					assert.Equal(t, int64(1), req.Requests[0].UpdateCells.Range.StartRowIndex)
+					assert.Equal(t, "02/11/2024", *req.Requests[0].UpdateCells.Rows[0].Values[0].UserEnteredValue.StringValue)
+					return nil
+				})
+				orderDB.EXPECT().BulkUpsert(gomock.Any()).DoAndReturn(func(orders []*db.Order) error {
+					assert.Len(t, orders, 1)
+					assert.Equal(t, "btcsgd", orders[0].Ticker)
+					assert.Equal(t, yesterday, orders[0].CreatedForDay)
+					assert.InDelta(t, 0.003, orders[0].CoinAmount, 1e-9)
+					assert.InDelta(t, 1000, orders[0].PricePerCoin, 1e-9)
+					return nil
+				})
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantTickers: []string{"ETH"},
+		},
+		{
+			name:       "ok_fills_already_recorded",
+			staleFills: OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817810"}},
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, cancelledResponder("0.002"))
+				orderDB.EXPECT().GetOrdersCreatedForDay(yesterday).Return([]*db.Order{{Ticker: "btcsgd", CreatedForDay: yesterday}}, nil)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantTickers: []string{"ETH"},
+		},
+		{
+			name:       "error_record_fills_kept",
+			staleFills: OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817810"}},
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, cancelledResponder("0.002"))
+				orderDB.EXPECT().GetOrdersCreatedForDay(yesterday).Return(nil, errors.New("error"))
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantTickers: []string{"BTC", "ETH"},
+		},
+		{
+			name: "error_cancel_order_kept",
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantTickers: []string{"BTC", "ETH"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockGS := mocks.NewMockGoogleSheetsRepository(ctrl)
+			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+			google_sheets.Set(mockGS)
+			db.Set(mockOrderDB)
+			setTestJournal(t)
+			// BTC is left behind by yesterday's run, ETH is in progress today
+			_ = journal.Get().Put(&journal.Entry{
+				Ticker:         "BTC",
+				CreatedForDay:  yesterday,
+				OrderID:        "106817811",
+				ExecutedAmount: tt.staleFills.ExecutedAmount,
+				FiatSpent:      tt.staleFills.FiatSpent,
+				OrderIDs:       tt.staleFills.OrderIDs,
+			})
+			_ = journal.Get().Put(&journal.Entry{Ticker: "ETH", CreatedForDay: config.TestNowDate, OrderID: "106817812"})
+
+			teardown := tt.setup(mockGS, mockOrderDB)
+			cancelStaleJournaledOrders(ctx)
+
+			entries, err := journal.Get().GetAll()
+			assert.NoError(t, err)
+			gotTickers := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				gotTickers = append(gotTickers, entry.Ticker)
+			}
+			assert.Equal(t, tt.wantTickers, gotTickers)
+			teardown()
+		})
+	}
+}
+
+func Test_clearJournal(t *testing.T) {
+	config.TestInit(nil, &config.TestNow)
+	setTestJournal(t)
+	_ = journal.Get().Put(&journal.Entry{Ticker: "BTC", CreatedForDay: config.TestNowDate.Add(-24 * time.Hour), OrderID: "106817811"})
+	_ = journal.Get().Put(&journal.Entry{Ticker: "ETH", CreatedForDay: config.TestNowDate, IsCompleted: true})
+	_ = journal.Get().Put(&journal.Entry{Ticker: "SOL", CreatedForDay: config.TestNowDate, OrderID: "106817812"})
+
+	clearJournal(map[string]bool{"BTC": true, "ETH": true})
+
+	entries, err := journal.Get().GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	tickers := []string{entries[0].Ticker, entries[1].Ticker}
+	assert.ElementsMatch(t, []string{"BTC", "SOL"}, tickers)
+}
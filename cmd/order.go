@@ -4,20 +4,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
-	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
+// ActualFiatDeposit includes Fee, unless the fee is charged in another currency
 type PostOrder struct {
 	ActualFiatDeposit float64
 	AvgExecutionPrice float64
 	ExecutedAmount    float64
+	Fee               float64
+	FeeCurrency       string
+	CarriedForward    float64 // unspent fiat amount of previous days added to today's order
+}
+
+// Accumulates executions across every order window of a ticker
+//
+// FiatSpent excludes trading fees. FeeAmount & FeeCurrency are only set once reconciled with the trade history,
+// the fee is estimated with the maker trading fee of the ticker's exchange otherwise
+type OrderFills struct {
+	ExecutedAmount float64
+	FiatSpent      float64
+	OrderIDs       []string // orders with executions
+	FeeAmount      float64
+	FeeCurrency    string
 }
 
 type PostOrderDetails struct {
@@ -26,12 +43,21 @@ type PostOrderDetails struct {
 }
 
 // Entry point for creating & fulfilling orders
-func handleOrder(ctx context.Context) *treemap.Map {
+//
+// tickers: tickers of this run, the others are skipped
+//
+// doneTickers: tickers that already have an order for today, to be skipped
+//
+// dailyFiatAmounts: fiat amount of every ticker to order for today, after the balance check
+//
+// Tickers not scheduled for today, or that would exceed a spend cap, are skipped
+func handleOrder(ctx context.Context, tickers, doneTickers map[string]bool, dailyFiatAmounts map[string]float64) *treemap.Map {
 	location := "cmd.handlerOrder"
 	c := config.Get()
 	postOrderDetails := &PostOrderDetails{
 		m: treemap.NewWithStringComparator(),
 	}
+	cappedTickers := getSpendCappedTickers(ctx, doneTickers, dailyFiatAmounts)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(len(c.CryptoTickers))
@@ -39,19 +65,35 @@ func handleOrder(ctx context.Context) *treemap.Map {
 		go func(ctx context.Context, wg *sync.WaitGroup, ticker string, postOrderMap *PostOrderDetails) {
 			defer wg.Done()
 			util.RecoverAndGraceFullyExit()
+			if !tickers[ticker] {
+				logger.Info(location, "Purchase for ticker '%s' is not scheduled for this run", ticker)
+				return
+			}
 			// Check switch
-			if c.OrderMetadata.DailyFiatAmount[ticker] <= 0 {
+			if dailyFiatAmounts[ticker] <= 0 {
 				logger.Warn(location, "Purchase for ticker '%s' is turned off", ticker)
 				return
 			}
+			if !c.IsDueToday(ticker) {
+				logger.Info(location, "Purchase for ticker '%s' is not scheduled for today", ticker)
+				return
+			}
+			if doneTickers[ticker] {
+				logger.Warn(location, "Purchase for ticker '%s' is already done for today", ticker)
+				return
+			}
+			if cappedTickers[ticker] {
+				logger.Warn(location, "Purchase for ticker '%s' is skipped for exceeding a spend cap", ticker)
+				return
+			}
 
-			// Sandbox environment guard check
-			if c.IsSandboxEnv {
+			// Simulated outside of production on an exchange without a sandbox
+			if c.IsSimulatedOrder(ticker) {
 				addToPostOrderDetails(postOrderDetails, ticker, nil)
 				return
 			}
 
-			handlerCexApiCalls(ctx, ticker, postOrderMap)
+			handlerCexApiCalls(ctx, ticker, dailyFiatAmounts[ticker], postOrderMap)
 		}(ctx, wg, ticker, postOrderDetails)
 	}
 	wg.Wait()
@@ -60,184 +102,525 @@ func handleOrder(ctx context.Context) *treemap.Map {
 }
 
 // Entry point for goroutine - Level 1
-func handlerCexApiCalls(ctx context.Context, ticker string, postOrderDetails *PostOrderDetails) {
+func handlerCexApiCalls(ctx context.Context, ticker string, dailyFiatAmount float64, postOrderDetails *PostOrderDetails) {
 	location := "handler.handlerCexApiCalls"
-	geminiClient := gemini.GetClient()
+	exchangeClient := exchange.Get(ticker)
+
+	fills := &OrderFills{}
+	orderOpenThenCancelWindowCounter := 0
+	// Of the client order ids of the orders placed by this run, see exchange.FormClientOrderID. The order being created
+	// when a previous run crashed is matched by the client order id it journaled instead
+	runStartedAt := config.GetTime().Now()
+
+	// Resume from the journal if a previous run crashed midway today
+	entry := getJournalEntry(ticker)
+	if entry != nil {
+		logger.Info(location, "'%s' Resuming from journal: %+v", ticker, entry)
+		fills.ExecutedAmount, fills.FiatSpent, fills.OrderIDs = entry.ExecutedAmount, entry.FiatSpent, entry.OrderIDs
+		fills.FeeAmount, fills.FeeCurrency = entry.FeeAmount, entry.FeeCurrency
+		orderOpenThenCancelWindowCounter = entry.OrderOpenThenCancelWindowCounter
+		if entry.IsCompleted {
+			addToPostOrderDetails(postOrderDetails, ticker, fills)
+			return
+		}
+	}
 
 	// Get Symbol details
-	results, err := gemini.RetryWrapper(ctx, fmt.Sprintf("GetQuoteIncrementAndTickSize - %v", ticker), geminiClient.GetQuoteIncrementAndTickSize, ticker)
+	quoteIncrement, tickSize, err := util.Retry2(ctx, fmt.Sprintf("GetQuoteIncrementAndTickSize - %v", ticker), func() (int, int, error) {
+		return exchangeClient.GetQuoteIncrementAndTickSize(ctx, ticker)
+	})
 	if err != nil {
 		logger.Error(location, "[handler.handlerCexApiCalls] Error getting symbol details", err)
 		return
 	}
-	quoteIncrement, tickSize := int(results[0].Int()), int(results[1].Int())
 
-	orderOpenThenCancelWindowCounter := 0
+	minOrderSize, err := util.Retry(ctx, fmt.Sprintf("GetMinOrderSize - %v", ticker), func() (float64, error) {
+		return exchangeClient.GetMinOrderSize(ctx, ticker)
+	})
+	if err != nil {
+		logger.Error(location, "'%s' Error getting min order size", err, ticker)
+		return
+	}
+
+	// Find the order that was being created when the previous run crashed, which may or may not be on the exchange
+	if entry != nil && entry.OrderID == "" && entry.ClientOrderID != "" {
+		liveOrder, isFilled, err := resumePendingOrder(ctx, ticker, entry.ClientOrderID, orderOpenThenCancelWindowCounter, fills)
+		if err != nil {
+			return
+		}
+		if isFilled {
+			completeOrderLoop(ctx, postOrderDetails, ticker, orderOpenThenCancelWindowCounter, fills)
+			return
+		}
+		if liveOrder != nil {
+			entry.OrderID, entry.OrderOpenQueryStatusWindowCounter = liveOrder.OrderID, 0
+		}
+	}
+
+	// Poll or cancel the order that was live when the previous run crashed, instead of placing a fresh one
+	if entry != nil && entry.OrderID != "" {
+		liveOrder := &exchange.Order{OrderID: entry.OrderID, ClientOrderID: entry.ClientOrderID}
+		isFilled, err := handlerCexApiCallsOrderQueryThenCancel(ctx, ticker, liveOrder, orderOpenThenCancelWindowCounter, entry.OrderOpenQueryStatusWindowCounter, fills)
+		if err == nil && isFilled {
+			completeOrderLoop(ctx, postOrderDetails, ticker, orderOpenThenCancelWindowCounter, fills)
+			return
+		}
+	}
+
 	for orderOpenThenCancelWindowCounter < config.OrderOpenThenCancelWindowCount {
+		// Stop placing orders once cancelled, e.g. on shutdown, keeping the fills so far
+		if ctx.Err() != nil {
+			logger.Warn(location, "'%s' Interrupted, stopping the order loop", ticker)
+			break
+		}
 		orderOpenThenCancelWindowCounter++
 
-		order, err := handlerCexApiCallsOrderOpenThenCancel(ctx, ticker, quoteIncrement, tickSize)
+		// Only size the order for what is left of the budget after partial fills of previous windows
+		remainingFiatAmount := dailyFiatAmount - fills.FiatSpent
+		if fills.isRemainingBelowMinOrderSize(remainingFiatAmount, minOrderSize) {
+			logger.Info(location, "'%s' Remaining fiat amount %v is below min order size", ticker, remainingFiatAmount)
+			completeOrderLoop(ctx, postOrderDetails, ticker, orderOpenThenCancelWindowCounter, fills)
+			return
+		}
+
+		// Switch to the ticker's fallback strategy after its unfilled windows
+		fallbackStrategy, fallbackWindow := getFallbackStrategy(ticker, orderOpenThenCancelWindowCounter)
+		if fallbackWindow == 1 {
+			logger.Warn(location, "'%s' Unfilled after %v windows, falling back to '%s'", ticker, fallbackStrategy.AfterWindows, fallbackStrategy.Name)
+		}
+		if fallbackStrategy != nil && fallbackStrategy.Name == config.FallbackStrategySkipAndCarry {
+			logger.Warn(location, "'%s' Skipping for the day, carrying %v forward", ticker, remainingFiatAmount)
+			break
+		}
+
+		var isFilled bool
+		var err error
+		if fallbackStrategy != nil && fallbackStrategy.Name == config.FallbackStrategyIocAtAsk {
+			isFilled, err = handlerCexApiCallsImmediateOrCancel(ctx, ticker, remainingFiatAmount, quoteIncrement, tickSize, runStartedAt, orderOpenThenCancelWindowCounter, fills)
+		} else {
+			isFilled, err = handlerCexApiCallsOrderOpenThenCancel(ctx, ticker, remainingFiatAmount, quoteIncrement, tickSize, runStartedAt, orderOpenThenCancelWindowCounter, fills)
+		}
 		if err != nil {
+			// Not recoverable by the next order window
+			if errors.Is(err, exchange.ErrInsufficientFunds) || errors.Is(err, exchange.ErrAuth) {
+				logger.Error(location, "'%s' Stopping the order loop", err, ticker)
+				break
+			}
 			continue
 		}
-		if order != nil {
-			addToPostOrderDetails(postOrderDetails, ticker, order)
+		if isFilled {
+			completeOrderLoop(ctx, postOrderDetails, ticker, orderOpenThenCancelWindowCounter, fills)
 			return
 		}
 	}
 
+	if fills.ExecutedAmount > 0 {
+		logger.Warn(location, "Ticker '%s' is only partially filled, spent %v of %v", ticker, fills.FiatSpent, dailyFiatAmount)
+		completeOrderLoop(ctx, postOrderDetails, ticker, orderOpenThenCancelWindowCounter, fills)
+		return
+	}
+
+	deleteJournalEntry(ticker)
 	logger.Warn(location, "Ticker '%s' failed to have a fulfilled order", ticker)
 }
 
 // Level 2
-func handlerCexApiCallsOrderOpenThenCancel(ctx context.Context, ticker string, quoteIncrement, tickSize int) (*gemini.Order, error) {
+//
+// Executions of every order created in this window, including partial fills of cancelled orders, are added to fills.
+//
+// bool: order is fulfilled
+func handlerCexApiCallsOrderOpenThenCancel(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement, tickSize int, runStartedAt time.Time, orderOpenThenCancelWindowCounter int, fills *OrderFills) (bool, error) {
 	location := "handler.handlerCexApiCallsOrderOpenThenCancel"
-	geminiClient := gemini.GetClient()
+	exchangeClient := exchange.Get(ticker)
 
-	// Get ticker best bid price
-	results, err := gemini.RetryWrapper(ctx, fmt.Sprintf("GetTickerBestBidPrice - %v", ticker), geminiClient.GetTickerBestBidPrice, ticker)
+	// Get order price from the ticker's pricing strategy
+	pricingStrategy := newPricingStrategy(exchangeClient, ticker, orderOpenThenCancelWindowCounter)
+	orderPrice, err := util.Retry(ctx, fmt.Sprintf("GetOrderPrice - %v", ticker), func() (float64, error) {
+		return pricingStrategy.GetOrderPrice(ctx, ticker, fiatAmount, quoteIncrement)
+	})
 	if err != nil {
-		logger.Error(location, "'%s' Error getting best bid price", err, ticker)
-		return nil, err
+		logger.Error(location, "'%s' Error getting order price", err, ticker)
+		return false, err
 	}
-	bestBid := results[0].Float()
 
-	// TODO: to monitor on situation on http error and no order created
 	// Create order - not retrying to prevent side effects
-	order, err := geminiClient.CreateOrder(ticker, bestBid, quoteIncrement, tickSize)
+	order, err := createOrMatchOrder(ctx, ticker, exchange.FormClientOrderID(ticker, runStartedAt, orderOpenThenCancelWindowCounter, 0), exchangeClient.CreateOrder, orderPrice, fiatAmount, quoteIncrement, tickSize, orderOpenThenCancelWindowCounter, fills)
 	if err != nil {
-		logger.Error(location, "'%s' Error creating order", err, ticker)
-		// Search order in case of order already exists
-		results, err = gemini.RetryWrapper(ctx, fmt.Sprintf("MatchActiveOrders - %v, ticker), geminiClient.MatchActiveOrders", ticker), geminiClient.MatchActiveOrders, ticker)
-		if err != nil {
-			logger.Error(location, "'%s' Error getting and matching active orders", err, ticker)
-			return nil, err
-		}
-		order = results[0].Interface().(*gemini.Order)
+		return false, err
 	}
 
-	// If order is cancelled, re-create order - not retrying to prevent side effects
+	// If order is cancelled, re-create order for the unfilled remainder - not retrying to prevent side effects
 	recreatingOrderCount := 0
-	for order.IsCancelled && recreatingOrderCount < gemini.MaxRetryCount {
+	for order.IsCancelled && recreatingOrderCount < config.OrderRecreateCount {
 		logger.Warn(location, "'%s' Order is cancelled, re-creating order", ticker)
 		recreatingOrderCount++
+		fiatAmount -= fills.add(order)
+		journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
 
-		order, err = geminiClient.CreateOrder(ticker, bestBid, quoteIncrement, tickSize)
+		clientOrderID := exchange.FormClientOrderID(ticker, runStartedAt, orderOpenThenCancelWindowCounter, recreatingOrderCount)
+		order, err = createOrMatchOrder(ctx, ticker, clientOrderID, exchangeClient.CreateOrder, orderPrice, fiatAmount, quoteIncrement, tickSize, orderOpenThenCancelWindowCounter, fills)
 		if err != nil {
-			logger.Error(location, "Error creating order", err)
-			// Search order in case of order already exists
-			results, err = gemini.RetryWrapper(ctx, fmt.Sprintf("MatchActiveOrders - %v, ticker), geminiClient.MatchActiveOrders", ticker), geminiClient.MatchActiveOrders, ticker)
-			if err != nil {
-				logger.Error(location, "'%s' Error getting and matching active orders", err, ticker)
-				return nil, err
-			}
-			order = results[0].Interface().(*gemini.Order)
+			return false, err
 		}
 	}
 
 	// If order is somehow still cancelled after retrying - return error
 	if order.IsCancelled {
+		fills.add(order)
+		journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
 		err := errors.New("order is cancelled")
 		logger.Error(location, "'%s' Order is still cancelled after retrying", err, ticker)
-		return nil, err
+		return false, err
 	}
 
 	// If order fulfilled - return order
 	if !order.IsLive {
 		logger.Info(location, "'%s' Order is fulfilled", ticker)
+		fills.add(order)
+		journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+		return true, nil
+	}
+
+	journalProgress(ticker, order, orderOpenThenCancelWindowCounter, 0, fills)
+	return handlerCexApiCallsOrderQueryThenCancel(ctx, ticker, order, orderOpenThenCancelWindowCounter, 0, fills)
+}
+
+// Level 2 - for FallbackStrategyIocAtAsk, the order is filled or cancelled immediately instead of being left open
+//
+// bool: order is fulfilled
+func handlerCexApiCallsImmediateOrCancel(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement, tickSize int, runStartedAt time.Time, orderOpenThenCancelWindowCounter int, fills *OrderFills) (bool, error) {
+	location := "handler.handlerCexApiCallsImmediateOrCancel"
+	exchangeClient := exchange.Get(ticker)
+
+	pricingStrategy := newPricingStrategy(exchangeClient, ticker, orderOpenThenCancelWindowCounter)
+	orderPrice, err := util.Retry(ctx, fmt.Sprintf("GetOrderPrice - %v", ticker), func() (float64, error) {
+		return pricingStrategy.GetOrderPrice(ctx, ticker, fiatAmount, quoteIncrement)
+	})
+	if err != nil {
+		logger.Error(location, "'%s' Error getting order price", err, ticker)
+		return false, err
+	}
+
+	// Create order - not retrying to prevent side effects
+	clientOrderID := exchange.FormClientOrderID(ticker, runStartedAt, orderOpenThenCancelWindowCounter, 0)
+	order, err := createOrMatchOrder(ctx, ticker, clientOrderID, exchangeClient.CreateImmediateOrCancelOrder, orderPrice, fiatAmount, quoteIncrement, tickSize, orderOpenThenCancelWindowCounter, fills)
+	if err != nil {
+		return false, err
+	}
+
+	// Should not happen, but leave it to the usual query then cancel
+	if order.IsLive {
+		journalProgress(ticker, order, orderOpenThenCancelWindowCounter, 0, fills)
+		return handlerCexApiCallsOrderQueryThenCancel(ctx, ticker, order, orderOpenThenCancelWindowCounter, 0, fills)
+	}
+
+	fills.add(order)
+	journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+	if order.IsCancelled {
+		logger.Warn(location, "'%s' Order is filled with amount %v, remainder is cancelled", ticker, order.ExecutedAmount)
+		logger.Info(location, "'%s' waiting for 1 min", ticker)
+		_ = util.Sleep(ctx, 1*time.Minute) // the order loop stops if interrupted
+		return false, nil
+	}
+
+	logger.Info(location, "'%s' Order is fulfilled", ticker)
+	return true, nil
+}
+
+// Fallback strategy of the ticker if it applies to the order window, and the order window's index (1-based) since switching
+// to it. nil and 0 otherwise
+func getFallbackStrategy(ticker string, orderOpenThenCancelWindowCounter int) (*config.FallbackStrategy, int) {
+	fallbackStrategy, ok := config.Get().OrderMetadata.FallbackStrategies[ticker]
+	if !ok || orderOpenThenCancelWindowCounter <= fallbackStrategy.AfterWindows {
+		return nil, 0
+	}
+	return &fallbackStrategy, orderOpenThenCancelWindowCounter - fallbackStrategy.AfterWindows
+}
+
+// Pricing strategy of the order window, which is that of the fallback strategy once switched to it
+func newPricingStrategy(exchangeClient exchange.Exchange, ticker string, orderOpenThenCancelWindowCounter int) exchange.PricingStrategy {
+	if _, fallbackWindow := getFallbackStrategy(ticker, orderOpenThenCancelWindowCounter); fallbackWindow > 0 {
+		if pricingStrategy := exchange.NewFallbackPricingStrategy(exchangeClient, ticker, fallbackWindow); pricingStrategy != nil {
+			return pricingStrategy
+		}
+	}
+	return exchange.NewPricingStrategy(exchangeClient, ticker)
+}
+
+// Creates the order, and if that fails ambiguously, i.e. is not rejected outright, searches for the order by its client order id in case it was created anyway
+//
+// createOrder is either CreateOrder or CreateImmediateOrCancelOrder of the ticker's exchange. It is not abandoned when
+// interrupted, so that the order created is known & journaled
+//
+// The client order id is journaled before the order is sent, so that a run crashing before the order is journaled
+// still finds it on resume, see resumePendingOrder
+func createOrMatchOrder(ctx context.Context, ticker, clientOrderID string, createOrder func(context.Context, string, string, float64, float64, int, int) (*exchange.Order, error), orderPrice, fiatAmount float64, quoteIncrement, tickSize, orderOpenThenCancelWindowCounter int, fills *OrderFills) (*exchange.Order, error) {
+	location := "handler.createOrMatchOrder"
+	exchangeClient := exchange.Get(ticker)
+
+	journalPendingOrder(ticker, clientOrderID, orderOpenThenCancelWindowCounter, fills)
+	// TODO: to monitor on situation on http error and no order created
+	order, err := createOrder(context.WithoutCancel(ctx), ticker, clientOrderID, orderPrice, fiatAmount, quoteIncrement, tickSize)
+	if err == nil {
 		return order, nil
 	}
+	logger.Error(location, "'%s' Error creating order '%s'", err, ticker, clientOrderID)
+	if exchange.IsRejected(err) {
+		journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+		return nil, err
+	}
+
+	order, err = util.Retry(ctx, fmt.Sprintf("MatchActiveOrders - %v", ticker), func() (*exchange.Order, error) {
+		return exchangeClient.MatchActiveOrders(ctx, ticker, clientOrderID)
+	})
+	if err != nil {
+		logger.Error(location, "'%s' Error matching order '%s'", err, ticker, clientOrderID)
+		return nil, err
+	}
+	return order, nil
+}
+
+// Level 2 - looks up the journaled order that was being created when the previous run crashed. An order that is no
+// longer live has its executions added to fills
+//
+// *exchange.Order: the order if it is still live, nil otherwise. bool: order is fulfilled. An error is only returned
+// if interrupted, an order that cannot be found is taken to have never been created
+func resumePendingOrder(ctx context.Context, ticker, clientOrderID string, orderOpenThenCancelWindowCounter int, fills *OrderFills) (*exchange.Order, bool, error) {
+	location := "handler.resumePendingOrder"
+	exchangeClient := exchange.Get(ticker)
+
+	order, err := util.Retry(ctx, fmt.Sprintf("MatchActiveOrders - %v", ticker), func() (*exchange.Order, error) {
+		return exchangeClient.MatchActiveOrders(ctx, ticker, clientOrderID)
+	})
+	if ctx.Err() != nil {
+		logger.Warn(location, "'%s' Interrupted, keeping order '%s' journaled", ticker, clientOrderID)
+		return nil, false, ctx.Err()
+	}
+	if err != nil {
+		logger.Warn(location, "'%s' Order '%s' is not found, it was not created before the previous run crashed", ticker, clientOrderID)
+		journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+		return nil, false, nil
+	}
+	if order.IsLive {
+		logger.Info(location, "'%s' Order '%s' is live, resuming it", ticker, clientOrderID)
+		journalProgress(ticker, order, orderOpenThenCancelWindowCounter, 0, fills)
+		return order, false, nil
+	}
+
+	fills.add(order)
+	journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+	return nil, !order.IsCancelled, nil
+}
+
+// Level 2 - waits for a live order to be fulfilled, and cancels it otherwise
+//
+// Also the entry point for resuming a journaled order, from its journaled orderOpenQueryStatusWindowCounter.
+//
+// bool: order is fulfilled
+func handlerCexApiCallsOrderQueryThenCancel(ctx context.Context, ticker string, order *exchange.Order, orderOpenThenCancelWindowCounter, orderOpenQueryStatusWindowCounter int, fills *OrderFills) (bool, error) {
+	location := "handler.handlerCexApiCallsOrderQueryThenCancel"
+	exchangeClient := exchange.Get(ticker)
+
+	// Updates pushed by the exchange end the wait early, the order is only polled while they may have been missed
+	events, unsubscribe := subscribeOrderEvents(exchangeClient, order.OrderID)
+	defer unsubscribe()
+	var syncedAt time.Time
+
+	// The order is cancelled early to be repriced in the next window if the streamed best bid moves away from it
+	bids, unsubscribeBids := subscribeBestBid(exchangeClient, ticker)
+	defer unsubscribeBids()
+	bidMove := &bidMoveWatcher{ratio: config.Get().OrderMetadata.RepriceBidMoveRatio}
 
 	// Check if order is fulfilled - query every minute for an hour
 	// Make sure that order is not cancelled - if cancelled, return
-	orderOpenQueryStatusWindowCounter := 0
 	for orderOpenQueryStatusWindowCounter < config.OrderOpenQueryStatusWindowCount {
 		orderOpenQueryStatusWindowCounter++
-		if !util.IsTestFlow(ctx) {
-			logger.Info(location, "'%s' waiting for 1 min", ticker)
-			time.Sleep(1 * time.Minute)
+		logger.Info(location, "'%s' waiting for 1 min", ticker)
+		event, isBidMoved, err := waitForOrderWindow(ctx, events, bids, bidMove, 1*time.Minute)
+		if err != nil {
+			logger.Warn(location, "'%s' Interrupted, cancelling order", ticker)
+			break
+		}
+		if isBidMoved {
+			logger.Warn(location, "'%s' Best bid moved by more than %v since the order was placed, cancelling order to reprice", ticker, bidMove.ratio)
+			break
 		}
 
-		order, isCancelled, err := handlerCexApiCallsOrderOpenQueryStatus(ctx, ticker, order)
-		if err != nil {
-			break // to cancel order
+		var queryOrder *exchange.Order
+		var isCancelled bool
+		if event != nil {
+			queryOrder, isCancelled = classifyOrderStatus(ticker, event)
+		} else if !isOrderEventsSynced(exchangeClient, syncedAt) {
+			queriedAt := time.Now()
+			queryOrder, isCancelled, err = handlerCexApiCallsOrderOpenQueryStatus(ctx, ticker, order)
+			if err != nil {
+				break // to cancel order
+			}
+			syncedAt = queriedAt
 		}
 		if isCancelled {
-			return nil, errors.New("order is cancelled")
+			fills.add(queryOrder)
+			journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+			return false, errors.New("order is cancelled")
 		}
-		if order != nil {
-			return order, nil
+		if queryOrder != nil {
+			fills.add(queryOrder)
+			journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+			return true, nil
 		}
+		journalProgress(ticker, order, orderOpenThenCancelWindowCounter, orderOpenQueryStatusWindowCounter, fills)
 	}
 
 	// Cancel order here, retry creating new order in the next iteration of the loop
-	results, err = gemini.RetryWrapper(ctx, fmt.Sprintf("CancelOrder - %v", ticker), geminiClient.CancelOrder, order.OrderID)
-	if err != nil || !results[0].Interface().(*gemini.Order).IsCancelled {
-		logger.Error(location, "'%s' Failed to cancel order: %+v", err, ticker)
-		return nil, err
+	// Also when interrupted, so that no order is left live
+	cancelCtx := context.WithoutCancel(ctx)
+	cancelledOrder, err := util.Retry(cancelCtx, fmt.Sprintf("CancelOrder - %v", ticker), func() (*exchange.Order, error) {
+		return exchangeClient.CancelOrder(cancelCtx, order.OrderID)
+	})
+	if err != nil {
+		logger.Error(location, "'%s' Failed to cancel order", err, ticker)
+		return false, err
+	}
+
+	// Keep whatever was filled before the cancellation, which may be all of it if the order filled since the last query
+	fiatSpent := fills.add(cancelledOrder)
+	journalProgress(ticker, nil, orderOpenThenCancelWindowCounter, 0, fills)
+	if !cancelledOrder.IsCancelled {
+		if cancelledOrder.RemainingAmount == 0 {
+			logger.Info(location, "'%s' Order is fulfilled before it could be cancelled", ticker)
+			return true, nil
+		}
+		logger.Error(location, "'%s' Failed to cancel order: %+v", errors.New("order is not cancelled"), ticker, cancelledOrder)
+		return false, nil
+	}
+	if fiatSpent > 0 {
+		logger.Warn(location, "'%s' Order is partially filled with amount %v and successfully cancelled", ticker, cancelledOrder.ExecutedAmount)
+		return false, nil
 	}
 
 	// Order is not filled and successfully cancelled
 	logger.Warn(location, "'%s' Order is not filled and successfully cancelled", ticker)
-	return nil, nil
+	return false, nil
 }
 
 // Level 3
 //
+// *exchange.Order: queried order, if order is fulfilled or cancelled
+//
 // bool: order is cancelled
-func handlerCexApiCallsOrderOpenQueryStatus(ctx context.Context, ticker string, order *gemini.Order) (*gemini.Order, bool, error) {
+func handlerCexApiCallsOrderOpenQueryStatus(ctx context.Context, ticker string, order *exchange.Order) (*exchange.Order, bool, error) {
 	location := "handler.handlerCexApiCallsOrderOpenQueryStatus"
-	geminiClient := gemini.GetClient()
+	exchangeClient := exchange.Get(ticker)
 
-	results, err := gemini.RetryWrapper(ctx, fmt.Sprintf("GetOrderStatus - %v", ticker), geminiClient.GetOrderStatus, order.OrderID)
+	queryOrder, err := util.Retry(ctx, fmt.Sprintf("GetOrderStatus - %v", ticker), func() (*exchange.Order, error) {
+		return exchangeClient.GetOrderStatus(ctx, order.OrderID)
+	})
 	if err != nil {
 		logger.Error(location, "'%s' Get order status failed", err, ticker)
 		return nil, false, err
 	}
-	queryOrder := results[0].Interface().(*gemini.Order)
+	queryOrder, isCancelled := classifyOrderStatus(ticker, queryOrder)
+	return queryOrder, isCancelled, nil
+}
 
-	// If order is cancelled - return empty
+// Same returns as handlerCexApiCallsOrderOpenQueryStatus, for an order either queried or pushed by the exchange
+func classifyOrderStatus(ticker string, queryOrder *exchange.Order) (*exchange.Order, bool) {
+	location := "handler.classifyOrderStatus"
+
+	// If order is cancelled - return order for its partial fills
 	if queryOrder.IsCancelled {
 		logger.Warn(location, "'%s' Order is cancelled", ticker)
-		return nil, true, nil
+		return queryOrder, true
 	}
 
 	// If order fulfilled - return order
 	if !queryOrder.IsLive {
 		logger.Info(location, "'%s' Order is fulfilled", ticker)
-		return queryOrder, false, nil
+		return queryOrder, false
 	}
 
 	// Order is not fulfilled - to continue querying
 	logger.Warn(location, "'%s' Order is not fulfilled yet", ticker)
-	return nil, false, nil
+	return nil, false
+}
+
+// Adds the executions of an order, returns the fiat spent by the order. An order already added, e.g. before the run was
+// resumed from the journal, is skipped so that its executions are not counted twice
+func (f *OrderFills) add(order *exchange.Order) float64 {
+	if order == nil || order.ExecutedAmount <= 0 || slices.Contains(f.OrderIDs, order.OrderID) {
+		return 0
+	}
+	fiatSpent := order.AvgExecutionPrice * order.ExecutedAmount
+	f.ExecutedAmount += order.ExecutedAmount
+	f.FiatSpent += fiatSpent
+	f.OrderIDs = append(f.OrderIDs, order.OrderID)
+	return fiatSpent
+}
+
+// Volume-weighted average price across all fills
+func (f *OrderFills) avgExecutionPrice() float64 {
+	if f.ExecutedAmount <= 0 {
+		return 0
+	}
+	return f.FiatSpent / f.ExecutedAmount
+}
+
+// Estimates the remaining order amount with the average price so far, as the next window's price is not known yet
+func (f *OrderFills) isRemainingBelowMinOrderSize(remainingFiatAmount, minOrderSize float64) bool {
+	if f.ExecutedAmount <= 0 {
+		return false
+	}
+	return remainingFiatAmount <= 0 || remainingFiatAmount/f.avgExecutionPrice() < minOrderSize
 }
 
-func addToPostOrderDetails(postOrderDetails *PostOrderDetails, ticker string, order *gemini.Order) {
+// Reconciles the fills with the trade history, then journals the ticker as completed before reporting its fills,
+// so that a crash before the db write does not lose them
+func completeOrderLoop(ctx context.Context, postOrderDetails *PostOrderDetails, ticker string, orderOpenThenCancelWindowCounter int, fills *OrderFills) {
+	reconcileFills(ctx, ticker, fills)
+	journalCompleted(ticker, orderOpenThenCancelWindowCounter, fills)
+	addToPostOrderDetails(postOrderDetails, ticker, fills)
+}
+
+func addToPostOrderDetails(postOrderDetails *PostOrderDetails, ticker string, fills *OrderFills) {
 	postOrderDetails.mu.Lock()
-	if order != nil {
+	if fills != nil {
 		// Prod
-		postOrderDetails.m.Put(ticker, formPostOrderData(order))
+		postOrderDetails.m.Put(ticker, formPostOrderData(ticker, fills))
 	} else {
-		// Sandbox
+		// Simulated
 		c := config.Get()
-		postOrderDetails.m.Put(ticker, sandboxPostOrderData(c.OrderMetadata.DailyFiatAmount[ticker]))
+		postOrderDetails.m.Put(ticker, simulatedPostOrderData(ticker, c.OrderMetadata.DailyFiatAmount[ticker]))
 	}
 	postOrderDetails.mu.Unlock()
 }
 
-func formPostOrderData(order *gemini.Order) PostOrder {
+func formPostOrderData(ticker string, fills *OrderFills) PostOrder {
+	exchangeClient := exchange.Get(ticker)
+	quoteCurrency := config.Get().GetQuoteCurrency(ticker)
+	fee, feeCurrency := fills.FiatSpent*exchangeClient.GetMakerTradingFee(), quoteCurrency
+	if fills.FeeCurrency != "" {
+		fee, feeCurrency = fills.FeeAmount, fills.FeeCurrency
+	}
+	actualFiatDeposit := fills.FiatSpent
+	if feeCurrency == quoteCurrency {
+		actualFiatDeposit += fee
+	}
 	return PostOrder{
-		ActualFiatDeposit: order.AvgExecutionPrice * order.ExecutedAmount * (1 + gemini.MakerTradingFee),
-		AvgExecutionPrice: order.AvgExecutionPrice,
-		ExecutedAmount:    order.ExecutedAmount,
+		ActualFiatDeposit: actualFiatDeposit,
+		AvgExecutionPrice: fills.avgExecutionPrice(),
+		ExecutedAmount:    fills.ExecutedAmount,
+		Fee:               fee,
+		FeeCurrency:       feeCurrency,
 	}
 }
 
-func sandboxPostOrderData(dailyFiatAmount float64) PostOrder {
+func simulatedPostOrderData(ticker string, dailyFiatAmount float64) PostOrder {
+	exchangeClient := exchange.Get(ticker)
 	return PostOrder{
-		ActualFiatDeposit: dailyFiatAmount * (1 + gemini.MakerTradingFee),
+		ActualFiatDeposit: dailyFiatAmount * (1 + exchangeClient.GetMakerTradingFee()),
 		AvgExecutionPrice: 1000,
 		ExecutedAmount:    1,
+		Fee:               dailyFiatAmount * exchangeClient.GetMakerTradingFee(),
+		FeeCurrency:       config.Get().GetQuoteCurrency(ticker),
 	}
 }
@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/kraken"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,8 +28,12 @@ func Test_handleOrder(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 	config.TestInit(nil, nil)
 	gemini.MustInitClient()
+	kraken.MustInitClient()
+	// Orders of tickers bought on Kraken are simulated in the sandbox environment
+	simulatedExchanges := map[string]string{"BTC": config.ExchangeKraken, "ETH": config.ExchangeKraken}
 
 	t.Run("ok_prod", func(t *testing.T) {
+		setTestJournal(t)
 		config.TestInit(&config.ConfigUpdateable{
 			IsSandboxEnv: util.PtrOf(false),
 		}, nil)
@@ -56,7 +68,7 @@ func Test_handleOrder(t *testing.T) {
 		}`)
 		httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
 
-		postOrderDetails := handleOrder(ctx)
+		postOrderDetails := handleOrder(ctx, config.Get().CryptoTickers, nil, config.Get().OrderMetadata.DailyFiatAmount)
 		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
 
 		postOrders := treemap.NewWithStringComparator()
@@ -64,49 +76,135 @@ func Test_handleOrder(t *testing.T) {
 			ActualFiatDeposit: 13675.163971708602,
 			AvgExecutionPrice: 3632.8508430064553,
 			ExecutedAmount:    3.7567928949,
+			Fee:               27.295736470476253,
+			FeeCurrency:       "SGD",
 		})
 		postOrders.Put("ETH", PostOrder{
 			ActualFiatDeposit: 13675.163971708602,
 			AvgExecutionPrice: 3632.8508430064553,
 			ExecutedAmount:    3.7567928949,
+			Fee:               27.295736470476253,
+			FeeCurrency:       "SGD",
 		})
 		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
 	})
 
-	t.Run("ok_sandbox", func(t *testing.T) {
-		config.TestInit(nil, nil)
-		postOrderDetails := handleOrder(ctx)
+	t.Run("ok_sandbox_simulated", func(t *testing.T) {
+		setTestJournal(t)
+		config.TestInit(&config.ConfigUpdateable{
+			Exchanges: simulatedExchanges,
+		}, nil)
+		postOrderDetails := handleOrder(ctx, config.Get().CryptoTickers, nil, config.Get().OrderMetadata.DailyFiatAmount)
 		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
 
 		postOrders := treemap.NewWithStringComparator()
 		postOrders.Put("BTC", PostOrder{
-			ActualFiatDeposit: 1.002,
+			ActualFiatDeposit: 1.0025,
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
+			Fee:               0.0025,
+			FeeCurrency:       "SGD",
 		})
 		postOrders.Put("ETH", PostOrder{
-			ActualFiatDeposit: 2.004,
+			ActualFiatDeposit: 2.005,
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
+			Fee:               0.005,
+			FeeCurrency:       "SGD",
 		})
 		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
 	})
 
-	t.Run("ok_sandbox_ignore_ETH", func(t *testing.T) {
+	t.Run("ok_sandbox_simulated_ignore_ETH", func(t *testing.T) {
+		setTestJournal(t)
 		config.TestInit(&config.ConfigUpdateable{
+			Exchanges: simulatedExchanges,
 			DailyFiatAmount: map[string]float64{
 				"BTC": 1,
 				"ETH": 0,
 			},
 		}, nil)
-		postOrderDetails := handleOrder(ctx)
+		postOrderDetails := handleOrder(ctx, config.Get().CryptoTickers, nil, config.Get().OrderMetadata.DailyFiatAmount)
+		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{
+			ActualFiatDeposit: 1.0025,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1,
+			Fee:               0.0025,
+			FeeCurrency:       "SGD",
+		})
+		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
+	})
+
+	t.Run("ok_sandbox_simulated_ETH_not_scheduled", func(t *testing.T) {
+		setTestJournal(t)
+		config.TestInit(&config.ConfigUpdateable{
+			Exchanges: simulatedExchanges,
+			Schedules: map[string]config.Schedule{
+				"ETH": {Name: config.ScheduleFirstOfMonth},
+			},
+		}, &config.TestNow)
+		postOrderDetails := handleOrder(ctx, config.Get().CryptoTickers, nil, config.Get().OrderMetadata.DailyFiatAmount)
+		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{
+			ActualFiatDeposit: 1.0025,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1,
+			Fee:               0.0025,
+			FeeCurrency:       "SGD",
+		})
+		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
+	})
+
+	t.Run("ok_sandbox_simulated_ETH_not_in_run", func(t *testing.T) {
+		setTestJournal(t)
+		config.TestInit(&config.ConfigUpdateable{
+			Exchanges: simulatedExchanges,
+		}, &config.TestNow)
+		// Tickers not part of the run are also done, see getTickersDoneForToday
+		postOrderDetails := handleOrder(ctx, map[string]bool{"BTC": true}, map[string]bool{"ETH": true}, config.Get().OrderMetadata.DailyFiatAmount)
+		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
+
+		postOrders := treemap.NewWithStringComparator()
+		postOrders.Put("BTC", PostOrder{
+			ActualFiatDeposit: 1.0025,
+			AvgExecutionPrice: 1000,
+			ExecutedAmount:    1,
+			Fee:               0.0025,
+			FeeCurrency:       "SGD",
+		})
+		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
+	})
+
+	t.Run("ok_sandbox_simulated_spend_capped_ETH", func(t *testing.T) {
+		setTestJournal(t)
+		config.TestInit(&config.ConfigUpdateable{
+			Exchanges: simulatedExchanges,
+			SpendCaps: &config.SpendCaps{
+				MonthlyFiatCaps: map[string]float64{"ETH": 10},
+			},
+		}, &config.TestNow)
+		ctrl := gomock.NewController(t)
+		mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+		db.Set(mockOrderDB)
+		mockOrderDB.EXPECT().GetOrdersCreatedSince(config.GetTime().GetMonthStartDate()).Return([]*db.Order{
+			{Ticker: "ethsgd", CreatedForDay: config.TestNowDate.AddDate(0, 0, -1), FiatDeposit: 9},
+		}, nil)
+
+		postOrderDetails := handleOrder(ctx, config.Get().CryptoTickers, nil, config.Get().OrderMetadata.DailyFiatAmount)
 		time.Sleep(time.Duration(len(config.Get().CryptoTickers)) * time.Second) // wait for goroutines to finish
 
 		postOrders := treemap.NewWithStringComparator()
 		postOrders.Put("BTC", PostOrder{
-			ActualFiatDeposit: 1.002,
+			ActualFiatDeposit: 1.0025,
 			AvgExecutionPrice: 1000,
 			ExecutedAmount:    1,
+			Fee:               0.0025,
+			FeeCurrency:       "SGD",
 		})
 		assert.Equal(t, util.SafeJsonDump(postOrders), util.SafeJsonDump(postOrderDetails))
 	})
@@ -120,6 +218,7 @@ func Test_handlerCexApiCalls(t *testing.T) {
 	gemini.MustInitClient()
 
 	t.Run("ok", func(t *testing.T) {
+		setTestJournal(t)
 		defer httpmock.Reset()
 		responder := httpmock.NewStringResponder(http.StatusOK, `{
 			"tick_size": 1E-8,
@@ -150,14 +249,17 @@ func Test_handlerCexApiCalls(t *testing.T) {
 			ActualFiatDeposit: 13675.163971708602,
 			AvgExecutionPrice: 3632.8508430064553,
 			ExecutedAmount:    3.7567928949,
+			Fee:               27.295736470476253,
+			FeeCurrency:       "SGD",
 		})
-		handlerCexApiCalls(ctx, "BTC", postOrderMap)
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
 		assert.Equal(t, util.SafeJsonDump(postOrderMap), util.SafeJsonDump(&PostOrderDetails{
 			m: postOrders,
 		}))
 	})
 
 	t.Run("error_GetQuoteIncrementAndTickSize", func(t *testing.T) {
+		setTestJournal(t)
 		defer httpmock.Reset()
 		responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
 		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
@@ -165,13 +267,14 @@ func Test_handlerCexApiCalls(t *testing.T) {
 		postOrderMap := &PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}
-		handlerCexApiCalls(ctx, "BTC", postOrderMap)
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
 		assert.Equal(t, util.SafeJsonDump(postOrderMap), util.SafeJsonDump(&PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}))
 	})
 
 	t.Run("error_handlerCexApiCallsOrderOpenThenCancel", func(t *testing.T) {
+		setTestJournal(t)
 		defer httpmock.Reset()
 		responder := httpmock.NewStringResponder(http.StatusOK, `{
 			"tick_size": 1E-8,
@@ -185,13 +288,14 @@ func Test_handlerCexApiCalls(t *testing.T) {
 		postOrderMap := &PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}
-		handlerCexApiCalls(ctx, "BTC", postOrderMap)
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
 		assert.Equal(t, util.SafeJsonDump(postOrderMap), util.SafeJsonDump(&PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}))
 	})
 
 	t.Run("error_no_fulfilled_order", func(t *testing.T) {
+		setTestJournal(t)
 		defer httpmock.Reset()
 		responder := httpmock.NewStringResponder(http.StatusOK, `{
 			"tick_size": 1E-8,
@@ -222,7 +326,7 @@ func Test_handlerCexApiCalls(t *testing.T) {
 				"avg_execution_price": "3632.8508430064554",
 				"is_live": true, 
 				"is_cancelled": true, 
-				"executed_amount": "3.7567928949",
+				"executed_amount": "0",
 				"client_order_id": "20190110-4738721"
 		}`)
 		httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, responder)
@@ -230,13 +334,232 @@ func Test_handlerCexApiCalls(t *testing.T) {
 		postOrderMap := &PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}
-		handlerCexApiCalls(ctx, "BTC", postOrderMap)
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+		assert.Equal(t, util.SafeJsonDump(postOrderMap), util.SafeJsonDump(&PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}))
+	})
+	t.Run("ok_partially_filled_across_windows", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		responder := httpmock.NewStringResponder(http.StatusOK, `{
+			"tick_size": 1E-8,
+			"quote_increment": 0.01,
+			"min_order_size": "0.00001"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `{
+			"bid": "1000.00"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerV2URI, "btcsgd"), responder)
+
+		// Every window places a new order
+		orderCount := 0
+		httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, func(req *http.Request) (*http.Response, error) {
+			orderCount++
+			return httpmock.NewStringResponse(http.StatusOK, fmt.Sprintf(`{
+				"order_id": "10681781%d",
+				"avg_execution_price": "0",
+				"is_live": true,
+				"is_cancelled": false,
+				"executed_amount": "0",
+				"client_order_id": "20190110-4738721"
+			}`, orderCount)), nil
+		})
+
+		responder = httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+		httpmock.RegisterResponder(http.MethodPost, gemini.OrderStatusURI, responder)
+
+		// Every window fills 0.25 of the daily fiat amount of 1 before it is cancelled
+		httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewStringResponse(http.StatusOK, fmt.Sprintf(`{
+				"order_id": "10681781%d",
+				"avg_execution_price": "1000",
+				"is_live": false,
+				"is_cancelled": true,
+				"executed_amount": "0.00025",
+				"client_order_id": "20190110-4738721"
+			}`, orderCount)), nil
+		})
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		info := httpmock.GetCallCountInfo()
+		assert.Equal(t, 4, info[http.MethodPost+" "+gemini.NewOrderURI])
+		got, ok := postOrderMap.m.Get("BTC")
+		assert.True(t, ok)
+		assert.InDelta(t, 1.002, got.(PostOrder).ActualFiatDeposit, 1e-9)
+		assert.InDelta(t, 1000, got.(PostOrder).AvgExecutionPrice, 1e-9)
+		assert.InDelta(t, 0.001, got.(PostOrder).ExecutedAmount, 1e-12)
+	})
+
+	t.Run("error_insufficient_funds", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		responder := httpmock.NewStringResponder(http.StatusOK, `{
+			"tick_size": 1E-8,
+			"quote_increment": 0.01
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `{
+			"bid": "9345.70"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerV2URI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusNotAcceptable, `{
+			"result": "error",
+			"reason": "InsufficientFunds",
+			"message": "Failed to place buy order on symbol 'BTCSGD' for price $9,336.35 and quantity 0.00010711 due to insufficient funds"
+		}`)
+		httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		// Neither matched nor re-created in the next order window
+		info := httpmock.GetCallCountInfo()
+		assert.Equal(t, 1, info[http.MethodPost+" "+gemini.NewOrderURI])
+		assert.Equal(t, 0, info[http.MethodPost+" "+gemini.ActiveOrdersURI])
 		assert.Equal(t, util.SafeJsonDump(postOrderMap), util.SafeJsonDump(&PostOrderDetails{
 			m: treemap.NewWithStringComparator(),
 		}))
 	})
 }
 
+func Test_handlerCexApiCalls_fallback(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+	gemini.MustInitClient()
+
+	// Orders of non-fallback windows are never filled, prices of every created order are recorded
+	setup := func(fillImmediateOrCancel bool) *[]map[string]any {
+		responder := httpmock.NewStringResponder(http.StatusOK, `{
+			"tick_size": 1E-8,
+			"quote_increment": 0.01,
+			"min_order_size": "0.00001"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerDetailsURI, "btcsgd"), responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `{
+			"bid": "1000.00",
+			"ask": "1005.00"
+		}`)
+		httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerV2URI, "btcsgd"), responder)
+
+		createdOrders := &[]map[string]any{}
+		httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, func(req *http.Request) (*http.Response, error) {
+			payload, _ := base64.StdEncoding.DecodeString(req.Header.Get("X-GEMINI-PAYLOAD"))
+			params := map[string]any{}
+			_ = json.Unmarshal(payload, &params)
+			*createdOrders = append(*createdOrders, params)
+			if params["options"] != nil && fillImmediateOrCancel {
+				return httpmock.NewStringResponse(http.StatusOK, `{
+					"order_id": "106817812",
+					"avg_execution_price": "1005",
+					"is_live": false,
+					"is_cancelled": false,
+					"executed_amount": "0.000995"
+				}`), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, `{
+				"order_id": "106817811",
+				"avg_execution_price": "0",
+				"is_live": true,
+				"is_cancelled": false,
+				"executed_amount": "0"
+			}`), nil
+		})
+
+		responder = httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+		httpmock.RegisterResponder(http.MethodPost, gemini.OrderStatusURI, responder)
+
+		responder = httpmock.NewStringResponder(http.StatusOK, `{
+			"order_id": "106817811",
+			"avg_execution_price": "0",
+			"is_live": false,
+			"is_cancelled": true,
+			"executed_amount": "0"
+		}`)
+		httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, responder)
+		return createdOrders
+	}
+
+	t.Run("ok_ioc_at_ask", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		config.TestInit(&config.ConfigUpdateable{
+			FallbackStrategies: map[string]config.FallbackStrategy{
+				"BTC": {Name: config.FallbackStrategyIocAtAsk, AfterWindows: 1, MaxPremium: 0.01},
+			},
+		}, nil)
+		createdOrders := setup(true)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		assert.Len(t, *createdOrders, 2)
+		assert.Nil(t, (*createdOrders)[0]["options"])
+		assert.Equal(t, []any{gemini.OrderOptionImmediateOrCancel}, (*createdOrders)[1]["options"])
+		assert.Equal(t, "1005.00", (*createdOrders)[1]["price"])
+		got, ok := postOrderMap.m.Get("BTC")
+		assert.True(t, ok)
+		assert.InDelta(t, 0.000995, got.(PostOrder).ExecutedAmount, 1e-12)
+		assert.InDelta(t, 1005, got.(PostOrder).AvgExecutionPrice, 1e-9)
+	})
+
+	t.Run("ok_aggressive_limit", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		config.TestInit(&config.ConfigUpdateable{
+			FallbackStrategies: map[string]config.FallbackStrategy{
+				"BTC": {Name: config.FallbackStrategyAggressiveLimit, AfterWindows: 1, Step: 0.001, MaxPremium: 0.002},
+			},
+		}, nil)
+		createdOrders := setup(false)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		assert.Len(t, *createdOrders, config.OrderOpenThenCancelWindowCount)
+		for i, wantPrice := range []string{"999.00", "1001.00", "1002.00", "1002.00"} {
+			assert.Equal(t, wantPrice, (*createdOrders)[i]["price"])
+		}
+		assert.Equal(t, 0, postOrderMap.m.Size())
+	})
+
+	t.Run("ok_skip_and_carry", func(t *testing.T) {
+		setTestJournal(t)
+		defer httpmock.Reset()
+		config.TestInit(&config.ConfigUpdateable{
+			FallbackStrategies: map[string]config.FallbackStrategy{
+				"BTC": {Name: config.FallbackStrategySkipAndCarry, AfterWindows: 2},
+			},
+		}, nil)
+		createdOrders := setup(false)
+
+		postOrderMap := &PostOrderDetails{
+			m: treemap.NewWithStringComparator(),
+		}
+		handlerCexApiCalls(ctx, "BTC", 1, postOrderMap)
+
+		assert.Len(t, *createdOrders, 2)
+		assert.Equal(t, 0, postOrderMap.m.Size())
+	})
+}
+
 func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 	ctx := util.TestContext()
 	httpmock.Activate()
@@ -244,17 +567,21 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 	config.TestInit(nil, nil)
 	gemini.MustInitClient()
 
+	avgExecutionPrice, executedAmount := 3632.8508430064554, 3.7567928949
+
 	type args struct {
 		ticker         string
+		fiatAmount     float64
 		quoteIncrement int
 		tickSize       int
 	}
 	tests := []struct {
-		name    string
-		setup   func() func()
-		args    args
-		want    *gemini.Order
-		wantErr bool
+		name      string
+		setup     func() func()
+		args      args
+		want      bool
+		wantFills *OrderFills
+		wantErr   bool
 	}{
 		{
 			name: "ok_is_fulfilled_upon_creation",
@@ -280,18 +607,16 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want: &gemini.Order{
-				OrderID:           "106817811",
-				AvgExecutionPrice: 3632.8508430064554,
-				IsLive:            false,
-				IsCancelled:       false,
-				ExecutedAmount:    3.7567928949,
-				ClientOrderID:     "20190110-4738721",
+			want: true,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
 			},
-			wantErr: false,
 		},
 		{
 			name: "ok_is_fulfilled_in_query",
@@ -327,18 +652,16 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want: &gemini.Order{
-				OrderID:           "106817811",
-				AvgExecutionPrice: 3632.8508430064554,
-				IsLive:            false,
-				IsCancelled:       false,
-				ExecutedAmount:    3.7567928949,
-				ClientOrderID:     "20190110-4738721",
+			want: true,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
 			},
-			wantErr: false,
 		},
 		{
 			name: "ok_is_cancelled_upon_creation_recreating_success_fulfilled",
@@ -353,7 +676,7 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 						"avg_execution_price": "3632.8508430064554",
 						"is_live": false, 
 						"is_cancelled": true, 
-						"executed_amount": "3.7567928949",
+						"executed_amount": "0",
 						"client_order_id": "20190110-4738721"
 				}`).Times(1)
 				httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
@@ -374,18 +697,73 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want: &gemini.Order{
-				OrderID:           "106817811",
-				AvgExecutionPrice: 3632.8508430064554,
-				IsLive:            false,
-				IsCancelled:       false,
-				ExecutedAmount:    3.7567928949,
-				ClientOrderID:     "20190110-4738721",
+			want: true,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
+			},
+		},
+		{
+			name: "ok_error_in_create_matched_by_client_order_id",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{
+					"bid": "9345.70"
+				}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(gemini.TickerV2URI, "btcsgd"), responder)
+
+				responder = httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
+
+				// Another open buy on the same symbol must not be matched
+				responder = httpmock.NewStringResponder(http.StatusOK, `[
+					{
+						"order_id": "106817810",
+						"is_live": true,
+						"is_cancelled": false,
+						"executed_amount": "0",
+						"client_order_id": "manual",
+						"symbol": "btcsgd",
+						"side": "buy"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, gemini.ActiveOrdersURI, responder)
+
+				clientOrderID := exchange.FormClientOrderID("BTC", config.TestNow, 1, 0)
+				responder = httpmock.NewStringResponder(http.StatusOK, fmt.Sprintf(`[
+					{
+						"order_id": "106817811",
+						"avg_execution_price": "3632.8508430064554",
+						"is_live": false,
+						"is_cancelled": false,
+						"executed_amount": "3.7567928949",
+						"client_order_id": "%s",
+						"symbol": "btcsgd",
+						"side": "buy"
+					}
+				]`, clientOrderID))
+				httpmock.RegisterResponder(http.MethodPost, gemini.OrderStatusURI, responder)
+
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker:         "BTC",
+				fiatAmount:     1,
+				quoteIncrement: 2,
+				tickSize:       8,
+			},
+			want: true,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
 			},
-			wantErr: false,
 		},
 		{
 			name: "error_GetTickerBestBidPrice",
@@ -399,10 +777,11 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want:    false,
 			wantErr: true,
 		},
 		{
@@ -422,10 +801,11 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want:    false,
 			wantErr: true,
 		},
 		{
@@ -441,7 +821,7 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 						"avg_execution_price": "3632.8508430064554",
 						"is_live": false, 
 						"is_cancelled": true, 
-						"executed_amount": "3.7567928949",
+						"executed_amount": "0",
 						"client_order_id": "20190110-4738721"
 				}`).Times(1)
 				httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
@@ -455,10 +835,11 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want:    false,
 			wantErr: true,
 		},
 		{
@@ -474,7 +855,7 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 						"avg_execution_price": "3632.8508430064554",
 						"is_live": false, 
 						"is_cancelled": true, 
-						"executed_amount": "3.7567928949",
+						"executed_amount": "0",
 						"client_order_id": "20190110-4738721"
 				}`)
 				httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
@@ -485,14 +866,15 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want:    false,
 			wantErr: true,
 		},
 		{
-			name: "error_in_query_ok_in_cancel",
+			name: "ok_partially_filled_error_in_query_ok_in_cancel",
 			setup: func() func() {
 				responder := httpmock.NewStringResponder(http.StatusOK, `{
 					"bid": "9345.70"
@@ -528,11 +910,16 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
-			wantErr: false,
+			want: false,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
+			},
 		},
 		{
 			name: "error_is_cancelled_in_query",
@@ -568,10 +955,16 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want: false,
+			wantFills: &OrderFills{
+				ExecutedAmount: executedAmount,
+				FiatSpent:      avgExecutionPrice * executedAmount,
+				OrderIDs:       []string{"106817811"},
+			},
 			wantErr: true,
 		},
 		{
@@ -604,23 +997,30 @@ func Test_handlerCexApiCallsOrderOpenThenCancel(t *testing.T) {
 			},
 			args: args{
 				ticker:         "BTC",
+				fiatAmount:     1,
 				quoteIncrement: 2,
 				tickSize:       8,
 			},
-			want:    nil,
+			want:    false,
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
 			teardown := tt.setup()
-			got, err := handlerCexApiCallsOrderOpenThenCancel(ctx, tt.args.ticker, tt.args.quoteIncrement, tt.args.tickSize)
+			fills := &OrderFills{}
+			got, err := handlerCexApiCallsOrderOpenThenCancel(ctx, tt.args.ticker, tt.args.fiatAmount, tt.args.quoteIncrement, tt.args.tickSize, config.TestNow, 1, fills)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 			}
 			assert.Equal(t, tt.want, got)
+			if tt.wantFills == nil {
+				tt.wantFills = &OrderFills{}
+			}
+			assert.Equal(t, tt.wantFills, fills)
 			teardown()
 		})
 	}
@@ -635,13 +1035,13 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 
 	type args struct {
 		ticker string
-		order  *gemini.Order
+		order  *exchange.Order
 	}
 	tests := []struct {
 		name    string
 		setup   func() func()
 		args    args
-		want    *gemini.Order
+		want    *exchange.Order
 		want1   bool
 		wantErr bool
 	}{
@@ -663,11 +1063,11 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 			},
 			args: args{
 				ticker: "BTC",
-				order: &gemini.Order{
+				order: &exchange.Order{
 					OrderID: "106817811",
 				},
 			},
-			want: &gemini.Order{
+			want: &exchange.Order{
 				OrderID:           "106817811",
 				AvgExecutionPrice: 3632.8508430064554,
 				IsLive:            false,
@@ -696,11 +1096,18 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 			},
 			args: args{
 				ticker: "BTC",
-				order: &gemini.Order{
+				order: &exchange.Order{
 					OrderID: "106817811",
 				},
 			},
-			want:    nil,
+			want: &exchange.Order{
+				OrderID:           "106817811",
+				AvgExecutionPrice: 3632.8508430064554,
+				IsLive:            false,
+				IsCancelled:       true,
+				ExecutedAmount:    3.7567928949,
+				ClientOrderID:     "20190110-4738721",
+			},
 			want1:   true,
 			wantErr: false,
 		},
@@ -722,7 +1129,7 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 			},
 			args: args{
 				ticker: "BTC",
-				order: &gemini.Order{
+				order: &exchange.Order{
 					OrderID: "106817811",
 				},
 			},
@@ -741,7 +1148,7 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 			},
 			args: args{
 				ticker: "BTC",
-				order: &gemini.Order{
+				order: &exchange.Order{
 					OrderID: "106817811",
 				},
 			},
@@ -752,6 +1159,7 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
 			teardown := tt.setup()
 			got, got1, err := handlerCexApiCallsOrderOpenQueryStatus(ctx, tt.args.ticker, tt.args.order)
 			if tt.wantErr {
@@ -765,3 +1173,71 @@ func Test_handlerCexApiCallsOrderOpenQueryStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_handlerCexApiCallsOrderQueryThenCancel_interrupted(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+	setTestJournal(t)
+
+	responder := httpmock.NewStringResponder(http.StatusOK, `{
+		"order_id": "106817811",
+		"avg_execution_price": "1000",
+		"is_live": false,
+		"is_cancelled": true,
+		"executed_amount": "0.0005"
+	}`)
+	httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, responder)
+
+	ctx, cancel := context.WithCancel(util.TestContext())
+	cancel()
+	fills := &OrderFills{}
+	isFilled, err := handlerCexApiCallsOrderQueryThenCancel(ctx, "BTC", &exchange.Order{OrderID: "106817811"}, 1, 0, fills)
+	assert.NoError(t, err)
+	assert.False(t, isFilled)
+	assert.Equal(t, &OrderFills{ExecutedAmount: 0.0005, FiatSpent: 0.5, OrderIDs: []string{"106817811"}}, fills)
+	// cancelled straight away without querying the order status
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func Test_handlerCexApiCallsOrderQueryThenCancel_filledBeforeCancel(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+	setTestJournal(t)
+
+	// Filled between the last query & the cancellation
+	responder := httpmock.NewStringResponder(http.StatusOK, `{
+		"order_id": "106817811",
+		"avg_execution_price": "1000",
+		"is_live": false,
+		"is_cancelled": false,
+		"executed_amount": "0.001",
+		"remaining_amount": "0"
+	}`)
+	httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, responder)
+
+	ctx, cancel := context.WithCancel(util.TestContext())
+	cancel()
+	fills := &OrderFills{}
+	isFilled, err := handlerCexApiCallsOrderQueryThenCancel(ctx, "BTC", &exchange.Order{OrderID: "106817811"}, 1, 0, fills)
+	assert.NoError(t, err)
+	assert.True(t, isFilled)
+	assert.Equal(t, &OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817811"}}, fills)
+}
+
+func TestOrderFills_add(t *testing.T) {
+	fills := &OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817810"}}
+
+	assert.Equal(t, float64(2), fills.add(&exchange.Order{OrderID: "106817811", AvgExecutionPrice: 1000, ExecutedAmount: 0.002}))
+	// already added, e.g. before resuming from the journal
+	assert.Equal(t, float64(0), fills.add(&exchange.Order{OrderID: "106817811", AvgExecutionPrice: 1000, ExecutedAmount: 0.002}))
+	assert.Equal(t, float64(0), fills.add(&exchange.Order{OrderID: "106817810", AvgExecutionPrice: 1000, ExecutedAmount: 0.001}))
+	// not executed
+	assert.Equal(t, float64(0), fills.add(&exchange.Order{OrderID: "106817812", AvgExecutionPrice: 1000}))
+	assert.Equal(t, float64(0), fills.add(nil))
+
+	assert.Equal(t, &OrderFills{ExecutedAmount: 0.003, FiatSpent: 3, OrderIDs: []string{"106817810", "106817811"}}, fills)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+)
+
+// Updates of the order pushed by its exchange, the channel is nil if the exchange does not push order updates
+func subscribeOrderEvents(exchangeClient exchange.Exchange, orderID string) (<-chan *exchange.Order, func()) {
+	source, ok := exchangeClient.(exchange.OrderEventsSource)
+	if !ok {
+		return nil, func() {}
+	}
+	return source.SubscribeOrderEvents(orderID)
+}
+
+// Whether every update of the order since syncedAt, i.e. its last polled status, has been pushed by its exchange, in
+// which case there is no need to poll the order
+func isOrderEventsSynced(exchangeClient exchange.Exchange, syncedAt time.Time) bool {
+	source, ok := exchangeClient.(exchange.OrderEventsSource)
+	if !ok || syncedAt.IsZero() {
+		return false
+	}
+	connectedSince, isConnected := source.OrderEventsConnectedSince()
+	return isConnected && !connectedSince.After(syncedAt)
+}
+
+// Best bid of the ticker streamed by its exchange, the channel is nil if the exchange does not stream market data or
+// live orders are never repriced
+func subscribeBestBid(exchangeClient exchange.Exchange, ticker string) (<-chan float64, func()) {
+	source, ok := exchangeClient.(exchange.MarketDataSource)
+	if !ok || config.Get().OrderMetadata.RepriceBidMoveRatio <= 0 {
+		return nil, func() {}
+	}
+	return source.SubscribeBestBid(ticker)
+}
+
+// Whether the best bid has moved by more than the ratio from the first best bid seen, i.e. the one when the live order
+// was placed
+type bidMoveWatcher struct {
+	ratio    float64
+	firstBid float64
+}
+
+func (w *bidMoveWatcher) isMoved(bid float64) bool {
+	if w.firstBid <= 0 {
+		w.firstBid = bid
+		return false
+	}
+	return math.Abs(bid-w.firstBid)/w.firstBid > w.ratio
+}
+
+// Waits for d, returning early with the update of the order once it is filled or cancelled, or with true once the best
+// bid has moved. Returns nil & false if the order is still live after d and the best bid has not moved
+//
+// Updates already received are returned without waiting in the test flow
+func waitForOrderWindow(ctx context.Context, events <-chan *exchange.Order, bids <-chan float64, bidMove *bidMoveWatcher, d time.Duration) (*exchange.Order, bool, error) {
+	if events == nil && bids == nil {
+		return nil, false, util.Sleep(ctx, d)
+	}
+
+	var timeout <-chan time.Time
+	if util.IsTestFlow(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		closed := make(chan time.Time)
+		close(closed)
+		timeout = closed
+	} else {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// Pending updates take precedence over the timeout, so that none are missed in the test flow
+		select {
+		case order := <-events:
+			if order.IsCancelled || !order.IsLive {
+				return order, false, nil
+			}
+			continue
+		case bid := <-bids:
+			if bidMove.isMoved(bid) {
+				return nil, true, nil
+			}
+			continue
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-timeout:
+			return nil, false, nil
+		case order := <-events:
+			if order.IsCancelled || !order.IsLive {
+				return order, false, nil
+			}
+		case bid := <-bids:
+			if bidMove.isMoved(bid) {
+				return nil, true, nil
+			}
+		}
+	}
+}
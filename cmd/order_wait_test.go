@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Pushes the given order updates & best bids, every other method is served by the wrapped exchange
+type fakeStreamingExchange struct {
+	exchange.Exchange
+	events         []*exchange.Order
+	connectedSince time.Time
+	bids           []float64
+}
+
+func (e *fakeStreamingExchange) SubscribeOrderEvents(orderID string) (<-chan *exchange.Order, func()) {
+	ch := make(chan *exchange.Order, len(e.events))
+	for _, event := range e.events {
+		ch <- event
+	}
+	return ch, func() {}
+}
+
+func (e *fakeStreamingExchange) OrderEventsConnectedSince() (time.Time, bool) {
+	return e.connectedSince, !e.connectedSince.IsZero()
+}
+
+func (e *fakeStreamingExchange) SubscribeBestBid(ticker string) (<-chan float64, func()) {
+	ch := make(chan float64, len(e.bids))
+	for _, bid := range e.bids {
+		ch <- bid
+	}
+	return ch, func() {}
+}
+
+func Test_handlerCexApiCallsOrderQueryThenCancel_streams(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+	defer gemini.MustInitClient()
+
+	liveOrder := `{
+		"order_id": "106817811",
+		"avg_execution_price": "0",
+		"is_live": true,
+		"is_cancelled": false,
+		"executed_amount": "0"
+	}`
+	cancelledOrder := `{
+		"order_id": "106817811",
+		"avg_execution_price": "0",
+		"is_live": false,
+		"is_cancelled": true,
+		"executed_amount": "0"
+	}`
+
+	tests := []struct {
+		name            string
+		events          []*exchange.Order
+		connectedSince  time.Time
+		bids            []float64
+		wantIsFilled    bool
+		wantFills       *OrderFills
+		wantStatusCalls int
+		wantCancelCalls int
+	}{
+		{
+			name:   "ok_filled_event",
+			events: []*exchange.Order{{OrderID: "106817811", IsLive: true}, {OrderID: "106817811", AvgExecutionPrice: 1000, ExecutedAmount: 0.001}},
+			// the live event is skipped, the fill is taken without querying the order status
+			connectedSince: time.Now().Add(-time.Hour),
+			wantIsFilled:   true,
+			wantFills:      &OrderFills{ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817811"}},
+		},
+		{
+			name:            "ok_connected_polled_once",
+			connectedSince:  time.Now().Add(-time.Hour),
+			wantFills:       &OrderFills{},
+			wantStatusCalls: 1,
+			wantCancelCalls: 1,
+		},
+		{
+			name:            "ok_bid_moved",
+			connectedSince:  time.Now().Add(-time.Hour),
+			bids:            []float64{1000, 1001, 1003},
+			wantFills:       &OrderFills{},
+			wantCancelCalls: 1,
+		},
+		{
+			name:            "ok_bid_not_moved",
+			connectedSince:  time.Now().Add(-time.Hour),
+			bids:            []float64{1000, 1001, 999},
+			wantFills:       &OrderFills{},
+			wantStatusCalls: 1,
+			wantCancelCalls: 1,
+		},
+		{
+			name:            "ok_disconnected_polled_every_window",
+			wantFills:       &OrderFills{},
+			wantStatusCalls: config.OrderOpenQueryStatusWindowCount,
+			wantCancelCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
+			defer httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodPost, gemini.OrderStatusURI, httpmock.NewStringResponder(http.StatusOK, liveOrder))
+			httpmock.RegisterResponder(http.MethodPost, gemini.CancelOrderURI, httpmock.NewStringResponder(http.StatusOK, cancelledOrder))
+			exchange.Set(config.ExchangeGemini, &fakeStreamingExchange{
+				Exchange:       gemini.GetClient(),
+				events:         tt.events,
+				connectedSince: tt.connectedSince,
+				bids:           tt.bids,
+			})
+
+			fills := &OrderFills{}
+			isFilled, err := handlerCexApiCallsOrderQueryThenCancel(ctx, "BTC", &exchange.Order{OrderID: "106817811"}, 1, 0, fills)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIsFilled, isFilled)
+			assert.Equal(t, tt.wantFills, fills)
+			info := httpmock.GetCallCountInfo()
+			assert.Equal(t, tt.wantStatusCalls, info[http.MethodPost+" "+gemini.OrderStatusURI])
+			assert.Equal(t, tt.wantCancelCalls, info[http.MethodPost+" "+gemini.CancelOrderURI])
+		})
+	}
+}
+
+func Test_waitForOrderWindow(t *testing.T) {
+	t.Run("ok_closed_event", func(t *testing.T) {
+		events := make(chan *exchange.Order, 1)
+		go func() {
+			events <- &exchange.Order{OrderID: "1", IsLive: true}
+			events <- &exchange.Order{OrderID: "1", IsCancelled: true}
+		}()
+		order, isBidMoved, err := waitForOrderWindow(context.Background(), events, nil, &bidMoveWatcher{ratio: 0.002}, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, isBidMoved)
+		assert.Equal(t, &exchange.Order{OrderID: "1", IsCancelled: true}, order)
+	})
+
+	t.Run("ok_bid_moved", func(t *testing.T) {
+		bids := make(chan float64, 1)
+		go func() {
+			bids <- 1000
+			bids <- 1002
+			bids <- 997
+		}()
+		bidMove := &bidMoveWatcher{ratio: 0.002}
+		order, isBidMoved, err := waitForOrderWindow(context.Background(), nil, bids, bidMove, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, isBidMoved)
+		assert.Nil(t, order)
+		assert.Equal(t, 1000.0, bidMove.firstBid)
+	})
+
+	t.Run("ok_timeout", func(t *testing.T) {
+		order, isBidMoved, err := waitForOrderWindow(context.Background(), make(chan *exchange.Order), nil, &bidMoveWatcher{}, time.Millisecond)
+		assert.NoError(t, err)
+		assert.False(t, isBidMoved)
+		assert.Nil(t, order)
+	})
+
+	t.Run("error_interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		order, isBidMoved, err := waitForOrderWindow(ctx, make(chan *exchange.Order), nil, &bidMoveWatcher{}, time.Minute)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, isBidMoved)
+		assert.Nil(t, order)
+	})
+}
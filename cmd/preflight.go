@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Tickers that already have a fill for today, either recorded in the db or found in the exchange's order history,
+// so that re-running on the same day does not buy again. Tickers journaled by a crashed run today are resumed instead
+//
+// Tickers not part of this run are treated as done
+func getTickersDoneForToday(ctx context.Context, tickers map[string]bool) (map[string]bool, error) {
+	location := "cmd.getTickersDoneForToday"
+	c := config.Get()
+	today := config.GetTime().GetTodayDate()
+
+	rows, err := db.Get().GetOrdersCreatedForDay(today)
+	if err != nil {
+		logger.Error(location, "Error getting orders created for today", err)
+		return nil, err
+	}
+	recordedTickers := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		recordedTickers[row.Ticker] = true
+	}
+
+	doneTickers := make(map[string]bool)
+	for ticker := range c.CryptoTickers {
+		if !tickers[ticker] {
+			logger.Info(location, "Ticker '%s' is not scheduled for this run", ticker)
+			doneTickers[ticker] = true
+			continue
+		}
+		if recordedTickers[exchange.Pair(ticker)] {
+			logger.Warn(location, "Ticker '%s' already has an order recorded for today", ticker)
+			doneTickers[ticker] = true
+			continue
+		}
+
+		// Simulated orders are not placed on the exchange, neither are orders of tickers not scheduled for today
+		if c.IsSimulatedOrder(ticker) || !c.IsDueToday(ticker) {
+			continue
+		}
+
+		// Fills of a crashed run today are resumed from the journal instead
+		if getJournalEntry(ticker) != nil {
+			logger.Info(location, "Ticker '%s' has a journal entry for today, resuming", ticker)
+			continue
+		}
+
+		hasFilledBuyOrder, err := util.Retry(ctx, fmt.Sprintf("HasFilledBuyOrderSince - %v", ticker), func() (bool, error) {
+			return exchange.Get(ticker).HasFilledBuyOrderSince(ctx, ticker, today)
+		})
+		if err != nil {
+			logger.Error(location, "'%s' Error checking order history", err, ticker)
+			return nil, err
+		}
+		if hasFilledBuyOrder {
+			logger.Warn(location, "Ticker '%s' already has a filled order on the exchange today but none recorded", ticker)
+			doneTickers[ticker] = true
+		}
+	}
+
+	return doneTickers, nil
+}
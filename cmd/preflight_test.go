@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getTickersDoneForToday(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+	// Orders of tickers bought on Kraken are simulated in the sandbox environment, i.e. not checked on the exchange
+	simulatedExchanges := map[string]string{"BTC": config.ExchangeKraken, "ETH": config.ExchangeKraken}
+
+	tests := []struct {
+		name         string
+		isSandboxEnv bool
+		exchanges    map[string]string
+		tickers      map[string]bool // defaults to every crypto ticker
+		setup        func(*mocks.MockOrderRepository) func()
+		want         map[string]bool
+		wantErr      bool
+	}{
+		{
+			name:         "ok_recorded_in_db",
+			isSandboxEnv: true,
+			exchanges:    simulatedExchanges,
+			setup: func(orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return([]*db.Order{
+					{Ticker: "btcsgd", CreatedForDay: config.TestNowDate},
+				}, nil)
+				return func() {}
+			},
+			want: map[string]bool{"BTC": true},
+		},
+		{
+			name:         "ok_not_part_of_run",
+			isSandboxEnv: true,
+			exchanges:    simulatedExchanges,
+			tickers:      map[string]bool{"BTC": true},
+			setup: func(orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
+				return func() {}
+			},
+			want: map[string]bool{"ETH": true},
+		},
+		{
+			name:         "ok_filled_on_exchange",
+			isSandboxEnv: false,
+			setup: func(orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
+				// Only ETH has a filled order in the order history
+				httpmock.RegisterResponder(http.MethodPost, gemini.OrderHistoryURI, func(req *http.Request) (*http.Response, error) {
+					payload, _ := base64.StdEncoding.DecodeString(req.Header.Get("X-GEMINI-PAYLOAD"))
+					params := map[string]any{}
+					_ = json.Unmarshal(payload, &params)
+					if params["symbol"] != "ethsgd" {
+						return httpmock.NewStringResponse(http.StatusOK, `[]`), nil
+					}
+					return httpmock.NewStringResponse(http.StatusOK, `[
+						{
+							"order_id": "106817811",
+							"symbol": "ethsgd",
+							"side": "buy",
+							"is_live": false,
+							"is_cancelled": false,
+							"executed_amount": "0.01"
+						}
+					]`), nil
+				})
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]bool{"ETH": true},
+		},
+		{
+			name:         "error_db",
+			isSandboxEnv: true,
+			setup: func(orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, errors.New("error"))
+				return func() {}
+			},
+			wantErr: true,
+		},
+		{
+			name:         "error_exchange",
+			isSandboxEnv: false,
+			setup: func(orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, gemini.OrderHistoryURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
+			config.TestInit(&config.ConfigUpdateable{
+				IsSandboxEnv: util.PtrOf(tt.isSandboxEnv),
+				Exchanges:    tt.exchanges,
+			}, &config.TestNow)
+			ctrl := gomock.NewController(t)
+			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+			db.Set(mockOrderDB)
+
+			teardown := tt.setup(mockOrderDB)
+			tickers := tt.tickers
+			if tickers == nil {
+				tickers = config.Get().CryptoTickers
+			}
+			got, err := getTickersDoneForToday(ctx, tickers)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Replaces the fills, as accumulated from the orders, with the actual fills and fees from the trades of the orders
+//
+// The fills are left as they are if the trade history cannot be fetched, so that the fee is estimated instead
+func reconcileFills(ctx context.Context, ticker string, fills *OrderFills) {
+	location := "cmd.reconcileFills"
+	if len(fills.OrderIDs) == 0 || fills.FeeCurrency != "" {
+		return
+	}
+
+	trades, err := util.Retry(ctx, fmt.Sprintf("GetOrderTrades - %v", ticker), func() ([]*exchange.Trade, error) {
+		return exchange.Get(ticker).GetOrderTrades(ctx, ticker, fills.OrderIDs, config.GetTime().GetTodayDate())
+	})
+	if err != nil {
+		logger.Warn(location, "'%s' Unable to get trades, estimating fee instead, err: %v", ticker, err)
+		return
+	}
+
+	reconciled, err := formFillsFromTrades(trades)
+	if err != nil {
+		logger.Warn(location, "'%s' Unable to reconcile trades, estimating fee instead, err: %v", ticker, err)
+		return
+	}
+	if math.Abs(reconciled.ExecutedAmount-fills.ExecutedAmount) > 1e-12 {
+		logger.Warn(location, "'%s' Executed amount of orders %v differs from trades %v, taking trades", ticker, fills.ExecutedAmount, reconciled.ExecutedAmount)
+	}
+
+	reconciled.OrderIDs = fills.OrderIDs
+	*fills = *reconciled
+	logger.Info(location, "'%s' Reconciled fills: %+v", ticker, fills)
+}
+
+func formFillsFromTrades(trades []*exchange.Trade) (*OrderFills, error) {
+	if len(trades) == 0 {
+		return nil, errors.New("no_trades")
+	}
+	fills := &OrderFills{}
+	for _, trade := range trades {
+		feeCurrency := strings.ToUpper(trade.FeeCurrency)
+		if fills.FeeCurrency != "" && fills.FeeCurrency != feeCurrency {
+			return nil, fmt.Errorf("mixed_fee_currencies: %s, %s", fills.FeeCurrency, feeCurrency)
+		}
+		fills.ExecutedAmount += trade.Amount
+		fills.FiatSpent += trade.Price * trade.Amount
+		fills.FeeAmount += trade.FeeAmount
+		fills.FeeCurrency = feeCurrency
+	}
+	return fills, nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_reconcileFills(t *testing.T) {
+	ctx := util.TestContext()
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, &config.TestNow)
+	gemini.MustInitClient()
+
+	estimatedFills := func() *OrderFills {
+		return &OrderFills{
+			ExecutedAmount: 0.002,
+			FiatSpent:      2,
+			OrderIDs:       []string{"106817811", "106817812"},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		fills *OrderFills
+		setup func() func()
+		want  *OrderFills
+	}{
+		{
+			name:  "ok",
+			fills: estimatedFills(),
+			setup: func() func() {
+				// Second order filled partly as taker at a higher fee, the last trade is of another order
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{"price": "1000", "amount": "0.001", "aggressor": false, "fee_currency": "SGD", "fee_amount": "0.002", "tid": 1, "order_id": "106817811"},
+					{"price": "1000", "amount": "0.0005", "aggressor": false, "fee_currency": "SGD", "fee_amount": "0.001", "tid": 2, "order_id": "106817812"},
+					{"price": "1002", "amount": "0.0005", "aggressor": true, "fee_currency": "SGD", "fee_amount": "0.002004", "tid": 3, "order_id": "106817812"},
+					{"price": "900", "amount": "1", "aggressor": false, "fee_currency": "SGD", "fee_amount": "1.8", "tid": 4, "order_id": "106817800"}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, gemini.MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: &OrderFills{
+				ExecutedAmount: 0.002,
+				FiatSpent:      2.001,
+				OrderIDs:       []string{"106817811", "106817812"},
+				FeeAmount:      0.005004,
+				FeeCurrency:    "SGD",
+			},
+		},
+		{
+			name:  "ok_error_estimated",
+			fills: estimatedFills(),
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, gemini.MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: estimatedFills(),
+		},
+		{
+			name:  "ok_no_trades_estimated",
+			fills: estimatedFills(),
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[]`)
+				httpmock.RegisterResponder(http.MethodPost, gemini.MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: estimatedFills(),
+		},
+		{
+			name:  "ok_mixed_fee_currencies_estimated",
+			fills: estimatedFills(),
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{"price": "1000", "amount": "0.001", "fee_currency": "SGD", "fee_amount": "0.002", "tid": 1, "order_id": "106817811"},
+					{"price": "1000", "amount": "0.001", "fee_currency": "GUSD", "fee_amount": "0.002", "tid": 2, "order_id": "106817812"}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, gemini.MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: estimatedFills(),
+		},
+		{
+			name: "ok_already_reconciled",
+			fills: &OrderFills{
+				ExecutedAmount: 0.002,
+				FiatSpent:      2,
+				OrderIDs:       []string{"106817811"},
+				FeeAmount:      0.004,
+				FeeCurrency:    "SGD",
+			},
+			setup: func() func() {
+				return func() {}
+			},
+			want: &OrderFills{
+				ExecutedAmount: 0.002,
+				FiatSpent:      2,
+				OrderIDs:       []string{"106817811"},
+				FeeAmount:      0.004,
+				FeeCurrency:    "SGD",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := tt.setup()
+			reconcileFills(ctx, "BTC", tt.fills)
+			assert.InDelta(t, tt.want.ExecutedAmount, tt.fills.ExecutedAmount, 1e-12)
+			assert.InDelta(t, tt.want.FiatSpent, tt.fills.FiatSpent, 1e-9)
+			assert.InDelta(t, tt.want.FeeAmount, tt.fills.FeeAmount, 1e-12)
+			assert.Equal(t, tt.want.FeeCurrency, tt.fills.FeeCurrency)
+			assert.Equal(t, tt.want.OrderIDs, tt.fills.OrderIDs)
+			teardown()
+		})
+	}
+}
+
+func Test_formPostOrderData(t *testing.T) {
+	tests := []struct {
+		name   string
+		ticker string
+		fills  *OrderFills
+		want   PostOrder
+	}{
+		{
+			name:   "ok_estimated_fee",
+			ticker: "BTC",
+			fills:  &OrderFills{ExecutedAmount: 0.002, FiatSpent: 2},
+			want:   PostOrder{ActualFiatDeposit: 2.004, AvgExecutionPrice: 1000, ExecutedAmount: 0.002, Fee: 0.004, FeeCurrency: "SGD"},
+		},
+		{
+			name:   "ok_reconciled_fee",
+			ticker: "BTC",
+			fills:  &OrderFills{ExecutedAmount: 0.002, FiatSpent: 2, FeeAmount: 0.007, FeeCurrency: "SGD"},
+			want:   PostOrder{ActualFiatDeposit: 2.007, AvgExecutionPrice: 1000, ExecutedAmount: 0.002, Fee: 0.007, FeeCurrency: "SGD"},
+		},
+		{
+			name:   "ok_fee_in_other_currency",
+			ticker: "SOL",
+			fills:  &OrderFills{ExecutedAmount: 0.02, FiatSpent: 2, FeeAmount: 0.007, FeeCurrency: "GUSD"},
+			want:   PostOrder{ActualFiatDeposit: 2, AvgExecutionPrice: 100, ExecutedAmount: 0.02, Fee: 0.007, FeeCurrency: "GUSD"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formPostOrderData(tt.ticker, tt.fills)
+			assert.InDelta(t, tt.want.ActualFiatDeposit, got.ActualFiatDeposit, 1e-9)
+			assert.InDelta(t, tt.want.AvgExecutionPrice, got.AvgExecutionPrice, 1e-9)
+			assert.InDelta(t, tt.want.ExecutedAmount, got.ExecutedAmount, 1e-12)
+			assert.InDelta(t, tt.want.Fee, got.Fee, 1e-12)
+			assert.Equal(t, tt.want.FeeCurrency, got.FeeCurrency)
+		})
+	}
+}
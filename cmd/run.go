@@ -2,30 +2,106 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
+// Returned when the run is cancelled midway, e.g. on SIGINT or SIGTERM. Fills obtained so far are still written
+var ErrInterrupted = errors.New("run is interrupted")
+
 func Run(ctx context.Context) error {
+	return RunTickers(ctx, config.Get().CryptoTickers)
+}
+
+// Runs the crypto tickers given only, the rest are left as is
+//
+// Once ctx is cancelled, live orders of the run are cancelled and no new order is placed
+func RunTickers(ctx context.Context, tickers map[string]bool) (err error) {
 	location := "cmd.Run"
-	logger.Info(location, "Running main script...")
+	logger.Info(location, "Running main script for %v...", tickers)
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrInterrupted, err)
+		} else {
+			err = ErrInterrupted
+		}
+	}()
+
+	// cancel orders left live by crashed runs of previous days
+	cancelStaleJournaledOrders(ctx)
+
+	// skip tickers already bought today, so that reruns are safe
+	doneTickers, err := getTickersDoneForToday(ctx, tickers)
+	if err != nil {
+		logger.Error(location, "Pre-flight check of tickers done for today", err)
+		return err
+	}
+
+	// daily fiat amounts as configured, in the quote currency of each ticker
+	dailyFiatBudgets, err := getDailyFiatBudgets(ctx)
+	if err != nil {
+		logger.Error(location, "Converting daily fiat amounts", err)
+		return err
+	}
 
-	postOrderDetails := handleOrder(ctx)
+	// unspent fiat amounts of previous days to add to today's orders
+	carryForwards := getCarryForwards()
+	carriedForwardAmounts := getCarriedForwardAmounts(carryForwards)
+	if len(carriedForwardAmounts) > 0 {
+		logger.Info(location, "Carried forward: %v", carriedForwardAmounts)
+	}
+
+	// make sure the balances cover today's orders
+	dailyFiatAmounts, err := getDailyFiatAmounts(ctx, doneTickers, dailyFiatBudgets, carriedForwardAmounts)
+	if err != nil {
+		logger.Error(location, "Pre-trade balance check", err)
+		return err
+	}
+
+	postOrderDetails := handleOrder(ctx, tickers, doneTickers, dailyFiatAmounts)
+	// fills are flushed even if interrupted
+	flushCtx := context.WithoutCancel(ctx)
+	if ctx.Err() != nil {
+		// every order loop cancels its own live order, this is for those that failed to
+		logger.Warn(location, "Interrupted, flushing fills obtained so far")
+		cancelLiveJournaledOrders(flushCtx, tickers)
+	}
+	addCarriedForwardAmounts(postOrderDetails, carriedForwardAmounts)
 	logger.Info(location, "postOrderDetails: %v", postOrderDetails)
 
 	// update google sheets cells
-	if err := batchUpdate(postOrderDetails); err != nil {
+	if err := batchUpdate(config.GetTime().GetTodayDate(), postOrderDetails); err != nil {
 		logger.Error(location, "Batch update google sheets", err)
 		return err
 	}
 	logger.Info(location, "Batch update google sheets successful")
 
-	// insert into db
-	if err := bulkInsertIntoDB(postOrderDetails); err != nil {
-		logger.Error(location, "Batch insert into db", err)
+	// upsert into db
+	if err := bulkUpsertIntoDB(flushCtx, config.GetTime().GetTodayDate(), postOrderDetails); err != nil {
+		logger.Error(location, "Batch upsert into db", err)
 		return err
 	}
-	logger.Info(location, "Batch insert into db successful")
+	logger.Info(location, "Batch upsert into db successful")
+
+	// record today's unspent fiat amounts, or spend the ones carried forward
+	updateCarryForwards(postOrderDetails, doneTickers, carryForwards, dailyFiatBudgets)
+
+	// fills are persisted, nothing left to resume
+	clearedTickers := tickers
+	if ctx.Err() != nil {
+		// unless interrupted before having any fills, to be resumed by the next run
+		clearedTickers = make(map[string]bool, postOrderDetails.Size())
+		for _, ticker := range postOrderDetails.Keys() {
+			clearedTickers[ticker.(string)] = true
+		}
+	}
+	clearJournal(clearedTickers)
 
 	logger.Info(location, "Successfully completed. Tearing down...")
 
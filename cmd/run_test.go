@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini/geminitest"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/google_sheets"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/kraken"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/jeraldyik/crypto_dca_go/mocks"
 	"github.com/stretchr/testify/assert"
@@ -19,10 +25,15 @@ import (
 
 func TestRun(t *testing.T) {
 	ctx := util.TestContext()
-	config.TestInit(nil, &config.TestNow)
+	// Orders of tickers bought on Kraken are simulated in the sandbox environment
+	config.TestInit(&config.ConfigUpdateable{
+		Exchanges: map[string]string{"BTC": config.ExchangeKraken, "ETH": config.ExchangeKraken},
+	}, &config.TestNow)
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	gemini.MustInitClient()
+	kraken.MustInitClient()
+	sgdFormat := &sheets.CellFormat{NumberFormat: fiatNumberFormat("SGD")}
 
 	tests := []struct {
 		name    string
@@ -63,6 +74,7 @@ func TestRun(t *testing.T) {
 				}`)
 				httpmock.RegisterResponder(http.MethodPost, gemini.NewOrderURI, responder)
 
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
 				gs.EXPECT().GetSheetID().Return(int64(1234), nil)
 				gs.EXPECT().BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
 					Requests: []*sheets.Request{
@@ -79,7 +91,7 @@ func TestRun(t *testing.T) {
 									{
 										Values: []*sheets.CellData{
 											{UserEnteredValue: &sheets.ExtendedValue{StringValue: &config.TestNowDateStr}},
-											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(1.002)}},
+											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(1.0025)}},
 											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1000))}},
 											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1))}},
 										},
@@ -88,6 +100,23 @@ func TestRun(t *testing.T) {
 								Fields: "userEnteredValue",
 							},
 						},
+						{
+							UpdateCells: &sheets.UpdateCellsRequest{
+								Range: &sheets.GridRange{
+									SheetId:          1234,
+									StartRowIndex:    2,
+									EndRowIndex:      3,
+									StartColumnIndex: 5,
+									EndColumnIndex:   7,
+								},
+								Rows: []*sheets.RowData{
+									{
+										Values: []*sheets.CellData{{UserEnteredFormat: sgdFormat}, {UserEnteredFormat: sgdFormat}},
+									},
+								},
+								Fields: "userEnteredFormat.numberFormat",
+							},
+						},
 						{
 							UpdateCells: &sheets.UpdateCellsRequest{
 								Range: &sheets.GridRange{
@@ -101,7 +130,7 @@ func TestRun(t *testing.T) {
 									{
 										Values: []*sheets.CellData{
 											{UserEnteredValue: &sheets.ExtendedValue{StringValue: &config.TestNowDateStr}},
-											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(2.004)}},
+											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(2.005)}},
 											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1000))}},
 											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1))}},
 										},
@@ -110,23 +139,56 @@ func TestRun(t *testing.T) {
 								Fields: "userEnteredValue",
 							},
 						},
+						{
+							UpdateCells: &sheets.UpdateCellsRequest{
+								Range: &sheets.GridRange{
+									SheetId:          1234,
+									StartRowIndex:    3,
+									EndRowIndex:      4,
+									StartColumnIndex: 9,
+									EndColumnIndex:   11,
+								},
+								Rows: []*sheets.RowData{
+									{
+										Values: []*sheets.CellData{{UserEnteredFormat: sgdFormat}, {UserEnteredFormat: sgdFormat}},
+									},
+								},
+								Fields: "userEnteredFormat.numberFormat",
+							},
+						},
 					},
 				}).Return(nil)
 
-				orderDB.EXPECT().BulkInsert([]*db.Order{
+				orderDB.EXPECT().BulkUpsert([]*db.Order{
 					{
-						Ticker:            "btcsgd",
-						CreatedForDay:     config.TestNowDate,
-						FiatDepositInSGD:  1.002,
-						PricePerCoinInSGD: 1000,
-						CoinAmount:        1,
+						Ticker:                         "btcsgd",
+						CreatedForDay:                  config.TestNowDate,
+						QuoteCurrency:                  "SGD",
+						FiatDeposit:                    1.0025,
+						PricePerCoin:                   1000,
+						CoinAmount:                     1,
+						Fee:                            0.0025,
+						FeeCurrency:                    "SGD",
+						ReportingCurrency:              "SGD",
+						FxRate:                         1,
+						FiatDepositInReportingCurrency: 1.0025,
+						CreatedAt:                      config.TestNow,
+						UpdatedAt:                      config.TestNow,
 					},
 					{
-						Ticker:            "ethsgd",
-						CreatedForDay:     config.TestNowDate,
-						FiatDepositInSGD:  2.004,
-						PricePerCoinInSGD: 1000,
-						CoinAmount:        1,
+						Ticker:                         "ethsgd",
+						CreatedForDay:                  config.TestNowDate,
+						QuoteCurrency:                  "SGD",
+						FiatDeposit:                    2.005,
+						PricePerCoin:                   1000,
+						CoinAmount:                     1,
+						Fee:                            0.005,
+						FeeCurrency:                    "SGD",
+						ReportingCurrency:              "SGD",
+						FxRate:                         1,
+						FiatDepositInReportingCurrency: 2.005,
+						CreatedAt:                      config.TestNow,
+						UpdatedAt:                      config.TestNow,
 					},
 				}).Return(nil)
 
@@ -135,9 +197,93 @@ func TestRun(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "ok_skip_tickers_done_for_today",
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return([]*db.Order{
+					{
+						Ticker:        "btcsgd",
+						CreatedForDay: config.TestNowDate,
+					},
+				}, nil)
+				gs.EXPECT().GetSheetID().Return(int64(1234), nil)
+				gs.EXPECT().BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
+					Requests: []*sheets.Request{
+						{
+							UpdateCells: &sheets.UpdateCellsRequest{
+								Range: &sheets.GridRange{
+									SheetId:          1234,
+									StartRowIndex:    3,
+									EndRowIndex:      4,
+									StartColumnIndex: 8,
+									EndColumnIndex:   12,
+								},
+								Rows: []*sheets.RowData{
+									{
+										Values: []*sheets.CellData{
+											{UserEnteredValue: &sheets.ExtendedValue{StringValue: &config.TestNowDateStr}},
+											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(2.005)}},
+											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1000))}},
+											{UserEnteredValue: &sheets.ExtendedValue{NumberValue: util.PtrOf(float64(1))}},
+										},
+									},
+								},
+								Fields: "userEnteredValue",
+							},
+						},
+						{
+							UpdateCells: &sheets.UpdateCellsRequest{
+								Range: &sheets.GridRange{
+									SheetId:          1234,
+									StartRowIndex:    3,
+									EndRowIndex:      4,
+									StartColumnIndex: 9,
+									EndColumnIndex:   11,
+								},
+								Rows: []*sheets.RowData{
+									{
+										Values: []*sheets.CellData{{UserEnteredFormat: sgdFormat}, {UserEnteredFormat: sgdFormat}},
+									},
+								},
+								Fields: "userEnteredFormat.numberFormat",
+							},
+						},
+					},
+				}).Return(nil)
+
+				orderDB.EXPECT().BulkUpsert([]*db.Order{
+					{
+						Ticker:                         "ethsgd",
+						CreatedForDay:                  config.TestNowDate,
+						QuoteCurrency:                  "SGD",
+						FiatDeposit:                    2.005,
+						PricePerCoin:                   1000,
+						CoinAmount:                     1,
+						Fee:                            0.005,
+						FeeCurrency:                    "SGD",
+						ReportingCurrency:              "SGD",
+						FxRate:                         1,
+						FiatDepositInReportingCurrency: 2.005,
+						CreatedAt:                      config.TestNow,
+						UpdatedAt:                      config.TestNow,
+					},
+				}).Return(nil)
+
+				return func() {}
+			},
+		},
+		{
+			name: "error_pre_flight_check",
+			setup: func(gs *mocks.MockGoogleSheetsRepository, orderDB *mocks.MockOrderRepository) func() {
+				orderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, errors.New("error"))
+				return func() {}
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			setTestJournal(t)
 			ctrl := gomock.NewController(t)
 			mockGS := mocks.NewMockGoogleSheetsRepository(ctrl)
 			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
@@ -155,3 +301,117 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+// The whole run against a fake exchange, with orders filled over several minutes and requests failing on the way
+func TestRun_fakeGemini(t *testing.T) {
+	ctx := util.TestContext()
+	server := geminitest.NewServer("gemini_api_key", "gemini_api_secret")
+	defer server.Close()
+	config.TestInit(&config.ConfigUpdateable{
+		IsSandboxEnv:    util.PtrOf(false),
+		DailyFiatAmount: map[string]float64{"BTC": 100, "ETH": 200},
+		GeminiBaseUrl:   server.URL,
+	}, &config.TestNow)
+	gemini.MustInitClient()
+	defer gemini.MustInitClient()
+	setTestJournal(t)
+
+	server.SetBalance("SGD", 1000)
+	server.AddSymbol(geminitest.Symbol{
+		Symbol: "btcsgd", BaseCurrency: "BTC", QuoteCurrency: "SGD", TickSize: 1e-8, QuoteIncrement: 0.01, MinOrderSize: 0.00001,
+		// filled at the order price of 999 once the ask drops to it
+		Prices: []geminitest.Price{{Bid: 1000, Ask: 1001}, {Bid: 1000, Ask: 1001}, {Bid: 998, Ask: 998.5}},
+	})
+	server.AddSymbol(geminitest.Symbol{
+		Symbol: "ethsgd", BaseCurrency: "ETH", QuoteCurrency: "SGD", TickSize: 1e-6, QuoteIncrement: 0.01, MinOrderSize: 0.001,
+		// partially filled at the order price of 99.9, then filled
+		Prices: []geminitest.Price{{Bid: 100, Ask: 100.1}, {Bid: 99.8, Ask: 99.9, Liquidity: 1}, {Bid: 99.5, Ask: 99.6}},
+	})
+	server.InjectFaults(gemini.OrderStatusURI, geminitest.RateLimited(time.Second), geminitest.Unavailable())
+	server.InjectFaults(gemini.BalancesURI, geminitest.Timeout(0))
+
+	ctrl := gomock.NewController(t)
+	mockGS := mocks.NewMockGoogleSheetsRepository(ctrl)
+	mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+	google_sheets.Set(mockGS)
+	db.Set(mockOrderDB)
+	mockOrderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
+	mockGS.EXPECT().GetSheetID().Return(int64(1234), nil)
+	mockGS.EXPECT().BatchUpdate(gomock.Any()).Return(nil)
+	var rows []*db.Order
+	mockOrderDB.EXPECT().BulkUpsert(gomock.Any()).DoAndReturn(func(orders []*db.Order) error {
+		rows = orders
+		return nil
+	})
+
+	assert.NoError(t, Run(ctx))
+
+	orders := server.Orders()
+	assert.Len(t, orders, 2)
+	for _, order := range orders {
+		assert.False(t, order.IsLive)
+		assert.False(t, order.IsCancelled)
+	}
+	assert.Len(t, rows, 2)
+	fiatDeposit := 0.0
+	for _, row := range rows {
+		fiatDeposit += row.FiatDeposit
+		switch row.Ticker {
+		case "btcsgd":
+			assert.InDelta(t, 999, row.PricePerCoin, 1e-9)
+			assert.InDelta(t, server.Balance("BTC"), row.CoinAmount, 1e-9)
+		case "ethsgd":
+			assert.InDelta(t, 99.9, row.PricePerCoin, 1e-9)
+			assert.InDelta(t, server.Balance("ETH"), row.CoinAmount, 1e-9)
+		}
+	}
+	// fees included
+	assert.InDelta(t, 1000-server.Balance("SGD"), fiatDeposit, 1e-6)
+	assert.Equal(t, 2, server.Requests(gemini.BalancesURI))
+}
+
+// Rates of a provider requesting them over the network, i.e. failing once ctx is done
+type ctxRateProvider struct {
+	fx.RateProvider
+}
+
+func (p ctxRateProvider) GetRate(ctx context.Context, from, to string) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return p.RateProvider.GetRate(ctx, from, to)
+}
+
+// Fills of an interrupted run are still written, converted into the reporting currency
+func TestRun_interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(util.TestContext())
+	cancel()
+	config.TestInit(&config.ConfigUpdateable{
+		Exchanges: map[string]string{"BTC": config.ExchangeKraken, "ETH": config.ExchangeKraken},
+		Fx:        &config.Fx{ReportingCurrency: "USD", BudgetCurrency: config.BudgetCurrencyQuote},
+	}, &config.TestNow)
+	kraken.MustInitClient()
+	fx.Set(ctxRateProvider{fx.NewStaticProvider(map[string]float64{"USD/SGD": 1.25})})
+	defer fx.Set(nil)
+	setTestJournal(t)
+
+	ctrl := gomock.NewController(t)
+	mockGS := mocks.NewMockGoogleSheetsRepository(ctrl)
+	mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+	google_sheets.Set(mockGS)
+	db.Set(mockOrderDB)
+	mockOrderDB.EXPECT().GetOrdersCreatedForDay(config.TestNowDate).Return(nil, nil)
+	mockGS.EXPECT().GetSheetID().Return(int64(1234), nil)
+	mockGS.EXPECT().BatchUpdate(gomock.Any()).Return(nil)
+	mockOrderDB.EXPECT().BulkUpsert(gomock.Any()).DoAndReturn(func(rows []*db.Order) error {
+		assert.Len(t, rows, 2)
+		for _, row := range rows {
+			assert.Equal(t, "USD", row.ReportingCurrency)
+			assert.Equal(t, 0.8, row.FxRate)
+		}
+		return nil
+	})
+
+	err := Run(ctx)
+	assert.ErrorIs(t, err, ErrInterrupted)
+}
@@ -2,33 +2,87 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+	"github.com/supabase-community/postgrest-go"
 )
 
-func (o *OrderDB) BulkInsert(rows []*Order) error {
-	location := "db.BulkInsert"
-	/*
-		result := o.db.Create(rows)
-		if result.Error != nil {
-			logger.Error(location, "Failed to insert rows", result.Error)
-			return result.Error
-		} else if result.RowsAffected != int64(len(rows)) {
-			err := errors.New("db_insert_mismatched_rows_count")
-			logger.Error(location, "Failed to insert correct number of rows. got = %v, expected = %v", err, result.RowsAffected, len(rows))
-			return err
-		}
-	*/
-	_, num_rows, err := o.db.From("Orders").Insert(rows, false, "", "minimal", "exact").Execute()
+// Rows are unique on (ticker, createdForDay), so re-running on the same day overwrites instead of duplicating
+func (o *OrderDB) BulkUpsert(rows []*Order) error {
+	location := "db.BulkUpsert"
+	_, num_rows, err := o.db.From(Order{}.TableName()).Upsert(rows, orderUniqueColumns, "minimal", "exact").Execute()
 	if err != nil {
-		logger.Error(location, "Failed to insert rows: %v", err)
+		logger.Error(location, "Failed to upsert rows: %v", err)
 		return err
 	} else if num_rows != int64(len(rows)) {
-		err := errors.New("db_insert_mismatched_rows_count")
-		logger.Error(location, "Failed to insert correct number of rows. got = %v, expected = %v", err, num_rows, len(rows))
+		err := errors.New("db_upsert_mismatched_rows_count")
+		logger.Error(location, "Failed to upsert correct number of rows. got = %v, expected = %v", err, num_rows, len(rows))
 		return err
 	}
 
-	logger.Info(location, "Successfully inserted %v rows", len(rows))
+	logger.Info(location, "Successfully upserted %v rows", len(rows))
 	return nil
 }
+
+func (o *OrderDB) GetOrdersCreatedForDay(day time.Time) ([]*Order, error) {
+	location := "db.GetOrdersCreatedForDay"
+	var rows []*Order
+	_, err := o.db.From(Order{}.TableName()).Select("*", "", false).Eq("createdForDay", day.Format(time.RFC3339)).ExecuteTo(&rows)
+	if err != nil {
+		logger.Error(location, "Failed to get rows for day %v", err, day)
+		return nil, err
+	}
+
+	logger.Info(location, "Found %v rows for day %v", len(rows), day)
+	return rows, nil
+}
+
+// Orders created for the day or after
+func (o *OrderDB) GetOrdersCreatedSince(day time.Time) ([]*Order, error) {
+	location := "db.GetOrdersCreatedSince"
+	var rows []*Order
+	_, err := o.db.From(Order{}.TableName()).Select("*", "", false).Gte("createdForDay", day.Format(time.RFC3339)).ExecuteTo(&rows)
+	if err != nil {
+		logger.Error(location, "Failed to get rows since day %v", err, day)
+		return nil, err
+	}
+
+	logger.Info(location, "Found %v rows since day %v", len(rows), day)
+	return rows, nil
+}
+
+// Rows are unique on (ticker, createdForDay)
+func (o *OrderDB) BulkUpsertCarryForwards(rows []*CarryForward) error {
+	location := "db.BulkUpsertCarryForwards"
+	_, num_rows, err := o.db.From(CarryForward{}.TableName()).Upsert(rows, carryForwardUniqueColumns, "minimal", "exact").Execute()
+	if err != nil {
+		logger.Error(location, "Failed to upsert rows: %v", err)
+		return err
+	} else if num_rows != int64(len(rows)) {
+		err := errors.New("db_upsert_mismatched_rows_count")
+		logger.Error(location, "Failed to upsert correct number of rows. got = %v, expected = %v", err, num_rows, len(rows))
+		return err
+	}
+
+	logger.Info(location, "Successfully upserted %v rows", len(rows))
+	return nil
+}
+
+// Carry forwards created for the day or after, which are not fully spent yet. Ordered from oldest
+func (o *OrderDB) GetRemainingCarryForwardsSince(day time.Time) ([]*CarryForward, error) {
+	location := "db.GetRemainingCarryForwardsSince"
+	var rows []*CarryForward
+	_, err := o.db.From(CarryForward{}.TableName()).Select("*", "", false).
+		Gte("createdForDay", day.Format(time.RFC3339)).
+		Gt("remaining", "0").
+		Order("createdForDay", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&rows)
+	if err != nil {
+		logger.Error(location, "Failed to get rows since day %v", err, day)
+		return nil, err
+	}
+
+	logger.Info(location, "Found %v rows since day %v", len(rows), day)
+	return rows, nil
+}
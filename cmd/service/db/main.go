@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 	supabase "github.com/supabase-community/supabase-go"
@@ -8,7 +10,11 @@ import (
 
 //go:generate mockgen -source=cmd/service/db/main.go -destination=mocks/mock_OrderRepository.go -package=mocks
 type OrderRepository interface {
-	BulkInsert(rows []*Order) error
+	BulkUpsert(rows []*Order) error
+	GetOrdersCreatedForDay(day time.Time) ([]*Order, error)
+	GetOrdersCreatedSince(day time.Time) ([]*Order, error)
+	BulkUpsertCarryForwards(rows []*CarryForward) error
+	GetRemainingCarryForwardsSince(day time.Time) ([]*CarryForward, error)
 	// GetDB() *gorm.DB
 	GetDB() *supabase.Client
 }
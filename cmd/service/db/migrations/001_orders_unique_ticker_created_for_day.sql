@@ -0,0 +1,10 @@
+-- Allows daily runs to upsert on (ticker, createdForDay) instead of appending duplicate rows.
+-- Remove existing duplicates before applying, keeping the earliest row per ticker per day.
+DELETE FROM "Orders" a
+USING "Orders" b
+WHERE a."ticker" = b."ticker"
+  AND a."createdForDay" = b."createdForDay"
+  AND a.ctid > b.ctid;
+
+ALTER TABLE "Orders"
+  ADD CONSTRAINT "Orders_ticker_createdForDay_key" UNIQUE ("ticker", "createdForDay");
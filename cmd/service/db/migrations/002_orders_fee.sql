@@ -0,0 +1,3 @@
+-- Actual trading fee of the day's orders, as per the trade history
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "fee" DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "feeCurrency" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Fiat amount of a ticker left unspent on a day, spent on the following days oldest first.
+CREATE TABLE IF NOT EXISTS "CarryForwards" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "ticker" TEXT NOT NULL,
+  "createdForDay" TIMESTAMPTZ NOT NULL,
+  "amount" DOUBLE PRECISION NOT NULL,
+  "remaining" DOUBLE PRECISION NOT NULL,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updatedAt" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT "CarryForwards_ticker_createdForDay_key" UNIQUE ("ticker", "createdForDay")
+);
@@ -0,0 +1,7 @@
+-- Orders of every quote currency, not only SGD, are recorded, so the fiat columns are no longer named after SGD.
+ALTER TABLE "Orders" RENAME COLUMN "fiatDepositInSgd" TO "fiatDeposit";
+ALTER TABLE "Orders" RENAME COLUMN "pricePerCoinInSgd" TO "pricePerCoin";
+
+-- Quote currency of the trading pair, backfilled from the ticker, e.g. btcsgd.
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "quoteCurrency" TEXT NOT NULL DEFAULT '';
+UPDATE "Orders" SET "quoteCurrency" = UPPER(RIGHT("ticker", 3)) WHERE "quoteCurrency" = '';
@@ -0,0 +1,9 @@
+-- Fiat deposit converted into the reporting currency, so that spend across quote currencies can be summed.
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "reportingCurrency" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "fxRate" DOUBLE PRECISION NOT NULL DEFAULT 1;
+ALTER TABLE "Orders" ADD COLUMN IF NOT EXISTS "fiatDepositInReportingCurrency" DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Existing orders are reported in their quote currency.
+UPDATE "Orders"
+SET "reportingCurrency" = "quoteCurrency", "fxRate" = 1, "fiatDepositInReportingCurrency" = "fiatDeposit"
+WHERE "reportingCurrency" = '';
@@ -0,0 +1,16 @@
+-- Progress of every ticker's order loop, so that a crashed run can be resumed on a fresh dyno.
+CREATE TABLE IF NOT EXISTS "Journal" (
+  "ticker" TEXT PRIMARY KEY,
+  "createdForDay" TIMESTAMPTZ NOT NULL,
+  "orderId" TEXT NOT NULL DEFAULT '',
+  "clientOrderId" TEXT NOT NULL DEFAULT '',
+  "orderOpenThenCancelWindowCounter" INTEGER NOT NULL DEFAULT 0,
+  "orderOpenQueryStatusWindowCounter" INTEGER NOT NULL DEFAULT 0,
+  "executedAmount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+  "fiatSpent" DOUBLE PRECISION NOT NULL DEFAULT 0,
+  "orderIds" JSONB,
+  "feeAmount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+  "feeCurrency" TEXT NOT NULL DEFAULT '',
+  "isCompleted" BOOLEAN NOT NULL DEFAULT FALSE,
+  "updatedAt" TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
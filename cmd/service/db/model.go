@@ -2,16 +2,44 @@ package db
 
 import "time"
 
+// Unique constraints of the tables, see migrations
+const (
+	orderUniqueColumns        = "ticker,createdForDay"
+	carryForwardUniqueColumns = "ticker,createdForDay"
+)
+
 type Order struct {
-	Ticker            string    `json:"ticker"`
-	CreatedForDay     time.Time `json:"createdForDay"`
-	FiatDepositInSGD  float64   `json:"fiatDepositInSgd"`  // legacy issue: could also be in other fiat curreny (i.e. USD)
-	PricePerCoinInSGD float64   `json:"pricePerCoinInSgd"` // legacy issue: could also be in other fiat curreny (i.e. USD)
-	CoinAmount        float64   `json:"coinAmount"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	Ticker                         string    `json:"ticker"`
+	CreatedForDay                  time.Time `json:"createdForDay"`
+	QuoteCurrency                  string    `json:"quoteCurrency"`
+	FiatDeposit                    float64   `json:"fiatDeposit"`  // in QuoteCurrency
+	PricePerCoin                   float64   `json:"pricePerCoin"` // in QuoteCurrency
+	CoinAmount                     float64   `json:"coinAmount"`
+	Fee                            float64   `json:"fee"`
+	FeeCurrency                    string    `json:"feeCurrency"`
+	ReportingCurrency              string    `json:"reportingCurrency"`
+	FxRate                         float64   `json:"fxRate"`                         // of QuoteCurrency to ReportingCurrency
+	FiatDepositInReportingCurrency float64   `json:"fiatDepositInReportingCurrency"` // FiatDeposit at FxRate
+	CreatedAt                      time.Time `json:"createdAt"`
+	UpdatedAt                      time.Time `json:"updatedAt"`
 }
 
 func (Order) TableName() string {
 	return "Orders"
 }
+
+// Fiat amount of a ticker left unspent on a day, to be spent on the following days
+//
+// Remaining is what is left of Amount after being spent on the following days, oldest first
+type CarryForward struct {
+	Ticker        string    `json:"ticker"`
+	CreatedForDay time.Time `json:"createdForDay"`
+	Amount        float64   `json:"amount"`
+	Remaining     float64   `json:"remaining"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func (CarryForward) TableName() string {
+	return "CarryForwards"
+}
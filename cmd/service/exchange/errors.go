@@ -0,0 +1,24 @@
+package exchange
+
+import (
+	"errors"
+)
+
+// Classes of errors shared by every exchange, to be checked with errors.Is. The errors of every Exchange wrap one of
+// them where applicable, e.g. errors.Is(err, ErrInsufficientFunds) for an order rejected for insufficient funds
+var (
+	ErrInsufficientFunds = errors.New("exchange_insufficient_funds")
+	ErrAuth              = errors.New("exchange_auth")
+	ErrRejected          = errors.New("exchange_rejected") // rejected for any other reason, e.g. a malformed request
+)
+
+// Whether the exchange rejected the request outright, i.e. the request certainly had no effect, e.g. no order was
+// created. Server errors, maintenance & network errors are ambiguous instead
+func IsRejected(err error) bool {
+	for _, class := range []error{ErrInsufficientFunds, ErrAuth, ErrRejected} {
+		if errors.Is(err, class) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package exchange
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the transport of every request to an exchange, e.g. for logging, metrics or recording
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Resolved on every request rather than when the client is built, so that http.DefaultTransport can still be swapped,
+// e.g. by httpmock
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Copy of the client, an empty one if nil, with the middlewares wrapping its transport in the given order, i.e. the first
+// middleware sees the request first
+func WrapClient(client *http.Client, middlewares []Middleware) *http.Client {
+	wrapped := &http.Client{}
+	if client != nil {
+		*wrapped = *client
+	}
+	transport := wrapped.Transport
+	if transport == nil {
+		transport = defaultTransport{}
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	wrapped.Transport = transport
+	return wrapped
+}
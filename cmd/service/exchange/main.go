@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+)
+
+// Trading api of an exchange, every ticker is bought on the exchange configured for it, see config.Config.GetExchange
+//
+// quoteIncrement & tickSize are the number of decimal places of the order price & amount respectively. Requests are
+// abandoned once ctx is done
+type Exchange interface {
+	GetMakerTradingFee() float64
+	GetQuoteIncrementAndTickSize(ctx context.Context, ticker string) (int, int, error)
+	GetMinOrderSize(ctx context.Context, ticker string) (float64, error)
+	GetTickerBestBidPrice(ctx context.Context, ticker string) (float64, error)
+	GetTickerBestBidAskPrice(ctx context.Context, ticker string) (float64, float64, error)
+	GetOrderBook(ctx context.Context, ticker string) (*OrderBook, error)
+	CreateOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*Order, error)
+	CreateImmediateOrCancelOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*Order, error)
+	MatchActiveOrders(ctx context.Context, ticker, clientOrderID string) (*Order, error)
+	HasFilledBuyOrderSince(ctx context.Context, ticker string, since time.Time) (bool, error)
+	GetOrderTrades(ctx context.Context, ticker string, orderIDs []string, since time.Time) ([]*Trade, error)
+	GetOrderStatus(ctx context.Context, orderID string) (*Order, error)
+	CancelOrder(ctx context.Context, orderID string) (*Order, error)
+	GetAvailableBalances(ctx context.Context) (map[string]float64, error)
+}
+
+// Optionally implemented by an Exchange that pushes updates of orders, so that the order status need not be polled
+type OrderEventsSource interface {
+	// Updates of the order, starting with its latest update if it was received before subscribing, until unsubscribed
+	SubscribeOrderEvents(orderID string) (<-chan *Order, func())
+	// Since when updates have been received without interruption, false if they are not being received
+	OrderEventsConnectedSince() (time.Time, bool)
+}
+
+// Optionally implemented by an Exchange that streams market data, so that a live order can be repriced as soon as the
+// market moves
+type MarketDataSource interface {
+	// Best bid of the ticker whenever it changes, starting with its current best bid if known, until unsubscribed
+	SubscribeBestBid(ticker string) (<-chan float64, func())
+}
+
+// Keyed by exchange name, e.g. config.ExchangeGemini. Set by the client of every exchange on init
+var exchanges = make(map[string]Exchange)
+
+// Exchange the ticker is bought on
+func Get(ticker string) Exchange {
+	return GetByName(config.Get().GetExchange(ticker))
+}
+
+func GetByName(name string) Exchange {
+	return exchanges[name]
+}
+
+func Set(name string, e Exchange) {
+	exchanges[name] = e
+}
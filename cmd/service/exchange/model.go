@@ -0,0 +1,54 @@
+package exchange
+
+import "time"
+
+// Models are shared by every exchange. Json tags are of the Gemini api, which other exchanges' responses are mapped to
+
+type OrderBook struct {
+	Bids []OrderBookEntry `json:"bids"`
+	Asks []OrderBookEntry `json:"asks"`
+}
+
+type OrderBookEntry struct {
+	Price  float64 `json:"price,string"`
+	Amount float64 `json:"amount,string"`
+}
+
+type Order struct {
+	OrderID           string   `json:"order_id"`
+	ClientOrderID     string   `json:"client_order_id"`
+	Symbol            string   `json:"symbol"`
+	Exchange          string   `json:"exchange"`
+	Price             float64  `json:"price,string"`
+	AvgExecutionPrice float64  `json:"avg_execution_price,string"`
+	Side              string   `json:"side"`
+	Type              string   `json:"type"`
+	Options           []string `json:"options"`
+	Timestamp         string   `json:"timestamp"`
+	Timestampms       int64    `json:"timestampms"`
+	IsLive            bool     `json:"is_live"`
+	IsCancelled       bool     `json:"is_cancelled"`
+	Reason            string   `json:"reason"`
+	WasForced         bool     `json:"was_forced"`
+	ExecutedAmount    float64  `json:"executed_amount,string"`
+	RemainingAmount   float64  `json:"remaining_amount,string"`
+	OriginalAmount    float64  `json:"original_amount,string"`
+	IsHidden          bool     `json:"is_hidden"`
+}
+
+type Trade struct {
+	Timestamp     int64     `json:"timestamp"`
+	Timestampms   int64     `json:"timestampms"`
+	TimestampmsT  time.Time `json:"timestampmst,omitempty"`
+	TradeID       int64     `json:"tid"`
+	OrderID       string    `json:"order_id"`
+	ClientOrderID string    `json:"client_order_id"`
+	Price         float64   `json:"price,string"`
+	Amount        float64   `json:"amount,string"`
+	Exchange      string    `json:"exchange"`
+	Type          string    `json:"type"`
+	IsAggressor   bool      `json:"aggressor"` // filled as taker
+	FeeCurrency   string    `json:"fee_currency"`
+	FeeAmount     float64   `json:"fee_amount,string"`
+	Broken        bool      `json:"broken,omitempty"`
+}
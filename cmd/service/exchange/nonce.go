@@ -0,0 +1,108 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Nonces of private requests of an exchange, shared by every ticker bought on it
+type NonceProvider interface {
+	Next() (int64, error)
+}
+
+// Strictly increasing across goroutines, and across restarts & the clock going backwards as its high-water mark is
+// persisted to a json file. Nonces are the current time in nanoseconds, bumped past the last nonce if need be
+//
+// The file is only as durable as the filesystem it is on, e.g. it is lost on every restart of a Heroku dyno. Nonces
+// then start from the current time again, which is only rejected if the clock has gone backwards since
+type IncreasingNonceProvider struct {
+	path string
+	mu   sync.Mutex
+	last int64
+}
+
+type nonceHighWaterMark struct {
+	Nonce int64 `json:"nonce"`
+}
+
+// A missing file starts from the current time
+func NewIncreasingNonceProvider(path string) (*IncreasingNonceProvider, error) {
+	location := "exchange.NewIncreasingNonceProvider"
+	p := &IncreasingNonceProvider{path: path}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		logger.Error(location, "Failed to read nonce file '%s'", err, path)
+		return nil, err
+	}
+	mark := &nonceHighWaterMark{}
+	if err := json.Unmarshal(b, mark); err != nil {
+		logger.Error(location, "Failed to unmarshal nonce file '%s'", err, path)
+		return nil, err
+	}
+	p.last = mark.Nonce
+	return p, nil
+}
+
+// The nonce is persisted before it is returned, so that it is never reused even if the process crashes right after
+func (p *IncreasingNonceProvider) Next() (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nonce := max(time.Now().UnixNano(), p.last+1)
+	if err := p.write(nonce); err != nil {
+		return 0, err
+	}
+	p.last = nonce
+	return nonce, nil
+}
+
+// Written atomically, so that a crash mid-write cannot corrupt the high-water mark
+func (p *IncreasingNonceProvider) write(nonce int64) error {
+	location := "exchange.IncreasingNonceProvider.write"
+	b, err := json.Marshal(&nonceHighWaterMark{Nonce: nonce})
+	if err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomic(p.path, b); err != nil {
+		logger.Error(location, "Failed to write nonce file '%s'", err, p.path)
+		return err
+	}
+	return nil
+}
+
+// Sends private requests in the order their nonces are taken, as an exchange rejects a nonce lower than one it has
+// already seen. The next nonce is only taken once the request of the previous one is written
+type NonceOrder struct {
+	mu sync.Mutex
+}
+
+// Takes the next nonce with next, returns ctx to send its request with, which lets the next nonce be taken once the
+// request is written. release must be called once the request is done, in case it is never written
+func (o *NonceOrder) Take(ctx context.Context, next func() (int64, error)) (int64, context.Context, func(), error) {
+	o.mu.Lock()
+	nonce, err := next()
+	if err != nil {
+		o.mu.Unlock()
+		return 0, ctx, func() {}, err
+	}
+	var once sync.Once
+	release := func() {
+		once.Do(o.mu.Unlock)
+	}
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			release()
+		},
+	})
+	return nonce, ctx, release, nil
+}
@@ -0,0 +1,139 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncreasingNonceProvider(t *testing.T) {
+	t.Run("strictly_increasing_across_goroutines", func(t *testing.T) {
+		p, err := NewIncreasingNonceProvider(filepath.Join(t.TempDir(), "nonce.json"))
+		assert.NoError(t, err)
+
+		var mu sync.Mutex
+		var nonces []int64
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 10; k++ {
+					nonce, err := p.Next()
+					assert.NoError(t, err)
+					mu.Lock()
+					nonces = append(nonces, nonce)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		sort.Slice(nonces, func(i, k int) bool { return nonces[i] < nonces[k] })
+		for i := 1; i < len(nonces); i++ {
+			assert.Less(t, nonces[i-1], nonces[i])
+		}
+	})
+
+	t.Run("resumes_from_high_water_mark", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nonce.json")
+		// e.g. the clock has gone backwards since the last run
+		future := time.Now().Add(time.Hour).UnixNano()
+		assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`{"nonce":%d}`, future)), 0o644))
+
+		p, err := NewIncreasingNonceProvider(path)
+		assert.NoError(t, err)
+		nonce, err := p.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, future+1, nonce)
+
+		p, err = NewIncreasingNonceProvider(path)
+		assert.NoError(t, err)
+		nonce, err = p.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, future+2, nonce)
+	})
+
+	t.Run("error_corrupt_file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nonce.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+		_, err := NewIncreasingNonceProvider(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestNonceOrder_Take(t *testing.T) {
+	o := &NonceOrder{}
+	var last int64
+	next := func() (int64, error) {
+		last++
+		return last, nil
+	}
+
+	t.Run("next_nonce_waits_for_release", func(t *testing.T) {
+		_, _, release, err := o.Take(context.Background(), next)
+		assert.NoError(t, err)
+
+		taken := make(chan int64)
+		go func() {
+			nonce, _, release, _ := o.Take(context.Background(), next)
+			release()
+			taken <- nonce
+		}()
+		select {
+		case <-taken:
+			assert.Fail(t, "next nonce is taken before the previous one is released")
+		case <-time.After(20 * time.Millisecond):
+		}
+		release()
+		release() // idempotent
+		assert.Equal(t, last, <-taken)
+	})
+
+	t.Run("next_nonce_taken_once_written", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the request is written, though not done yet
+			taken := make(chan struct{})
+			go func() {
+				_, _, release, _ := o.Take(context.Background(), next)
+				release()
+				close(taken)
+			}()
+			select {
+			case <-taken:
+			case <-time.After(time.Second):
+				assert.Fail(t, "next nonce is not taken once the previous request is written")
+			}
+		}))
+		defer server.Close()
+
+		_, ctx, release, err := o.Take(context.Background(), next)
+		assert.NoError(t, err)
+		defer release()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+		assert.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, _, release, err := o.Take(context.Background(), func() (int64, error) { return 0, errors.New("error") })
+		assert.Error(t, err)
+		release()
+		// not left held
+		_, _, release, err = o.Take(context.Background(), next)
+		assert.NoError(t, err)
+		release()
+	})
+}
@@ -0,0 +1,153 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// PricingStrategy decides the limit price of a buy order, and is queried once per order window
+type PricingStrategy interface {
+	GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error)
+}
+
+// Selects the strategy configured for the ticker, defaulting to the bid ratio strategy
+func NewPricingStrategy(e Exchange, ticker string) PricingStrategy {
+	orderMetadata := config.Get().OrderMetadata
+	strategy := orderMetadata.PricingStrategies[ticker]
+	switch strategy.Name {
+	case config.PricingStrategyMidPrice:
+		return &midPriceStrategy{e: e}
+	case config.PricingStrategyAskMinusTicks:
+		return &askMinusTicksStrategy{e: e, ticks: strategy.Ticks}
+	case config.PricingStrategyBookDepth:
+		return &bookDepthStrategy{e: e, depth: strategy.Depth}
+	default:
+		return &bidRatioStrategy{e: e, ratio: orderMetadata.OrderPriceToBidPriceRatio}
+	}
+}
+
+// Strategy of the fallbackWindow-th order window (1-based) since the ticker switched to its fallback strategy,
+// nil if the fallback strategy does not place orders
+func NewFallbackPricingStrategy(e Exchange, ticker string, fallbackWindow int) PricingStrategy {
+	strategy := config.Get().OrderMetadata.FallbackStrategies[ticker]
+	switch strategy.Name {
+	case config.FallbackStrategyIocAtAsk:
+		return &cappedAskStrategy{e: e, maxPremium: strategy.MaxPremium}
+	case config.FallbackStrategyAggressiveLimit:
+		return &bidPremiumStrategy{e: e, premium: math.Min(float64(fallbackWindow)*strategy.Step, strategy.MaxPremium)}
+	default:
+		return nil
+	}
+}
+
+// Best bid * ratio
+type bidRatioStrategy struct {
+	e     Exchange
+	ratio float64
+}
+
+func (s *bidRatioStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	bestBid, err := s.e.GetTickerBestBidPrice(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	return bestBid * s.ratio, nil
+}
+
+// Mid of best bid and best ask
+type midPriceStrategy struct {
+	e Exchange
+}
+
+func (s *midPriceStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	bestBid, bestAsk, err := s.e.GetTickerBestBidAskPrice(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	return (bestBid + bestAsk) / 2, nil
+}
+
+// Best ask less N price increments, where one increment is the smallest price step of the symbol
+type askMinusTicksStrategy struct {
+	e     Exchange
+	ticks int
+}
+
+func (s *askMinusTicksStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	location := "exchange.askMinusTicksStrategy.GetOrderPrice"
+	_, bestAsk, err := s.e.GetTickerBestBidAskPrice(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	orderPrice := bestAsk - float64(s.ticks)*math.Pow10(-quoteIncrement)
+	if orderPrice <= 0 {
+		err := errors.New("invalid_order_price")
+		logger.Error(location, "ticker: %s, bestAsk: %v, ticks: %v", err, ticker, bestAsk, s.ticks)
+		return 0, err
+	}
+	return orderPrice, nil
+}
+
+// Walks down the bids and joins the first level where the resting notional ahead of the order
+// reaches depth * fiatAmount. A thicker book yields a lower price, a thinner book stays near the best bid.
+// A book too shallow to ever reach that notional joins the best bid, rather than the deepest bid fetched
+type bookDepthStrategy struct {
+	e     Exchange
+	depth float64
+}
+
+func (s *bookDepthStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	location := "exchange.bookDepthStrategy.GetOrderPrice"
+	orderBook, err := s.e.GetOrderBook(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	if len(orderBook.Bids) == 0 {
+		err := errors.New("empty_order_book")
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, err
+	}
+
+	targetNotional := s.depth * fiatAmount
+	cumulativeNotional := float64(0)
+	for _, bid := range orderBook.Bids {
+		cumulativeNotional += bid.Price * bid.Amount
+		if cumulativeNotional >= targetNotional {
+			return bid.Price, nil
+		}
+	}
+	logger.Warn(location, "'%s' Resting notional of %v is short of %v, joining the best bid", ticker, cumulativeNotional, targetNotional)
+	return orderBook.Bids[0].Price, nil
+}
+
+// Best ask, capped at best bid * (1 + maxPremium)
+type cappedAskStrategy struct {
+	e          Exchange
+	maxPremium float64
+}
+
+func (s *cappedAskStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	bestBid, bestAsk, err := s.e.GetTickerBestBidAskPrice(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	return math.Min(bestAsk, bestBid*(1+s.maxPremium)), nil
+}
+
+// Best bid * (1 + premium)
+type bidPremiumStrategy struct {
+	e       Exchange
+	premium float64
+}
+
+func (s *bidPremiumStrategy) GetOrderPrice(ctx context.Context, ticker string, fiatAmount float64, quoteIncrement int) (float64, error) {
+	bestBid, err := s.e.GetTickerBestBidPrice(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	return bestBid * (1 + s.premium), nil
+}
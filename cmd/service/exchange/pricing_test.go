@@ -0,0 +1,182 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Serves the same market data for every ticker, every other method of Exchange is left unimplemented
+type fakeExchange struct {
+	Exchange
+	bid, ask  float64
+	orderBook *OrderBook
+	err       error
+}
+
+func (e *fakeExchange) GetTickerBestBidPrice(ctx context.Context, ticker string) (float64, error) {
+	return e.bid, e.err
+}
+
+func (e *fakeExchange) GetTickerBestBidAskPrice(ctx context.Context, ticker string) (float64, float64, error) {
+	return e.bid, e.ask, e.err
+}
+
+func (e *fakeExchange) GetOrderBook(ctx context.Context, ticker string) (*OrderBook, error) {
+	return e.orderBook, e.err
+}
+
+func TestNewPricingStrategy(t *testing.T) {
+	e := &fakeExchange{}
+	config.TestInit(&config.ConfigUpdateable{
+		PricingStrategies: map[string]config.PricingStrategy{
+			"ETH": {Name: config.PricingStrategyAskMinusTicks, Ticks: 2},
+		},
+	}, nil)
+
+	t.Run("default_bid_ratio", func(t *testing.T) {
+		got := NewPricingStrategy(e, "BTC")
+		assert.Equal(t, &bidRatioStrategy{e: e, ratio: 0.999}, got)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		got := NewPricingStrategy(e, "ETH")
+		assert.Equal(t, &askMinusTicksStrategy{e: e, ticks: 2}, got)
+	})
+}
+
+func TestNewFallbackPricingStrategy(t *testing.T) {
+	e := &fakeExchange{}
+	config.TestInit(&config.ConfigUpdateable{
+		FallbackStrategies: map[string]config.FallbackStrategy{
+			"BTC": {Name: config.FallbackStrategyAggressiveLimit, AfterWindows: 10, Step: 0.001, MaxPremium: 0.0025},
+			"ETH": {Name: config.FallbackStrategyIocAtAsk, AfterWindows: 20, MaxPremium: 0.01},
+			"SOL": {Name: config.FallbackStrategySkipAndCarry, AfterWindows: 5},
+		},
+	}, nil)
+
+	t.Run("aggressive_limit", func(t *testing.T) {
+		assert.Equal(t, &bidPremiumStrategy{e: e, premium: 0.002}, NewFallbackPricingStrategy(e, "BTC", 2))
+	})
+
+	t.Run("aggressive_limit_capped", func(t *testing.T) {
+		assert.Equal(t, &bidPremiumStrategy{e: e, premium: 0.0025}, NewFallbackPricingStrategy(e, "BTC", 3))
+	})
+
+	t.Run("ioc_at_ask", func(t *testing.T) {
+		assert.Equal(t, &cappedAskStrategy{e: e, maxPremium: 0.01}, NewFallbackPricingStrategy(e, "ETH", 1))
+	})
+
+	t.Run("skip_and_carry", func(t *testing.T) {
+		assert.Nil(t, NewFallbackPricingStrategy(e, "SOL", 1))
+	})
+
+	t.Run("not_configured", func(t *testing.T) {
+		assert.Nil(t, NewFallbackPricingStrategy(e, "DOGE", 1))
+	})
+}
+
+func TestPricingStrategy_GetOrderPrice(t *testing.T) {
+	e := &fakeExchange{bid: 1000, ask: 1002}
+
+	type args struct {
+		ticker         string
+		fiatAmount     float64
+		quoteIncrement int
+	}
+	tests := []struct {
+		name     string
+		strategy PricingStrategy
+		args     args
+		want     float64
+		wantErr  bool
+	}{
+		{
+			name:     "ok_bid_ratio",
+			strategy: &bidRatioStrategy{e: e, ratio: 0.999},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want:     999,
+		},
+		{
+			name:     "error_bid_ratio",
+			strategy: &bidRatioStrategy{e: &fakeExchange{err: errors.New("unavailable")}, ratio: 0.999},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			wantErr:  true,
+		},
+		{
+			name:     "ok_mid_price",
+			strategy: &midPriceStrategy{e: e},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want:     1001,
+		},
+		{
+			name:     "ok_ask_minus_ticks",
+			strategy: &askMinusTicksStrategy{e: e, ticks: 3},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 0},
+			want:     999,
+		},
+		{
+			name:     "error_ask_minus_ticks_non_positive_price",
+			strategy: &askMinusTicksStrategy{e: e, ticks: 2000},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 0},
+			wantErr:  true,
+		},
+		{
+			name:     "ok_capped_ask",
+			strategy: &cappedAskStrategy{e: e, maxPremium: 0.01},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want:     1002,
+		},
+		{
+			name:     "ok_capped_ask_wide_spread",
+			strategy: &cappedAskStrategy{e: e, maxPremium: 0.001},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want:     1001,
+		},
+		{
+			name:     "ok_bid_premium",
+			strategy: &bidPremiumStrategy{e: e, premium: 0.002},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want:     1002,
+		},
+		{
+			name: "ok_book_depth",
+			strategy: &bookDepthStrategy{e: &fakeExchange{orderBook: &OrderBook{
+				Bids: []OrderBookEntry{{Price: 1000, Amount: 0.01}, {Price: 999, Amount: 0.03}, {Price: 998, Amount: 1}},
+				Asks: []OrderBookEntry{{Price: 1002, Amount: 1}},
+			}}, depth: 5},
+			args: args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want: 998,
+		},
+		{
+			name: "ok_book_depth_shallow_book",
+			strategy: &bookDepthStrategy{e: &fakeExchange{orderBook: &OrderBook{
+				Bids: []OrderBookEntry{{Price: 1000, Amount: 0.01}, {Price: 999, Amount: 0.03}},
+				Asks: []OrderBookEntry{{Price: 1002, Amount: 1}},
+			}}, depth: 1000},
+			args: args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			want: 1000,
+		},
+		{
+			name:     "error_book_depth_empty_book",
+			strategy: &bookDepthStrategy{e: &fakeExchange{orderBook: &OrderBook{}}, depth: 5},
+			args:     args{ticker: "BTC", fiatAmount: 10, quoteIncrement: 2},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.strategy.GetOrderPrice(util.TestContext(), tt.args.ticker, tt.args.fiatAmount, tt.args.quoteIncrement)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+)
+
+// Trading pair of the ticker as configured, e.g. btcsgd. Also identifies the ticker in the db
+func Pair(ticker string) string {
+	return strings.ToLower(ticker + config.Get().GetQuoteCurrency(ticker))
+}
+
+// Unique to the run, so that an order of a previous run of the same day is never matched, e.g. 20241101_143000_btcsgd_w1_a0
+// for the first attempt of the first order window of the run started at 14:30:00. The same arguments always form the
+// same id, so that an order whose creation failed ambiguously can be matched by it
+//
+// runStartedAt is set once per order loop, window is the order open then cancel window, and attempt counts the orders
+// re-created within the window
+func FormClientOrderID(ticker string, runStartedAt time.Time, window, attempt int) string {
+	return fmt.Sprintf("%s_%s_w%d_a%d", runStartedAt.Format("20060102_150405"), Pair(ticker), window, attempt)
+}
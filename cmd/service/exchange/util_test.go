@@ -0,0 +1,21 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormClientOrderID(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "SGD", "ETH": "SGD", "SOL": "USD"}}, nil)
+
+	runStartedAt := time.Date(2024, 11, 1, 14, 30, 0, 0, time.UTC)
+	assert.Equal(t, "20241101_143000_btcsgd_w1_a0", FormClientOrderID("BTC", runStartedAt, 1, 0))
+	assert.Equal(t, "20241101_143000_solusd_w3_a2", FormClientOrderID("SOL", runStartedAt, 3, 2))
+	// same within the run, to be matched after an ambiguous failure
+	assert.Equal(t, FormClientOrderID("ETH", runStartedAt, 2, 1), FormClientOrderID("ETH", runStartedAt, 2, 1))
+	// not across reruns of the same day
+	assert.NotEqual(t, FormClientOrderID("ETH", runStartedAt, 2, 1), FormClientOrderID("ETH", runStartedAt.Add(time.Hour), 2, 1))
+}
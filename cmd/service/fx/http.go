@@ -0,0 +1,101 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Rates are refreshed once stale, as the daemon stays alive across days
+const cacheTTL = time.Hour
+
+type cachedRates struct {
+	rates     map[string]float64
+	fetchedAt time.Time
+}
+
+// Latest rates of an open.er-api.com compatible api, i.e. GET {url}/{from} returns the rates of from, cached per from
+type HttpProvider struct {
+	url   string
+	mu    sync.Mutex
+	cache map[string]*cachedRates
+}
+
+func NewHttpProvider(url string) *HttpProvider {
+	return &HttpProvider{url: url, cache: make(map[string]*cachedRates)}
+}
+
+func (p *HttpProvider) GetRate(ctx context.Context, from, to string) (float64, error) {
+	location := "fx.HttpProvider.GetRate"
+	if from == to {
+		return 1, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cached, ok := p.cache[from]
+	if !ok || time.Now().Sub(cached.fetchedAt) > cacheTTL {
+		rates, err := p.latestRates(ctx, from)
+		if err != nil {
+			logger.Error(location, "from: %s, to: %s", err, from, to)
+			return 0, err
+		}
+		cached = &cachedRates{rates: rates, fetchedAt: time.Now()}
+		p.cache[from] = cached
+	}
+
+	rate, ok := cached.rates[to]
+	if !ok || rate <= 0 {
+		err := fmt.Errorf("fx_rate_not_found: %s/%s", from, to)
+		logger.Error(location, "from: %s, to: %s", err, from, to)
+		return 0, err
+	}
+	return rate, nil
+}
+
+func (p *HttpProvider) latestRates(ctx context.Context, base string) (map[string]float64, error) {
+	location := "fx.HttpProvider.latestRates"
+	reqURL := p.url + "/" + base
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "request url:%s", reqURL)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP Status Code: %d", resp.StatusCode)
+	}
+
+	latestRates := &LatestRatesResponse{}
+	if err := json.Unmarshal(body, latestRates); err != nil {
+		return nil, err
+	}
+	if latestRates.Result != "success" {
+		return nil, fmt.Errorf("fx_api_error: %s", latestRates.ErrorType)
+	}
+
+	logger.Info(location, "base: %s, rates: %d", latestRates.BaseCode, len(latestRates.Rates))
+
+	return latestRates.Rates, nil
+}
@@ -0,0 +1,60 @@
+package fx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpProvider_GetRate(t *testing.T) {
+	config.TestInit(nil, nil)
+	ctx := util.TestContext()
+	calls := 0
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/v6/latest/USD":
+			fmt.Fprint(w, `{"result":"success","base_code":"USD","rates":{"USD":1,"SGD":1.35}}`)
+		case "/v6/latest/XYZ":
+			fmt.Fprint(w, `{"result":"error","error-type":"unsupported-code"}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer stub.Close()
+	p := NewHttpProvider(stub.URL + "/v6/latest")
+
+	t.Run("ok", func(t *testing.T) {
+		got, err := p.GetRate(ctx, "USD", "SGD")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.35, got)
+	})
+
+	t.Run("ok_cached", func(t *testing.T) {
+		before := calls
+		got, err := p.GetRate(ctx, "USD", "SGD")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.35, got)
+		assert.Equal(t, before, calls)
+	})
+
+	t.Run("error_rate_not_found", func(t *testing.T) {
+		_, err := p.GetRate(ctx, "USD", "EUR")
+		assert.Error(t, err)
+	})
+
+	t.Run("error_api_error", func(t *testing.T) {
+		_, err := p.GetRate(ctx, "XYZ", "SGD")
+		assert.Error(t, err)
+	})
+
+	t.Run("error_status_code", func(t *testing.T) {
+		_, err := p.GetRate(ctx, "EUR", "SGD")
+		assert.Error(t, err)
+	})
+}
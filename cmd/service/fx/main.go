@@ -0,0 +1,55 @@
+package fx
+
+import (
+	"context"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Source of FX rates, as the amount of to for 1 unit of from, e.g. 1.35 for USD to SGD
+type RateProvider interface {
+	GetRate(ctx context.Context, from, to string) (float64, error)
+}
+
+var provider RateProvider
+
+// Without a reporting currency, spend is reported in the quote currency of each ticker and no rate is needed
+func MustInit() {
+	location := "fx.MustInit"
+	c := config.Get().Fx
+	switch c.RateProvider {
+	case config.FxRateProviderStatic:
+		provider = NewStaticProvider(c.Rates)
+	case config.FxRateProviderFile:
+		p, err := NewFileProvider(c.RatesPath)
+		if err != nil {
+			logger.Panic(location, "Failed to load FX rates from '%s'", err, c.RatesPath)
+		}
+		provider = p
+	case config.FxRateProviderHttp:
+		provider = NewHttpProvider(c.ApiUrl)
+	default:
+		provider = NewStaticProvider(nil)
+	}
+}
+
+func Get() RateProvider {
+	return provider
+}
+
+func Set(p RateProvider) {
+	provider = p
+}
+
+// Returns the converted amount & the rate used. Same currencies are not converted, without needing a provider
+func Convert(ctx context.Context, amount float64, from, to string) (float64, float64, error) {
+	if from == to {
+		return amount, 1, nil
+	}
+	rate, err := Get().GetRate(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	return amount * rate, rate, nil
+}
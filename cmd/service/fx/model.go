@@ -0,0 +1,9 @@
+package fx
+
+// Latest rates of the base currency, as returned by open.er-api.com, e.g. GET /v6/latest/USD
+type LatestRatesResponse struct {
+	Result    string             `json:"result"` // success or error
+	ErrorType string             `json:"error-type"`
+	BaseCode  string             `json:"base_code"`
+	Rates     map[string]float64 `json:"rates"`
+}
@@ -0,0 +1,51 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Fixed rates keyed by currency pair, e.g. 1.35 for USD/SGD. The inverse of a pair is derived, e.g. SGD/USD
+type StaticProvider struct {
+	rates map[string]float64
+}
+
+func NewStaticProvider(rates map[string]float64) *StaticProvider {
+	return &StaticProvider{rates: rates}
+}
+
+// Rates are read once, from a JSON file of the same format as FX_RATES, e.g. {"USD/SGD":1.35}
+func NewFileProvider(path string) (*StaticProvider, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]float64)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	rates := make(map[string]float64, len(m))
+	for currencyPair, rate := range m {
+		if _, _, ok := strings.Cut(currencyPair, "/"); !ok || rate <= 0 {
+			return nil, fmt.Errorf("invalid_fx_rate: %s %v", currencyPair, rate)
+		}
+		rates[strings.ToUpper(currencyPair)] = rate
+	}
+	return NewStaticProvider(rates), nil
+}
+
+func (p *StaticProvider) GetRate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if rate, ok := p.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[to+"/"+from]; ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("fx_rate_not_found: %s/%s", from, to)
+}
@@ -0,0 +1,80 @@
+package fx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticProvider_GetRate(t *testing.T) {
+	ctx := util.TestContext()
+	p := NewStaticProvider(map[string]float64{"USD/SGD": 1.25})
+	tests := []struct {
+		name     string
+		from, to string
+		want     float64
+		wantErr  bool
+	}{
+		{name: "ok", from: "USD", to: "SGD", want: 1.25},
+		{name: "ok_inverse", from: "SGD", to: "USD", want: 0.8},
+		{name: "ok_same_currency", from: "EUR", to: "EUR", want: 1},
+		{name: "error_not_found", from: "EUR", to: "SGD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.GetRate(ctx, tt.from, tt.to)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestNewFileProvider(t *testing.T) {
+	ctx := util.TestContext()
+	dir := t.TempDir()
+
+	t.Run("ok", func(t *testing.T) {
+		path := filepath.Join(dir, "rates.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"usd/sgd":1.35}`), 0o644))
+		p, err := NewFileProvider(path)
+		assert.NoError(t, err)
+		got, err := p.GetRate(ctx, "USD", "SGD")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.35, got)
+	})
+
+	t.Run("error_invalid_rate", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"USD/SGD":-1}`), 0o644))
+		_, err := NewFileProvider(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("error_missing_file", func(t *testing.T) {
+		_, err := NewFileProvider(filepath.Join(dir, "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	ctx := util.TestContext()
+	Set(NewStaticProvider(map[string]float64{"USD/SGD": 1.35}))
+	defer Set(nil)
+
+	got, rate, err := Convert(ctx, 10, "USD", "SGD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 13.5, got, 1e-9)
+	assert.Equal(t, 1.35, rate)
+
+	got, rate, err = Convert(ctx, 10, "SGD", "SGD")
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, got)
+	assert.Equal(t, 1.0, rate)
+}
@@ -1,18 +1,31 @@
 package gemini
 
+import "time"
+
 const (
 	baseURL    = "https://api.gemini.com"
 	sandboxURL = "https://api.sandbox.gemini.com"
 
 	// public
+	SymbolsURI       = "/v1/symbols"
 	TickerDetailsURI = "/v1/symbols/details/%s"
 	TickerV2URI      = "/v2/ticker/%s"
+	OrderBookURI     = "/v1/book/%s"
 
 	// authenticated
 	NewOrderURI     = "/v1/order/new"
 	ActiveOrdersURI = "/v1/orders"
 	OrderStatusURI  = "/v1/order/status"
 	CancelOrderURI  = "/v1/order/cancel"
+	OrderHistoryURI = "/v1/orders/history"
+	BalancesURI     = "/v1/balances"
+	MyTradesURI     = "/v1/mytrades"
+
+	// websocket, public
+	MarketDataURI = "/v2/marketdata"
+
+	// websocket, authenticated
+	OrderEventsURI = "/v1/order/events"
 )
 
 const (
@@ -20,13 +33,22 @@ const (
 )
 
 const (
-	MaxRetryCount = 5
+	defaultUserAgent = "crypto_dca_go"
+)
+
+const (
+	OrderOptionImmediateOrCancel = "immediate-or-cancel" // fills what it can immediately, cancels the rest
 )
 
 const (
-	BTC = "BTC"
-	ETH = "ETH"
+	websocketReadTimeout = 30 * time.Second // heartbeats are sent every 5 seconds
+	websocketMaxBackoff  = 1 * time.Minute  // between reconnects
+	orderEventsLatestTTL = 1 * time.Hour    // latest update of every order is kept for orders subscribed to after it
+	marketDataDepth      = 50               // price levels on each side of an order book snapshot, as OrderBookLimit
+)
 
-	SGD = "SGD"
-	USD = "USD"
+const (
+	OrderBookLimit    = "50" // number of price levels to fetch on each side of the order book
+	OrderHistoryLimit = 500  // max number of closed orders to fetch from order history
+	MyTradesLimit     = 500  // max number of trades to fetch from trade history
 )
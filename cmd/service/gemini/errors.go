@@ -0,0 +1,149 @@
+package gemini
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+)
+
+// Classes of errors returned by Gemini, to be checked with errors.Is. Those of rejected requests wrap the classes of
+// exchange, see exchange.IsRejected
+var (
+	ErrMoved             = fmt.Errorf("gemini_moved: %w", exchange.ErrRejected)
+	ErrBadRequest        = fmt.Errorf("gemini_bad_request: %w", exchange.ErrRejected)
+	ErrAuth              = fmt.Errorf("gemini_auth: %w", exchange.ErrAuth)
+	ErrNotFound          = fmt.Errorf("gemini_not_found: %w", exchange.ErrRejected)
+	ErrInsufficientFunds = fmt.Errorf("gemini_insufficient_funds: %w", exchange.ErrInsufficientFunds)
+	ErrInvalidNonce      = fmt.Errorf("gemini_invalid_nonce: %w", exchange.ErrRejected)
+	ErrRateLimited       = fmt.Errorf("gemini_rate_limited: %w", exchange.ErrRejected)
+	ErrServer            = errors.New("gemini_server")
+	ErrMaintenance       = errors.New("gemini_maintenance")
+	ErrUnexpectedStatus  = errors.New("gemini_unexpected_status")
+)
+
+// Error of a non-200 response, with the reason & message of the error body, if any
+type Error struct {
+	StatusCode int
+	Reason     string
+	Message    string
+	RetryAfter time.Duration // of the Retry-After header, if any
+	class      error
+}
+
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = statusDescriptions[e.StatusCode]
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("HTTP Status Code: %d --- %s: %s", e.StatusCode, e.Reason, message)
+	}
+	return fmt.Sprintf("HTTP Status Code: %d --- %s", e.StatusCode, message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.class
+}
+
+// Retrying does not help with a request that is rejected for its content, its credentials or an unknown order
+func (e *Error) Retryable() bool {
+	for _, class := range []error{ErrMoved, ErrBadRequest, ErrAuth, ErrNotFound, ErrInsufficientFunds} {
+		if errors.Is(e.class, class) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Error) RetryAfterDelay() time.Duration {
+	return e.RetryAfter
+}
+
+var statusDescriptions = map[int]string{
+	http.StatusBadRequest:          "Auction not open or paused, ineligible timing, market not open, or the request was malformed; in the case of a private API request, missing or malformed Gemini private API authentication headers",
+	http.StatusUnauthorized:        "Missing or invalid Gemini private API authentication headers",
+	http.StatusForbidden:           "The API key is missing the role necessary to access this private API endpoint",
+	http.StatusNotFound:            "Unknown API entry point or Order not found",
+	http.StatusNotAcceptable:       "Insufficient Funds",
+	http.StatusTooManyRequests:     "Rate Limiting was applied",
+	http.StatusInternalServerError: "The server encountered an error",
+	http.StatusBadGateway:          "Technical issues are preventing the request from being satisfied",
+	http.StatusServiceUnavailable:  "The exchange is down for maintenance",
+	http.StatusGatewayTimeout:      "The request timed out at the gateway",
+}
+
+// Reasons of the error body that are more specific than the status code, e.g. a 400 for an invalid nonce
+var reasonClasses = map[string]error{
+	"InsufficientFunds":      ErrInsufficientFunds,
+	"InvalidNonce":           ErrInvalidNonce,
+	"RateLimit":              ErrRateLimited,
+	"RateLimited":            ErrRateLimited,
+	"Maintenance":            ErrMaintenance,
+	"System":                 ErrServer,
+	"InvalidSignature":       ErrAuth,
+	"InvalidApiKey":          ErrAuth,
+	"MissingApikeyHeader":    ErrAuth,
+	"MissingPayloadHeader":   ErrAuth,
+	"MissingSignatureHeader": ErrAuth,
+	"MissingRole":            ErrAuth,
+	"AccountNotFound":        ErrAuth,
+	"OrderNotFound":          ErrNotFound,
+	"EndpointNotFound":       ErrNotFound,
+}
+
+func statusClass(statusCode int) error {
+	switch {
+	case statusCode >= 300 && statusCode < 400:
+		return ErrMoved
+	case statusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrAuth
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusNotAcceptable:
+		return ErrInsufficientFunds
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusServiceUnavailable:
+		return ErrMaintenance
+	case statusCode >= 500:
+		return ErrServer
+	default:
+		return ErrUnexpectedStatus
+	}
+}
+
+// Retry-After is either in seconds or a HTTP date, 0 if absent or invalid
+func parseRetryAfter(retryAfter string) time.Duration {
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(retryAfter); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// newError classifies a non-200 response by the reason of its error body if known, and by its status code otherwise
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode, class: statusClass(statusCode)}
+
+	errorResponse := &ErrorResponse{}
+	if err := json.Unmarshal(body, errorResponse); err == nil && errorResponse.Result == "error" {
+		e.Reason = errorResponse.Reason
+		e.Message = errorResponse.Message
+		if class, ok := reasonClasses[e.Reason]; ok {
+			e.class = class
+		}
+	}
+	return e
+}
@@ -0,0 +1,539 @@
+// Package geminitest provides an in-process fake of the Gemini REST API, for end-to-end tests of the order loop
+// without network access
+package geminitest
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+)
+
+// Server verifies the signature & nonce of every private request as Gemini does, and keeps the orders, trades &
+// balances of a single account. Buy limit orders are filled according to the price path of their symbol
+type Server struct {
+	*httptest.Server
+
+	key    string
+	secret string
+	// Fee of every fill, in the quote currency, as a ratio of the fiat amount filled
+	FeeRate float64
+	// Timestamps of orders & trades
+	Now func() time.Time
+
+	mu        sync.Mutex
+	lastNonce int64
+	symbols   map[string]*Symbol
+	balances  map[string]float64 // keyed by upper case currency
+	orders    []*exchange.Order
+	trades    []*exchange.Trade
+	nextID    int64
+	faults    map[string][]Fault
+	requests  map[string]int // keyed by path
+}
+
+// Symbol listed on the fake exchange, e.g. btcsgd
+type Symbol struct {
+	Symbol         string
+	BaseCurrency   string // e.g. BTC
+	QuoteCurrency  string // e.g. SGD
+	TickSize       float64
+	QuoteIncrement float64
+	MinOrderSize   float64
+	// The price path, starting at its first price. The last price is kept once the path is exhausted
+	Prices []Price
+
+	step         int
+	filledAtStep float64
+}
+
+// Top of the order book at a step of the price path
+type Price struct {
+	Bid float64
+	Ask float64
+	// Amount that can be filled at the step across all orders, no limit if 0
+	Liquidity float64
+}
+
+// Fault injected in place of the response to a request
+type Fault struct {
+	StatusCode int
+	RetryAfter time.Duration // sent as the Retry-After header if set
+	// The connection is dropped without a response after Delay, as when the request times out
+	IsTimeout bool
+	Delay     time.Duration
+}
+
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+func Unavailable() Fault {
+	return Fault{StatusCode: http.StatusServiceUnavailable}
+}
+
+func Timeout(delay time.Duration) Fault {
+	return Fault{IsTimeout: true, Delay: delay}
+}
+
+// NewServer starts a fake exchange for the API key & secret, to be closed once done
+func NewServer(key, secret string) *Server {
+	s := &Server{
+		key:      key,
+		secret:   secret,
+		FeeRate:  gemini.MakerTradingFee,
+		Now:      time.Now,
+		symbols:  make(map[string]*Symbol),
+		balances: make(map[string]float64),
+		faults:   make(map[string][]Fault),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) AddSymbol(symbol Symbol) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbols[symbol.Symbol] = &symbol
+}
+
+func (s *Server) SetBalance(currency string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[strings.ToUpper(currency)] = amount
+}
+
+// Faults are injected in order into the next requests to the path, e.g. gemini.OrderStatusURI
+func (s *Server) InjectFaults(path string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = append(s.faults[path], faults...)
+}
+
+// Advance moves the symbol one step along its price path, filling its live orders that the new price crosses
+//
+// Also done on every order status query of a live order of the symbol, i.e. once every minute of the order loop
+func (s *Server) Advance(symbol string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advance(symbol)
+}
+
+func (s *Server) Balance(currency string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balances[strings.ToUpper(currency)]
+}
+
+// Copies of every order placed, in the order placed
+func (s *Server) Orders() []exchange.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]exchange.Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		orders = append(orders, *order)
+	}
+	return orders
+}
+
+// Number of requests to the path, including those answered with a fault
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := r.URL.Path
+	route := routePath(path)
+	s.requests[route]++
+
+	if faults := s.faults[route]; len(faults) > 0 {
+		s.faults[route] = faults[1:]
+		s.writeFault(w, faults[0])
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		s.handlePublic(w, path)
+		return
+	}
+	params, errReason := s.authenticate(r)
+	if errReason != "" {
+		writeError(w, http.StatusBadRequest, errReason)
+		return
+	}
+	s.handlePrivate(w, path, params)
+}
+
+// Public paths include the symbol, e.g. /v1/book/btcsgd is routed as gemini.OrderBookURI
+func routePath(path string) string {
+	for _, uri := range []string{gemini.TickerDetailsURI, gemini.TickerV2URI, gemini.OrderBookURI} {
+		prefix := strings.TrimSuffix(uri, "%s")
+		if strings.HasPrefix(path, prefix) {
+			return uri
+		}
+	}
+	return path
+}
+
+func (s *Server) writeFault(w http.ResponseWriter, fault Fault) {
+	if fault.Delay > 0 {
+		// Not holding up other requests meanwhile
+		s.mu.Unlock()
+		time.Sleep(fault.Delay)
+		s.mu.Lock()
+	}
+	if fault.IsTimeout {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		writeError(w, http.StatusGatewayTimeout, "Timeout")
+		return
+	}
+	if fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(fault.RetryAfter.Seconds()))))
+	}
+	reason := map[int]string{
+		http.StatusTooManyRequests:    "RateLimited",
+		http.StatusServiceUnavailable: "Maintenance",
+	}[fault.StatusCode]
+	if reason == "" {
+		reason = "System"
+	}
+	writeError(w, fault.StatusCode, reason)
+}
+
+// authenticate verifies the headers of a private request, returning its payload, or the reason it is rejected
+func (s *Server) authenticate(r *http.Request) (map[string]any, string) {
+	if r.Header.Get("X-GEMINI-APIKEY") != s.key {
+		return nil, "InvalidApiKey"
+	}
+	payload := r.Header.Get("X-GEMINI-PAYLOAD")
+	if payload == "" {
+		return nil, "MissingPayloadHeader"
+	}
+	mac := hmac.New(sha512.New384, []byte(s.secret))
+	mac.Write([]byte(payload))
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(r.Header.Get("X-GEMINI-SIGNATURE"))) {
+		return nil, "InvalidSignature"
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "InvalidJson"
+	}
+	var params map[string]any
+	decoder := json.NewDecoder(strings.NewReader(string(decoded)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, "InvalidJson"
+	}
+	if params["request"] != r.URL.Path {
+		return nil, "EndpointMismatch"
+	}
+	nonce, err := strconv.ParseInt(fmt.Sprint(params["nonce"]), 10, 64)
+	if err != nil || nonce <= s.lastNonce {
+		return nil, "InvalidNonce"
+	}
+	s.lastNonce = nonce
+	return params, ""
+}
+
+func (s *Server) handlePublic(w http.ResponseWriter, path string) {
+	if path == gemini.SymbolsURI {
+		symbols := make([]string, 0, len(s.symbols))
+		for symbol := range s.symbols {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		writeJson(w, symbols)
+		return
+	}
+
+	route := routePath(path)
+	symbol, ok := s.symbols[strings.TrimPrefix(path, strings.TrimSuffix(route, "%s"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidSymbol")
+		return
+	}
+	price := symbol.price()
+	switch route {
+	case gemini.TickerDetailsURI:
+		writeJson(w, &gemini.TickerDetails{
+			Symbol:         strings.ToUpper(symbol.Symbol),
+			BaseCurrency:   symbol.BaseCurrency,
+			QuoteCurrency:  symbol.QuoteCurrency,
+			TickSize:       symbol.TickSize,
+			QuoteIncrement: symbol.QuoteIncrement,
+			MinOrderSize:   symbol.MinOrderSize,
+			Status:         "open",
+		})
+	case gemini.TickerV2URI:
+		writeJson(w, &gemini.TickerV2{Symbol: strings.ToUpper(symbol.Symbol), Bid: price.Bid, Ask: price.Ask, Close: price.Bid})
+	case gemini.OrderBookURI:
+		amount := price.Liquidity - symbol.filledAtStep
+		if price.Liquidity == 0 {
+			amount = math.MaxInt32
+		}
+		writeJson(w, &exchange.OrderBook{
+			Bids: []exchange.OrderBookEntry{{Price: price.Bid, Amount: amount}},
+			Asks: []exchange.OrderBookEntry{{Price: price.Ask, Amount: amount}},
+		})
+	default:
+		writeError(w, http.StatusNotFound, "NotFound")
+	}
+}
+
+func (s *Server) handlePrivate(w http.ResponseWriter, path string, params map[string]any) {
+	switch path {
+	case gemini.NewOrderURI:
+		s.newOrder(w, params)
+	case gemini.ActiveOrdersURI:
+		orders := []*exchange.Order{}
+		for _, order := range s.orders {
+			if order.IsLive {
+				orders = append(orders, order)
+			}
+		}
+		writeJson(w, orders)
+	case gemini.OrderStatusURI:
+		s.orderStatus(w, params)
+	case gemini.CancelOrderURI:
+		order := s.findOrder(fmt.Sprint(params["order_id"]))
+		if order == nil {
+			writeError(w, http.StatusNotFound, "OrderNotFound")
+			return
+		}
+		if order.IsLive {
+			order.IsLive, order.IsCancelled = false, true
+		}
+		writeJson(w, order)
+	case gemini.OrderHistoryURI:
+		since := s.since(params)
+		orders := []*exchange.Order{}
+		for _, order := range s.orders {
+			if !order.IsLive && order.Symbol == params["symbol"] && order.Timestampms >= since.UnixMilli() {
+				orders = append(orders, order)
+			}
+		}
+		writeJson(w, orders)
+	case gemini.MyTradesURI:
+		since := s.since(params)
+		trades := []*exchange.Trade{}
+		symbol := s.symbols[fmt.Sprint(params["symbol"])]
+		for _, trade := range s.trades {
+			if symbol != nil && s.findOrder(trade.OrderID).Symbol == symbol.Symbol && trade.Timestampms >= since.UnixMilli() {
+				trades = append(trades, trade)
+			}
+		}
+		writeJson(w, trades)
+	case gemini.BalancesURI:
+		balances := []*gemini.FundBalance{}
+		for currency, amount := range s.balances {
+			available := amount - s.held(currency)
+			balances = append(balances, &gemini.FundBalance{Currency: currency, Amount: amount, Available: available, AvailableForWithdrawal: available, Type: "exchange"})
+		}
+		sort.Slice(balances, func(i, k int) bool { return balances[i].Currency < balances[k].Currency })
+		writeJson(w, balances)
+	default:
+		writeError(w, http.StatusNotFound, "NotFound")
+	}
+}
+
+// Crosses the ask straight away if priced at or above it, the remainder is left live unless immediate-or-cancel
+func (s *Server) newOrder(w http.ResponseWriter, params map[string]any) {
+	symbol, ok := s.symbols[fmt.Sprint(params["symbol"])]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidSymbol")
+		return
+	}
+	price, errPrice := strconv.ParseFloat(fmt.Sprint(params["price"]), 64)
+	amount, errAmount := strconv.ParseFloat(fmt.Sprint(params["amount"]), 64)
+	if errPrice != nil || errAmount != nil || price <= 0 || amount < symbol.MinOrderSize {
+		writeError(w, http.StatusBadRequest, "InvalidQuantity")
+		return
+	}
+	if params["side"] != "buy" || params["type"] != "exchange limit" {
+		writeError(w, http.StatusBadRequest, "InvalidOrderType")
+		return
+	}
+	quoteCurrency := strings.ToUpper(symbol.QuoteCurrency)
+	if s.balances[quoteCurrency]-s.held(quoteCurrency) < price*amount*(1+s.FeeRate) {
+		writeError(w, http.StatusNotAcceptable, "InsufficientFunds")
+		return
+	}
+
+	var options []string
+	if raw, ok := params["options"].([]any); ok {
+		for _, option := range raw {
+			options = append(options, fmt.Sprint(option))
+		}
+	}
+	s.nextID++
+	order := &exchange.Order{
+		OrderID:         strconv.FormatInt(s.nextID, 10),
+		ClientOrderID:   fmt.Sprint(params["client_order_id"]),
+		Symbol:          symbol.Symbol,
+		Exchange:        "gemini",
+		Price:           price,
+		Side:            "buy",
+		Type:            "exchange limit",
+		Options:         options,
+		Timestampms:     s.Now().UnixMilli(),
+		Timestamp:       strconv.FormatInt(s.Now().Unix(), 10),
+		IsLive:          true,
+		OriginalAmount:  amount,
+		RemainingAmount: amount,
+	}
+	s.orders = append(s.orders, order)
+
+	if current := symbol.price(); price >= current.Ask {
+		s.fill(symbol, order, current.Ask, true)
+	}
+	if order.IsLive && len(options) > 0 && options[0] == gemini.OrderOptionImmediateOrCancel {
+		order.IsLive, order.IsCancelled = false, true
+	}
+	writeJson(w, order)
+}
+
+// By order id, or every order of the client order id
+func (s *Server) orderStatus(w http.ResponseWriter, params map[string]any) {
+	if clientOrderID, ok := params["client_order_id"]; ok {
+		orders := []*exchange.Order{}
+		for _, order := range s.orders {
+			if order.ClientOrderID == fmt.Sprint(clientOrderID) {
+				orders = append(orders, order)
+			}
+		}
+		writeJson(w, orders)
+		return
+	}
+	order := s.findOrder(fmt.Sprint(params["order_id"]))
+	if order == nil {
+		writeError(w, http.StatusNotFound, "OrderNotFound")
+		return
+	}
+	if order.IsLive {
+		s.advance(order.Symbol)
+	}
+	writeJson(w, order)
+}
+
+func (s *Server) advance(symbolName string) {
+	symbol, ok := s.symbols[symbolName]
+	if !ok {
+		return
+	}
+	if symbol.step < len(symbol.Prices)-1 {
+		symbol.step++
+		symbol.filledAtStep = 0
+	}
+	current := symbol.price()
+	for _, order := range s.orders {
+		if order.IsLive && order.Symbol == symbol.Symbol && order.Price >= current.Ask {
+			s.fill(symbol, order, order.Price, false)
+		}
+	}
+}
+
+// Fills as much of the order as the liquidity left at the current step allows
+func (s *Server) fill(symbol *Symbol, order *exchange.Order, price float64, isAggressor bool) {
+	amount := order.RemainingAmount
+	if liquidity := symbol.Prices[symbol.step].Liquidity; liquidity > 0 {
+		amount = math.Min(amount, liquidity-symbol.filledAtStep)
+	}
+	if amount <= 0 {
+		return
+	}
+	symbol.filledAtStep += amount
+
+	fee := price * amount * s.FeeRate
+	order.AvgExecutionPrice = (order.AvgExecutionPrice*order.ExecutedAmount + price*amount) / (order.ExecutedAmount + amount)
+	order.ExecutedAmount += amount
+	order.RemainingAmount -= amount
+	if order.RemainingAmount <= 1e-12 {
+		order.RemainingAmount = 0
+		order.IsLive = false
+	}
+	s.balances[strings.ToUpper(symbol.QuoteCurrency)] -= price*amount + fee
+	s.balances[strings.ToUpper(symbol.BaseCurrency)] += amount
+
+	s.nextID++
+	s.trades = append(s.trades, &exchange.Trade{
+		Timestamp:     s.Now().Unix(),
+		Timestampms:   s.Now().UnixMilli(),
+		TradeID:       s.nextID,
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Price:         price,
+		Amount:        amount,
+		Exchange:      "gemini",
+		Type:          "Buy",
+		IsAggressor:   isAggressor,
+		FeeCurrency:   strings.ToUpper(symbol.QuoteCurrency),
+		FeeAmount:     fee,
+	})
+}
+
+// Held by the live orders quoted in the currency
+func (s *Server) held(currency string) float64 {
+	held := 0.0
+	for _, order := range s.orders {
+		if symbol := s.symbols[order.Symbol]; order.IsLive && strings.EqualFold(symbol.QuoteCurrency, currency) {
+			held += order.Price * order.RemainingAmount * (1 + s.FeeRate)
+		}
+	}
+	return held
+}
+
+func (s *Server) findOrder(orderID string) *exchange.Order {
+	for _, order := range s.orders {
+		if order.OrderID == orderID {
+			return order
+		}
+	}
+	return nil
+}
+
+func (s *Server) since(params map[string]any) time.Time {
+	timestamp, _ := strconv.ParseInt(fmt.Sprint(params["timestamp"]), 10, 64)
+	return time.Unix(timestamp, 0)
+}
+
+func (symbol *Symbol) price() Price {
+	if len(symbol.Prices) == 0 {
+		return Price{}
+	}
+	return symbol.Prices[symbol.step]
+}
+
+func writeJson(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(&gemini.ErrorResponse{Result: "error", Reason: reason, Message: reason})
+}
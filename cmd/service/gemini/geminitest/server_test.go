@@ -0,0 +1,128 @@
+package geminitest
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
+	"github.com/stretchr/testify/assert"
+)
+
+// Signed as the Gemini client does, with the secret given. The request of the payload defaults to the path
+func post(t *testing.T, s *Server, path, secret string, params map[string]any) (int, []byte) {
+	if _, ok := params["request"]; !ok {
+		params["request"] = path
+	}
+	payloadJson, err := json.Marshal(params)
+	assert.NoError(t, err)
+	payload := base64.StdEncoding.EncodeToString(payloadJson)
+	mac := hmac.New(sha512.New384, []byte(secret))
+	mac.Write([]byte(payload))
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+path, nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-GEMINI-APIKEY", "key")
+	req.Header.Set("X-GEMINI-PAYLOAD", payload)
+	req.Header.Set("X-GEMINI-SIGNATURE", hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return 0, nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, body
+}
+
+func reason(t *testing.T, body []byte) string {
+	errResp := &gemini.ErrorResponse{}
+	assert.NoError(t, json.Unmarshal(body, errResp))
+	return errResp.Reason
+}
+
+func TestServer_authenticate(t *testing.T) {
+	s := NewServer("key", "secret")
+	defer s.Close()
+
+	statusCode, _ := post(t, s, gemini.BalancesURI, "secret", map[string]any{"nonce": 2})
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	statusCode, body := post(t, s, gemini.BalancesURI, "secret", map[string]any{"nonce": 2})
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	assert.Equal(t, "InvalidNonce", reason(t, body))
+
+	_, body = post(t, s, gemini.BalancesURI, "wrong_secret", map[string]any{"nonce": 3})
+	assert.Equal(t, "InvalidSignature", reason(t, body))
+
+	_, body = post(t, s, gemini.BalancesURI, "secret", map[string]any{"nonce": 4, "request": gemini.ActiveOrdersURI})
+	assert.Equal(t, "EndpointMismatch", reason(t, body))
+}
+
+func TestServer_newOrder(t *testing.T) {
+	s := NewServer("key", "secret")
+	defer s.Close()
+	s.SetBalance("SGD", 1000)
+	s.AddSymbol(Symbol{
+		Symbol: "btcsgd", BaseCurrency: "BTC", QuoteCurrency: "SGD", MinOrderSize: 0.001,
+		Prices: []Price{{Bid: 100, Ask: 101, Liquidity: 1}, {Bid: 99, Ask: 99.5}},
+	})
+	nonce := 0
+	newOrder := func(price, amount string, options ...string) (int, *exchange.Order, []byte) {
+		nonce++
+		params := map[string]any{"nonce": nonce, "symbol": "btcsgd", "price": price, "amount": amount, "side": "buy", "type": "exchange limit"}
+		if len(options) > 0 {
+			params["options"] = options
+		}
+		statusCode, body := post(t, s, gemini.NewOrderURI, "secret", params)
+		order := &exchange.Order{}
+		_ = json.Unmarshal(body, order)
+		return statusCode, order, body
+	}
+
+	t.Run("ok_immediate_or_cancel_partially_filled", func(t *testing.T) {
+		statusCode, order, _ := newOrder("101", "2", gemini.OrderOptionImmediateOrCancel)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.True(t, order.IsCancelled)
+		assert.Equal(t, 1.0, order.ExecutedAmount)
+		assert.Equal(t, 101.0, order.AvgExecutionPrice)
+		assert.InDelta(t, 1000-101*1.002, s.Balance("SGD"), 1e-9)
+	})
+
+	t.Run("ok_live_then_filled", func(t *testing.T) {
+		statusCode, order, _ := newOrder("99.5", "1")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.True(t, order.IsLive)
+
+		s.Advance("btcsgd")
+		orders := s.Orders()
+		assert.False(t, orders[len(orders)-1].IsLive)
+		assert.Equal(t, 99.5, orders[len(orders)-1].AvgExecutionPrice)
+		assert.Equal(t, 2.0, s.Balance("BTC"))
+	})
+
+	t.Run("error_insufficient_funds", func(t *testing.T) {
+		statusCode, _, body := newOrder("99", "100")
+		assert.Equal(t, http.StatusNotAcceptable, statusCode)
+		assert.Equal(t, "InsufficientFunds", reason(t, body))
+	})
+
+	t.Run("error_injected_faults", func(t *testing.T) {
+		s.InjectFaults(gemini.NewOrderURI, RateLimited(2500*time.Millisecond), Unavailable())
+		nonce++
+		statusCode, body := post(t, s, gemini.NewOrderURI, "secret", map[string]any{"nonce": nonce})
+		assert.Equal(t, http.StatusTooManyRequests, statusCode)
+		assert.Equal(t, "RateLimited", reason(t, body))
+		statusCode, body = post(t, s, gemini.NewOrderURI, "secret", map[string]any{"nonce": nonce})
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, "Maintenance", reason(t, body))
+		assert.Equal(t, 5, s.Requests(gemini.NewOrderURI))
+	})
+}
@@ -1,15 +1,46 @@
 package gemini
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
-func (api *Api) GetQuoteIncrementAndTickSize(ticker string) (int, int, error) {
+func (api *Api) GetMakerTradingFee() float64 {
+	return MakerTradingFee
+}
+
+// Errors if the trading pair of any of the tickers, as configured, is not listed on Gemini
+func (api *Api) ValidateTradingPairs(ctx context.Context, tickers []string) error {
+	location := "gemini.ValidateTradingPairs"
+	symbols, err := api.symbols(ctx)
+	if err != nil {
+		logger.Error(location, "Error getting symbols", err)
+		return err
+	}
+	isSymbol := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		isSymbol[strings.ToLower(symbol)] = true
+	}
+	for _, ticker := range tickers {
+		if symbol := AppendTickerWithQuoteCurrency(ticker); !isSymbol[symbol] {
+			err := fmt.Errorf("unknown_symbol: %s", symbol)
+			logger.Error(location, "ticker: %s", err, ticker)
+			return err
+		}
+	}
+	return nil
+}
+
+func (api *Api) GetQuoteIncrementAndTickSize(ctx context.Context, ticker string) (int, int, error) {
 	location := "gemini.GetTickSize"
-	tickerData, err := api.tickerDetails(ticker)
+	tickerData, err := api.tickerDetails(ctx, ticker)
 	if err != nil {
 		logger.Error(location, "ticker: %s", err, ticker)
 		return 0, 0, err
@@ -17,9 +48,27 @@ func (api *Api) GetQuoteIncrementAndTickSize(ticker string) (int, int, error) {
 	return util.NumDecimalPlaces(tickerData.QuoteIncrement), util.NumDecimalPlaces(tickerData.TickSize), nil
 }
 
-func (api *Api) GetTickerBestBidPrice(ticker string) (float64, error) {
+func (api *Api) GetMinOrderSize(ctx context.Context, ticker string) (float64, error) {
+	location := "gemini.GetMinOrderSize"
+	tickerData, err := api.tickerDetails(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, err
+	}
+	return tickerData.MinOrderSize, nil
+}
+
+func (api *Api) GetTickerBestBidPrice(ctx context.Context, ticker string) (float64, error) {
 	location := "gemini.GetTickerBestBidPrice"
-	tickerActivity, err := api.tickerV2(ticker)
+	if api.marketData != nil {
+		bestBid, _, err := api.streamedBestBidAskPrice(ctx, ticker)
+		if err != nil {
+			logger.Error(location, "ticker: %s", err, ticker)
+			return 0, err
+		}
+		return bestBid, nil
+	}
+	tickerActivity, err := api.tickerV2(ctx, ticker)
 	if err != nil {
 		logger.Error(location, "ticker: %s", err, ticker)
 		return 0, err
@@ -27,10 +76,59 @@ func (api *Api) GetTickerBestBidPrice(ticker string) (float64, error) {
 	return tickerActivity.Bid, nil
 }
 
-func (api *Api) CreateOrder(ticker string, bestBid float64, quoteIncrement, tickSize int) (*Order, error) {
+func (api *Api) GetTickerBestBidAskPrice(ctx context.Context, ticker string) (float64, float64, error) {
+	location := "gemini.GetTickerBestBidAskPrice"
+	if api.marketData != nil {
+		bestBid, bestAsk, err := api.streamedBestBidAskPrice(ctx, ticker)
+		if err != nil {
+			logger.Error(location, "ticker: %s", err, ticker)
+			return 0, 0, err
+		}
+		return bestBid, bestAsk, nil
+	}
+	tickerActivity, err := api.tickerV2(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, 0, err
+	}
+	return tickerActivity.Bid, tickerActivity.Ask, nil
+}
+
+func (api *Api) GetOrderBook(ctx context.Context, ticker string) (*exchange.OrderBook, error) {
+	location := "gemini.GetOrderBook"
+	if api.marketData != nil {
+		if orderBook, ok := api.marketData.Snapshot(ticker); ok {
+			return orderBook, nil
+		}
+	}
+	orderBook, err := api.orderBook(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	return orderBook, nil
+}
+
+// Top of the streamed order book, or of the REST order book while the market data websocket is disconnected
+func (api *Api) streamedBestBidAskPrice(ctx context.Context, ticker string) (float64, float64, error) {
+	orderBook, ok := api.marketData.Snapshot(ticker)
+	if !ok {
+		var err error
+		if orderBook, err = api.orderBook(ctx, ticker); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(orderBook.Bids) == 0 || len(orderBook.Asks) == 0 {
+		return 0, 0, errors.New("empty_order_book")
+	}
+	return orderBook.Bids[0].Price, orderBook.Asks[0].Price, nil
+}
+
+// clientOrderID should be formed with FormClientOrderID, so that the order can be matched after an ambiguous failure
+func (api *Api) CreateOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*exchange.Order, error) {
 	location := "gemini.CreateOrder"
-	orderPriceStr, orderAmountStr := formCreateOrderReq(ticker, bestBid, quoteIncrement, tickSize)
-	order, err := api.newOrder(ticker, orderPriceStr, orderAmountStr)
+	orderPriceStr, orderAmountStr := formCreateOrderReq(orderPrice, fiatAmount, quoteIncrement, tickSize)
+	order, err := api.newOrder(ctx, ticker, clientOrderID, orderPriceStr, orderAmountStr, nil)
 	if err != nil {
 		logger.Error(location, "ticker: %s", err, ticker)
 		return nil, err
@@ -38,26 +136,86 @@ func (api *Api) CreateOrder(ticker string, bestBid float64, quoteIncrement, tick
 	return order, nil
 }
 
-func (api *Api) MatchActiveOrders(ticker string) (*Order, error) {
-	location := "gemini.MatchActiveOrders"
-	orders, err := api.getActiveOrders()
+// Same as CreateOrder, but the order is never live - it is filled immediately, and the unfilled remainder is cancelled
+func (api *Api) CreateImmediateOrCancelOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*exchange.Order, error) {
+	location := "gemini.CreateImmediateOrCancelOrder"
+	orderPriceStr, orderAmountStr := formCreateOrderReq(orderPrice, fiatAmount, quoteIncrement, tickSize)
+	order, err := api.newOrder(ctx, ticker, clientOrderID, orderPriceStr, orderAmountStr, []string{OrderOptionImmediateOrCancel})
 	if err != nil {
 		logger.Error(location, "ticker: %s", err, ticker)
 		return nil, err
 	}
-	for _, order := range orders {
-		if order != nil && order.Symbol == AppendTickerWithQuoteCurrency(ticker) && order.Side == "buy" {
-			return order, nil
-		}
+	return order, nil
+}
+
+// Finds the order submitted with clientOrderID, in case creating it had failed ambiguously
+//
+// Active orders are searched first, then the order status endpoint, which also covers orders that are no longer live
+func (api *Api) MatchActiveOrders(ctx context.Context, ticker, clientOrderID string) (*exchange.Order, error) {
+	location := "gemini.MatchActiveOrders"
+	orders, err := api.getActiveOrders(ctx)
+	if err != nil {
+		logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
+		return nil, err
 	}
+	if order := matchClientOrderID(orders, ticker, clientOrderID); order != nil {
+		return order, nil
+	}
+
+	orders, err = api.orderStatusByClientOrderID(ctx, clientOrderID)
+	if err != nil {
+		logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
+		return nil, err
+	}
+	if order := matchClientOrderID(orders, ticker, clientOrderID); order != nil {
+		return order, nil
+	}
+
 	err = errors.New("order_not_found")
-	logger.Error(location, "ticker: %s", err, ticker)
+	logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
 	return nil, err
 }
 
-func (api *Api) GetOrderStatus(orderID string) (*Order, error) {
+// Whether any buy order of the ticker has been (partially) filled since the given time
+func (api *Api) HasFilledBuyOrderSince(ctx context.Context, ticker string, since time.Time) (bool, error) {
+	location := "gemini.HasFilledBuyOrderSince"
+	orders, err := api.getOrderHistory(ctx, ticker, since)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return false, err
+	}
+	for _, order := range orders {
+		if order != nil && order.Side == "buy" && order.ExecutedAmount > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Trades of the given orders since the given time, i.e. the actual fills and fees of the orders
+func (api *Api) GetOrderTrades(ctx context.Context, ticker string, orderIDs []string, since time.Time) ([]*exchange.Trade, error) {
+	location := "gemini.GetOrderTrades"
+	trades, err := api.getMyTrades(ctx, ticker, since)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	isOrderID := make(map[string]bool, len(orderIDs))
+	for _, orderID := range orderIDs {
+		isOrderID[orderID] = true
+	}
+	orderTrades := make([]*exchange.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade != nil && isOrderID[trade.OrderID] {
+			orderTrades = append(orderTrades, trade)
+		}
+	}
+	return orderTrades, nil
+}
+
+func (api *Api) GetOrderStatus(ctx context.Context, orderID string) (*exchange.Order, error) {
 	location := "gemini.GetOrderStatus"
-	order, err := api.orderStatus(orderID)
+	order, err := api.orderStatus(ctx, orderID)
 	if err != nil {
 		logger.Error(location, "orderID: %s", err, orderID)
 		return nil, err
@@ -65,12 +223,52 @@ func (api *Api) GetOrderStatus(orderID string) (*Order, error) {
 	return order, nil
 }
 
-func (api *Api) CancelOrder(orderID string) (*Order, error) {
+func (api *Api) CancelOrder(ctx context.Context, orderID string) (*exchange.Order, error) {
 	location := "gemini.cancelOrder"
-	order, err := api.cancelOrder(orderID)
+	order, err := api.cancelOrder(ctx, orderID)
 	if err != nil {
 		logger.Error(location, "orderID: %s", err, orderID)
 		return nil, err
 	}
 	return order, nil
 }
+
+// Updates of the order pushed by the order events websocket, the channel is nil if order updates are polled
+func (api *Api) SubscribeOrderEvents(orderID string) (<-chan *exchange.Order, func()) {
+	if api.orderEvents == nil {
+		return nil, func() {}
+	}
+	return api.orderEvents.Subscribe(orderID)
+}
+
+func (api *Api) OrderEventsConnectedSince() (time.Time, bool) {
+	if api.orderEvents == nil {
+		return time.Time{}, false
+	}
+	return api.orderEvents.ConnectedSince()
+}
+
+// Best bid of the ticker streamed by the market data websocket, the channel is nil if market data is polled
+func (api *Api) SubscribeBestBid(ticker string) (<-chan float64, func()) {
+	if api.marketData == nil {
+		return nil, func() {}
+	}
+	return api.marketData.SubscribeBestBid(ticker)
+}
+
+// Available balance keyed by upper case currency, e.g. SGD
+func (api *Api) GetAvailableBalances(ctx context.Context) (map[string]float64, error) {
+	location := "gemini.GetAvailableBalances"
+	balances, err := api.getBalances(ctx)
+	if err != nil {
+		logger.Error(location, "Error getting balances", err)
+		return nil, err
+	}
+	availableBalances := make(map[string]float64, len(balances))
+	for _, balance := range balances {
+		if balance != nil {
+			availableBalances[strings.ToUpper(balance.Currency)] += balance.Available
+		}
+	}
+	return availableBalances, nil
+}
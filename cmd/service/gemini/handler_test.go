@@ -1,18 +1,96 @@
 package gemini
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestApi_ValidateTradingPairs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "SGD", "ETH": "USD"}}, nil)
+	type args struct {
+		tickers []string
+	}
+	tests := []struct {
+		name    string
+		setup   func() func()
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `["btcusd", "btcsgd", "ethusd"]`)
+				httpmock.RegisterResponder(http.MethodGet, SymbolsURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				tickers: []string{"BTC", "ETH"},
+			},
+		},
+		{
+			name: "error_unknown_symbol",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `["btcusd", "btcsgd", "ethsgd"]`)
+				httpmock.RegisterResponder(http.MethodGet, SymbolsURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				tickers: []string{"BTC", "ETH"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodGet, SymbolsURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				tickers: []string{"BTC"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			err := api.ValidateTradingPairs(util.TestContext(), tt.args.tickers)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			teardown()
+		})
+	}
+}
+
 func TestApi_GetQuoteIncrementAndTickSize(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
 	type args struct {
 		ticker string
 	}
@@ -63,7 +141,7 @@ func TestApi_GetQuoteIncrementAndTickSize(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, got1, err := api.GetQuoteIncrementAndTickSize(tt.args.ticker)
+			got, got1, err := api.GetQuoteIncrementAndTickSize(util.TestContext(), tt.args.ticker)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Api.GetQuoteIncrementAndTickSize() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -79,9 +157,75 @@ func TestApi_GetQuoteIncrementAndTickSize(t *testing.T) {
 	}
 }
 
+func TestApi_GetMinOrderSize(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+	type args struct {
+		ticker string
+	}
+	tests := []struct {
+		name    string
+		setup   func() func()
+		args    args
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{
+					"tick_size": 1E-8,
+					"quote_increment": 0.01,
+					"min_order_size": "0.00001"
+				}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(TickerDetailsURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			want: 0.00001,
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(TickerDetailsURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			got, err := api.GetMinOrderSize(util.TestContext(), tt.args.ticker)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
+
 func TestApi_GetTickerBestBidPrice(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
 	type args struct {
 		ticker string
 	}
@@ -129,7 +273,7 @@ func TestApi_GetTickerBestBidPrice(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, err := api.GetTickerBestBidPrice(tt.args.ticker)
+			got, err := api.GetTickerBestBidPrice(util.TestContext(), tt.args.ticker)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Api.GetTickerBestBidPrice() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -142,6 +286,188 @@ func TestApi_GetTickerBestBidPrice(t *testing.T) {
 	}
 }
 
+func TestApi_GetTickerBestBidAskPrice(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+	type args struct {
+		ticker string
+	}
+	tests := []struct {
+		name       string
+		setup      func() func()
+		marketData *MarketData
+		args       args
+		want       float64
+		want1      float64
+		wantErr    bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{
+					"bid": "9345.70",
+					"ask": "9347.67"
+				}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(TickerV2URI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			want:  9345.7,
+			want1: 9347.67,
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(TickerV2URI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok_streamed",
+			setup: func() func() {
+				return func() {}
+			},
+			marketData: newTestMarketData(t, true, [][]string{{"buy", "9345.70", "1"}, {"buy", "9345.60", "1"}, {"sell", "9347.67", "1"}}),
+			args: args{
+				ticker: "BTC",
+			},
+			want:  9345.7,
+			want1: 9347.67,
+		},
+		{
+			name: "ok_streamed_disconnected",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{
+					"bids": [{"price": "9345.50", "amount": "1"}],
+					"asks": [{"price": "9347.00", "amount": "1"}]
+				}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(OrderBookURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			marketData: newTestMarketData(t, false, [][]string{{"buy", "9345.70", "1"}, {"sell", "9347.67", "1"}}),
+			args: args{
+				ticker: "BTC",
+			},
+			want:  9345.5,
+			want1: 9347,
+		},
+		{
+			name: "error_streamed_disconnected_empty_book",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{"bids": [], "asks": []}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(OrderBookURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			marketData: newTestMarketData(t, false, nil),
+			args: args{
+				ticker: "BTC",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url:        "",
+				marketData: tt.marketData,
+			}
+			teardown := tt.setup()
+			got, got1, err := api.GetTickerBestBidAskPrice(util.TestContext(), tt.args.ticker)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want1, got1)
+			teardown()
+		})
+	}
+}
+
+func TestApi_GetOrderBook(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+	type args struct {
+		ticker string
+	}
+	tests := []struct {
+		name    string
+		setup   func() func()
+		args    args
+		want    *exchange.OrderBook
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{
+					"bids": [{"price": "3607.85", "amount": "6.643373", "timestamp": "1547147541"}],
+					"asks": [{"price": "3607.86", "amount": "14.68205084", "timestamp": "1547147541"}]
+				}`)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(OrderBookURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			want: &exchange.OrderBook{
+				Bids: []exchange.OrderBookEntry{{Price: 3607.85, Amount: 6.643373}},
+				Asks: []exchange.OrderBookEntry{{Price: 3607.86, Amount: 14.68205084}},
+			},
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf(OrderBookURI, "btcsgd"), responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			got, err := api.GetOrderBook(util.TestContext(), tt.args.ticker)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
+
 func TestApi_CreateOrder(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -149,46 +475,55 @@ func TestApi_CreateOrder(t *testing.T) {
 
 	type args struct {
 		ticker         string
-		bestBid        float64
-		tickSize       int
+		clientOrderID  string
+		orderPrice     float64
+		fiatAmount     float64
 		quoteIncrement int
+		tickSize       int
 	}
 	tests := []struct {
 		name    string
 		setup   func() func()
 		args    args
-		want    *Order
+		want    *exchange.Order
 		wantErr bool
 	}{
 		{
 			name: "ok",
 			setup: func() func() {
-				responder := httpmock.NewStringResponder(http.StatusOK, `{
-						"order_id": "106817811", 
+				// Echoes the client order id of the payload
+				httpmock.RegisterResponder(http.MethodPost, NewOrderURI, func(req *http.Request) (*http.Response, error) {
+					payload, _ := base64.StdEncoding.DecodeString(req.Header.Get("X-GEMINI-PAYLOAD"))
+					params := map[string]any{}
+					_ = json.Unmarshal(payload, &params)
+					return httpmock.NewStringResponse(http.StatusOK, fmt.Sprintf(`{
+						"order_id": "106817811",
 						"avg_execution_price": "3632.8508430064554",
-						"is_live": false, 
-						"is_cancelled": false, 
+						"is_live": false,
+						"is_cancelled": false,
 						"executed_amount": "3.7567928949",
-						"client_order_id": "20190110-4738721"
-				}`)
-				httpmock.RegisterResponder(http.MethodPost, NewOrderURI, responder)
+						"client_order_id": "%v"
+					}`, params["client_order_id"])), nil
+				})
 				return func() {
 					httpmock.Reset()
 				}
 			},
 			args: args{
 				ticker:         "BTC",
-				bestBid:        3632.85,
-				tickSize:       8,
+				clientOrderID:  "20241101_btcsgd_w1_a0",
+				orderPrice:     3632.85,
+				fiatAmount:     1,
 				quoteIncrement: 2,
+				tickSize:       8,
 			},
-			want: &Order{
+			want: &exchange.Order{
 				OrderID:           "106817811",
 				AvgExecutionPrice: 3632.8508430064554,
 				IsLive:            false,
 				IsCancelled:       false,
 				ExecutedAmount:    3.7567928949,
-				ClientOrderID:     "20190110-4738721",
+				ClientOrderID:     "20241101_btcsgd_w1_a0",
 			},
 		},
 	}
@@ -198,7 +533,7 @@ func TestApi_CreateOrder(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, err := api.CreateOrder(tt.args.ticker, tt.args.bestBid, tt.args.tickSize, tt.args.quoteIncrement)
+			got, err := api.CreateOrder(util.TestContext(), tt.args.ticker, tt.args.clientOrderID, tt.args.orderPrice, tt.args.fiatAmount, tt.args.quoteIncrement, tt.args.tickSize)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -210,19 +545,60 @@ func TestApi_CreateOrder(t *testing.T) {
 	}
 }
 
+func TestApi_CreateImmediateOrCancelOrder(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+
+	t.Run("ok", func(t *testing.T) {
+		defer httpmock.Reset()
+		var params map[string]any
+		httpmock.RegisterResponder(http.MethodPost, NewOrderURI, func(req *http.Request) (*http.Response, error) {
+			payload, _ := base64.StdEncoding.DecodeString(req.Header.Get("X-GEMINI-PAYLOAD"))
+			_ = json.Unmarshal(payload, &params)
+			return httpmock.NewStringResponse(http.StatusOK, `{
+				"order_id": "106817811",
+				"avg_execution_price": "1002",
+				"is_live": false,
+				"is_cancelled": true,
+				"executed_amount": "0.0005",
+				"client_order_id": "20241101_btcsgd_w21_a0"
+			}`), nil
+		})
+
+		api := &Api{
+			url: "",
+		}
+		got, err := api.CreateImmediateOrCancelOrder(util.TestContext(), "BTC", "20241101_btcsgd_w21_a0", 1002, 1, 2, 8)
+		assert.NoError(t, err)
+		assert.Equal(t, &exchange.Order{
+			OrderID:           "106817811",
+			AvgExecutionPrice: 1002,
+			IsLive:            false,
+			IsCancelled:       true,
+			ExecutedAmount:    0.0005,
+			ClientOrderID:     "20241101_btcsgd_w21_a0",
+		}, got)
+		assert.Equal(t, []any{OrderOptionImmediateOrCancel}, params["options"])
+		assert.Equal(t, "1002.00", params["price"])
+		assert.Equal(t, "0.00099800", params["amount"])
+	})
+}
+
 func TestApi_MatchActiveOrders(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 	config.TestInit(nil, nil)
 
 	type args struct {
-		ticker string
+		ticker        string
+		clientOrderID string
 	}
 	tests := []struct {
 		name    string
 		setup   func() func()
 		args    args
-		want    *Order
+		want    *exchange.Order
 		wantErr bool
 	}{
 		{
@@ -236,7 +612,8 @@ func TestApi_MatchActiveOrders(t *testing.T) {
 							"is_cancelled": false, 
 							"executed_amount": "3.7567928949",
 							"client_order_id": "20190110-4738721",
-							"symbol": "btcsgd"
+							"symbol": "btcsgd",
+							"side": "buy"
 					},
 					{
 							"order_id": "106817812", 
@@ -254,9 +631,59 @@ func TestApi_MatchActiveOrders(t *testing.T) {
 				}
 			},
 			args: args{
-				ticker: "BTC",
+				ticker:        "BTC",
+				clientOrderID: "20190110-4738721",
+			},
+			want: &exchange.Order{
+				OrderID:           "106817811",
+				AvgExecutionPrice: 3632.8508430064554,
+				IsLive:            false,
+				IsCancelled:       false,
+				ExecutedAmount:    3.7567928949,
+				ClientOrderID:     "20190110-4738721",
+				Symbol:            "btcsgd",
+				Side:              "buy",
+			},
+			wantErr: false,
+		},
+		{
+			name: "ok_not_live",
+			setup: func() func() {
+				// Another open buy on the same symbol is not the order
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{
+							"order_id": "106817810",
+							"is_live": true,
+							"is_cancelled": false,
+							"executed_amount": "0",
+							"client_order_id": "20190110-4738720",
+							"symbol": "btcsgd",
+							"side": "buy"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, ActiveOrdersURI, responder)
+				responder = httpmock.NewStringResponder(http.StatusOK, `[
+					{
+							"order_id": "106817811",
+							"avg_execution_price": "3632.8508430064554",
+							"is_live": false,
+							"is_cancelled": false,
+							"executed_amount": "3.7567928949",
+							"client_order_id": "20190110-4738721",
+							"symbol": "btcsgd",
+							"side": "buy"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, OrderStatusURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker:        "BTC",
+				clientOrderID: "20190110-4738721",
 			},
-			want: &Order{
+			want: &exchange.Order{
 				OrderID:           "106817811",
 				AvgExecutionPrice: 3632.8508430064554,
 				IsLive:            false,
@@ -264,6 +691,7 @@ func TestApi_MatchActiveOrders(t *testing.T) {
 				ExecutedAmount:    3.7567928949,
 				ClientOrderID:     "20190110-4738721",
 				Symbol:            "btcsgd",
+				Side:              "buy",
 			},
 			wantErr: false,
 		},
@@ -282,12 +710,15 @@ func TestApi_MatchActiveOrders(t *testing.T) {
 					}
 				]`)
 				httpmock.RegisterResponder(http.MethodPost, ActiveOrdersURI, responder)
+				responder = httpmock.NewStringResponder(http.StatusOK, `[]`)
+				httpmock.RegisterResponder(http.MethodPost, OrderStatusURI, responder)
 				return func() {
 					httpmock.Reset()
 				}
 			},
 			args: args{
-				ticker: "BTC",
+				ticker:        "BTC",
+				clientOrderID: "20190110-4738721",
 			},
 			want:    nil,
 			wantErr: true,
@@ -299,7 +730,214 @@ func TestApi_MatchActiveOrders(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, err := api.MatchActiveOrders(tt.args.ticker)
+			got, err := api.MatchActiveOrders(util.TestContext(), tt.args.ticker, tt.args.clientOrderID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
+
+func TestApi_HasFilledBuyOrderSince(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+
+	type args struct {
+		ticker string
+		since  time.Time
+	}
+	tests := []struct {
+		name    string
+		setup   func() func()
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "ok_filled",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{
+						"order_id": "106817811",
+						"symbol": "btcsgd",
+						"side": "buy",
+						"is_live": false,
+						"is_cancelled": true,
+						"executed_amount": "0.0001"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, OrderHistoryURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+				since:  config.TestNowDate,
+			},
+			want: true,
+		},
+		{
+			name: "ok_not_filled",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{
+						"order_id": "106817811",
+						"symbol": "btcsgd",
+						"side": "buy",
+						"is_live": false,
+						"is_cancelled": true,
+						"executed_amount": "0"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, OrderHistoryURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+				since:  config.TestNowDate,
+			},
+			want: false,
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, OrderHistoryURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker: "BTC",
+				since:  config.TestNowDate,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			got, err := api.HasFilledBuyOrderSince(util.TestContext(), tt.args.ticker, tt.args.since)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
+
+func TestApi_GetOrderTrades(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+
+	type args struct {
+		ticker   string
+		orderIDs []string
+		since    time.Time
+	}
+	tests := []struct {
+		name    string
+		setup   func() func()
+		args    args
+		want    []*exchange.Trade
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{
+						"price": "3648.09",
+						"amount": "0.0027343246",
+						"timestamp": 1547232911,
+						"timestampms": 1547232911021,
+						"type": "Buy",
+						"aggressor": true,
+						"fee_currency": "SGD",
+						"fee_amount": "0.024937655575035",
+						"tid": 107317526,
+						"order_id": "107317524",
+						"exchange": "gemini"
+					},
+					{
+						"price": "3640.00",
+						"amount": "0.1",
+						"timestamp": 1547232900,
+						"timestampms": 1547232900000,
+						"type": "Buy",
+						"aggressor": false,
+						"fee_currency": "SGD",
+						"fee_amount": "0.728",
+						"tid": 107317500,
+						"order_id": "107317400",
+						"exchange": "gemini"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker:   "BTC",
+				orderIDs: []string{"107317524"},
+				since:    time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+			},
+			want: []*exchange.Trade{
+				{
+					Timestamp:   1547232911,
+					Timestampms: 1547232911021,
+					TradeID:     107317526,
+					OrderID:     "107317524",
+					Price:       3648.09,
+					Amount:      0.0027343246,
+					Exchange:    "gemini",
+					Type:        "Buy",
+					IsAggressor: true,
+					FeeCurrency: "SGD",
+					FeeAmount:   0.024937655575035,
+				},
+			},
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, MyTradesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			args: args{
+				ticker:   "BTC",
+				orderIDs: []string{"107317524"},
+				since:    time.Date(2019, 1, 11, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			got, err := api.GetOrderTrades(util.TestContext(), tt.args.ticker, tt.args.orderIDs, tt.args.since)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -323,7 +961,7 @@ func TestApi_GetOrderStatus(t *testing.T) {
 		name    string
 		setup   func() func()
 		args    args
-		want    *Order
+		want    *exchange.Order
 		wantErr bool
 	}{
 		{
@@ -345,7 +983,7 @@ func TestApi_GetOrderStatus(t *testing.T) {
 			args: args{
 				orderID: "106817811",
 			},
-			want: &Order{
+			want: &exchange.Order{
 				OrderID:           "106817811",
 				AvgExecutionPrice: 3632.8508430064554,
 				IsLive:            false,
@@ -361,7 +999,7 @@ func TestApi_GetOrderStatus(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, err := api.GetOrderStatus(tt.args.orderID)
+			got, err := api.GetOrderStatus(util.TestContext(), tt.args.orderID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -385,7 +1023,7 @@ func TestApi_CancelOrder(t *testing.T) {
 		name    string
 		setup   func() func()
 		args    args
-		want    *Order
+		want    *exchange.Order
 		wantErr bool
 	}{
 		{
@@ -408,7 +1046,7 @@ func TestApi_CancelOrder(t *testing.T) {
 			args: args{
 				orderID: "106817811",
 			},
-			want: &Order{
+			want: &exchange.Order{
 				OrderID:           "106817811",
 				AvgExecutionPrice: 3632.8508430064554,
 				IsLive:            false,
@@ -424,7 +1062,77 @@ func TestApi_CancelOrder(t *testing.T) {
 				url: "",
 			}
 			teardown := tt.setup()
-			got, err := api.CancelOrder(tt.args.orderID)
+			got, err := api.CancelOrder(util.TestContext(), tt.args.orderID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			teardown()
+		})
+	}
+}
+
+func TestApi_GetAvailableBalances(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	config.TestInit(nil, nil)
+
+	tests := []struct {
+		name    string
+		setup   func() func()
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `[
+					{
+						"type": "exchange",
+						"currency": "SGD",
+						"amount": "120.5",
+						"available": "100.5",
+						"availableForWithdrawal": "100.5"
+					},
+					{
+						"type": "exchange",
+						"currency": "BTC",
+						"amount": "0.1",
+						"available": "0.1",
+						"availableForWithdrawal": "0.1"
+					}
+				]`)
+				httpmock.RegisterResponder(http.MethodPost, BalancesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			want: map[string]float64{
+				"SGD": 100.5,
+				"BTC": 0.1,
+			},
+		},
+		{
+			name: "error",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusInternalServerError, ``)
+				httpmock.RegisterResponder(http.MethodPost, BalancesURI, responder)
+				return func() {
+					httpmock.Reset()
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url: "",
+			}
+			teardown := tt.setup()
+			got, err := api.GetAvailableBalances(util.TestContext())
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
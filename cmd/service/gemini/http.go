@@ -2,32 +2,84 @@ package gemini
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+	"golang.org/x/time/rate"
 )
 
 type Api struct {
 	url    string
 	key    string
 	secret string
+
+	client      *http.Client // http.DefaultClient if nil
+	timeout     time.Duration
+	userAgent   string
+	middlewares []Middleware
+
+	// Shared by every ticker, nil if not rate limited
+	publicLimiter  *rate.Limiter
+	privateLimiter *rate.Limiter
+	limiterWait    time.Duration
+
+	nonces     exchange.NonceProvider
+	nonceOrder exchange.NonceOrder
+
+	orderEvents *OrderEvents // nil if order updates are polled
+	marketData  *MarketData  // nil if market data is polled
+}
+
+// Requests are sent to the live API unless configured otherwise with opts
+func New(key, secret string, opts ...Option) *Api {
+	api := &Api{
+		url:       baseURL,
+		key:       key,
+		secret:    secret,
+		userAgent: defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	api.buildClient()
+	return api
 }
 
-func New(key, secret string, live bool) *Api {
-	var url string
-	if live {
-		url = baseURL
-	} else {
-		url = sandboxURL
+func newLimiter(rateLimit config.RateLimit) *rate.Limiter {
+	if rateLimit.PerSecond <= 0 {
+		return nil
 	}
-	return &Api{url: url, key: key, secret: secret}
+	return rate.NewLimiter(rate.Limit(rateLimit.PerSecond), max(rateLimit.Burst, 1))
+}
+
+// waitForLimiter queues the request until it is let through by the limiter of its endpoint, i.e. private requests are
+// signed POST requests, public requests are GET requests. Fails instead of waiting beyond limiterWait
+func (api *Api) waitForLimiter(ctx context.Context, verb string) error {
+	limiter := api.publicLimiter
+	if verb != http.MethodGet {
+		limiter = api.privateLimiter
+	}
+	if limiter == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, api.limiterWait)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate_limiter_wait: %w", err)
+	}
+	return nil
 }
 
 // buildHeader handles the conversion of post parameters into headers formatted
@@ -56,12 +108,53 @@ func (api *Api) buildHeader(req map[string]any) http.Header {
 	return header
 }
 
+// Nonces of an Api without a nonce provider, e.g. in unit tests, are the current time in nanoseconds
+func (api *Api) nextNonce() (int64, error) {
+	if api.nonces == nil {
+		return time.Now().UnixNano(), nil
+	}
+	return api.nonces.Next()
+}
+
 // request makes the HTTP request to Gemini and handles any returned errors
-func (api *Api) request(verb, path string, params map[string]any) ([]byte, error) {
+//
+// A private request rejected for its nonce, e.g. as it was overtaken by a request with a later nonce on another
+// connection, is sent once more with a fresh nonce, as it had no effect
+func (api *Api) request(ctx context.Context, verb, path string, params map[string]any) ([]byte, error) {
 	location := "gemini.request"
+	if api.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, api.timeout)
+		defer cancel()
+	}
+
+	body, err := api.send(ctx, verb, path, params)
+	if verb != http.MethodGet && errors.Is(err, ErrInvalidNonce) {
+		logger.Warn(location, "Nonce of %s is rejected, resending with a fresh nonce", path)
+		body, err = api.send(ctx, verb, path, params)
+	}
+	return body, err
+}
+
+func (api *Api) send(ctx context.Context, verb, path string, params map[string]any) ([]byte, error) {
+	location := "gemini.send"
 	url := api.url + path
 
-	req, err := http.NewRequest(verb, url, bytes.NewBuffer([]byte{}))
+	if err := api.waitForLimiter(ctx, verb); err != nil {
+		return nil, err
+	}
+	// Only taken once let through by the rate limiter, the next nonce is only taken once this request is written
+	if verb != http.MethodGet && params != nil {
+		nonce, nonceCtx, release, err := api.nonceOrder.Take(ctx, api.nextNonce)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		ctx = nonceCtx
+		params["nonce"] = nonce
+	}
+
+	req, err := http.NewRequestWithContext(ctx, verb, url, bytes.NewBuffer([]byte{}))
 	if err != nil {
 		return nil, err
 	}
@@ -78,9 +171,16 @@ func (api *Api) request(verb, path string, params map[string]any) ([]byte, error
 		}
 	}
 
+	if api.userAgent != "" {
+		req.Header.Set("User-Agent", api.userAgent)
+	}
+
 	logger.Info(location, "request verb:%s, url:%s, params:%+v", verb, url, params)
 
-	client := &http.Client{}
+	client := api.client
+	if client == nil {
+		client = http.DefaultClient
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -95,27 +195,10 @@ func (api *Api) request(verb, path string, params map[string]any) ([]byte, error
 
 	logger.Info(location, "response.body: %v", string(body))
 
-	if resp.StatusCode != 200 {
-		statusCode := fmt.Sprintf("HTTP Status Code: %d", resp.StatusCode)
-		if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "API entry point has moved, see Location: header. Most likely an http: to https: redirect.")
-		} else if resp.StatusCode == 400 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "Auction not open or paused, ineligible timing, market not open, or the request was malformed; in the case of a private API request, missing or malformed Gemini private API authentication headers")
-		} else if resp.StatusCode == 403 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "The API key is missing the role necessary to access this private API endpoint")
-		} else if resp.StatusCode == 404 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "Unknown API entry point or Order not found")
-		} else if resp.StatusCode == 406 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "Insufficient Funds")
-		} else if resp.StatusCode == 429 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "Rate Limiting was applied")
-		} else if resp.StatusCode == 500 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "The server encountered an error")
-		} else if resp.StatusCode == 502 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "Technical issues are preventing the request from being satisfied")
-		} else if resp.StatusCode == 503 {
-			return nil, fmt.Errorf("%s --- %s", statusCode, "The exchange is down for maintenance")
-		}
+	if resp.StatusCode != http.StatusOK {
+		geminiErr := newError(resp.StatusCode, body)
+		geminiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return nil, geminiErr
 	}
 
 	return body, nil
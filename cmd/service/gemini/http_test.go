@@ -0,0 +1,293 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApi_request(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantErr    error
+		wantReason string
+	}{
+		{
+			name:       "ok",
+			statusCode: http.StatusOK,
+			body:       `["btcsgd"]`,
+			want:       `["btcsgd"]`,
+		},
+		{
+			name:       "error_insufficient_funds",
+			statusCode: http.StatusNotAcceptable,
+			body:       `{"result":"error","reason":"InsufficientFunds","message":"Failed to place buy order due to insufficient funds"}`,
+			wantErr:    ErrInsufficientFunds,
+			wantReason: "InsufficientFunds",
+		},
+		{
+			name:       "error_invalid_nonce",
+			statusCode: http.StatusBadRequest,
+			body:       `{"result":"error","reason":"InvalidNonce","message":"Nonce '1' has not increased since your last call to the Gemini API."}`,
+			wantErr:    ErrInvalidNonce,
+			wantReason: "InvalidNonce",
+		},
+		{
+			name:       "error_bad_request_unknown_reason",
+			statusCode: http.StatusBadRequest,
+			body:       `{"result":"error","reason":"InvalidQuantity","message":"Invalid quantity for symbol BTCSGD: 0"}`,
+			wantErr:    ErrBadRequest,
+			wantReason: "InvalidQuantity",
+		},
+		{
+			name:       "error_auth_unlisted_status",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"result":"error","reason":"InvalidSignature","message":"InvalidSignature"}`,
+			wantErr:    ErrAuth,
+			wantReason: "InvalidSignature",
+		},
+		{
+			name:       "error_rate_limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       `{"result":"error","reason":"RateLimited","message":"Requests were made too frequently."}`,
+			wantErr:    ErrRateLimited,
+			wantReason: "RateLimited",
+		},
+		{
+			name:       "error_maintenance_html_body",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `<html>down for maintenance</html>`,
+			wantErr:    ErrMaintenance,
+		},
+		{
+			name:       "error_gateway_timeout",
+			statusCode: http.StatusGatewayTimeout,
+			wantErr:    ErrServer,
+		},
+		{
+			name:       "error_unexpected_status",
+			statusCode: http.StatusNoContent,
+			wantErr:    ErrUnexpectedStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer httpmock.Reset()
+			responder := httpmock.NewStringResponder(tt.statusCode, tt.body)
+			httpmock.RegisterResponder(http.MethodPost, NewOrderURI, responder)
+
+			api := &Api{url: ""}
+			got, err := api.request(util.TestContext(), http.MethodPost, NewOrderURI, map[string]any{"request": NewOrderURI})
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, string(got))
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			var geminiErr *Error
+			if assert.True(t, errors.As(err, &geminiErr)) {
+				assert.Equal(t, tt.statusCode, geminiErr.StatusCode)
+				assert.Equal(t, tt.wantReason, geminiErr.Reason)
+			}
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestError_isRejected(t *testing.T) {
+	assert.True(t, exchange.IsRejected(newError(http.StatusNotAcceptable, nil)))
+	assert.True(t, exchange.IsRejected(newError(http.StatusBadRequest, []byte(`{"result":"error","reason":"InvalidNonce"}`))))
+	assert.False(t, exchange.IsRejected(newError(http.StatusInternalServerError, nil)))
+	assert.False(t, exchange.IsRejected(newError(http.StatusBadRequest, []byte(`{"result":"error","reason":"System"}`))))
+	assert.False(t, exchange.IsRejected(errors.New("connection reset by peer")))
+	assert.True(t, errors.Is(newError(http.StatusNotAcceptable, nil), exchange.ErrInsufficientFunds))
+	assert.True(t, errors.Is(newError(http.StatusUnauthorized, nil), exchange.ErrAuth))
+}
+
+func TestError_Retryable(t *testing.T) {
+	assert.False(t, newError(http.StatusNotAcceptable, nil).Retryable())
+	assert.False(t, newError(http.StatusForbidden, nil).Retryable())
+	assert.False(t, newError(http.StatusBadRequest, []byte(`{"result":"error","reason":"InvalidSignature"}`)).Retryable())
+	assert.False(t, newError(http.StatusNotFound, []byte(`{"result":"error","reason":"OrderNotFound"}`)).Retryable())
+	assert.True(t, newError(http.StatusBadRequest, []byte(`{"result":"error","reason":"InvalidNonce"}`)).Retryable())
+	assert.True(t, newError(http.StatusTooManyRequests, nil).Retryable())
+	assert.True(t, newError(http.StatusServiceUnavailable, nil).Retryable())
+}
+
+func TestApi_request_retryAfter(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	responder := httpmock.NewStringResponder(http.StatusTooManyRequests, ``).HeaderSet(http.Header{"Retry-After": {"3"}})
+	httpmock.RegisterResponder(http.MethodGet, SymbolsURI, responder)
+
+	api := &Api{url: ""}
+	_, err := api.request(util.TestContext(), http.MethodGet, SymbolsURI, nil)
+	var geminiErr *Error
+	if assert.True(t, errors.As(err, &geminiErr)) {
+		assert.Equal(t, 3*time.Second, geminiErr.RetryAfterDelay())
+	}
+}
+
+func TestApi_waitForLimiter(t *testing.T) {
+	api := New("key", "secret", WithRateLimits(config.GeminiRateLimits{
+		Private: config.RateLimit{PerSecond: 0.001, Burst: 1},
+	}, 10*time.Millisecond))
+
+	t.Run("public_not_limited", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, api.waitForLimiter(util.TestContext(), http.MethodGet))
+		}
+	})
+
+	t.Run("private_burst_then_timeout", func(t *testing.T) {
+		assert.NoError(t, api.waitForLimiter(util.TestContext(), http.MethodPost))
+		assert.Error(t, api.waitForLimiter(util.TestContext(), http.MethodPost))
+	})
+}
+
+func TestNew(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+	tests := []struct {
+		name string
+		opts []Option
+		want *Api
+	}{
+		{
+			name: "live",
+			want: &Api{url: baseURL, key: "key", secret: "secret", userAgent: defaultUserAgent},
+		},
+		{
+			name: "sandbox",
+			opts: []Option{WithSandbox()},
+			want: &Api{url: sandboxURL, key: "key", secret: "secret", userAgent: defaultUserAgent},
+		},
+		{
+			name: "base_url_client_timeout_user_agent",
+			opts: []Option{WithBaseURL("http://127.0.0.1:8080"), WithHTTPClient(client), WithTimeout(5 * time.Second), WithUserAgent("test")},
+			want: &Api{url: "http://127.0.0.1:8080", key: "key", secret: "secret", client: client, timeout: 5 * time.Second, userAgent: "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, New("key", "secret", tt.opts...))
+		})
+	}
+}
+
+func TestApi_request_middleware(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	var userAgent string
+	httpmock.RegisterResponder(http.MethodGet, SymbolsURI, func(req *http.Request) (*http.Response, error) {
+		userAgent = req.Header.Get("User-Agent")
+		return httpmock.NewStringResponse(http.StatusOK, `["btcsgd"]`), nil
+	})
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	api := New("key", "secret", WithBaseURL(""), WithUserAgent("test"), WithMiddleware(middleware("logging"), middleware("metrics")))
+
+	got, err := api.request(util.TestContext(), http.MethodGet, SymbolsURI, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `["btcsgd"]`, string(got))
+	assert.Equal(t, []string{"logging", "metrics"}, calls)
+	assert.Equal(t, "test", userAgent)
+}
+
+func TestApi_request_timeout(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder(http.MethodGet, SymbolsURI, func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done() // hung until abandoned
+		return nil, req.Context().Err()
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		api := New("key", "secret", WithBaseURL(""), WithTimeout(10*time.Millisecond))
+		_, err := api.request(util.TestContext(), http.MethodGet, SymbolsURI, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ctx_cancelled", func(t *testing.T) {
+		api := New("key", "secret", WithBaseURL(""))
+		ctx, cancel := context.WithCancel(util.TestContext())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := api.request(ctx, http.MethodGet, SymbolsURI, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestApi_request_invalidNonce(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name      string
+		responses []string // of every call, in order
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "ok_resent_with_fresh_nonce",
+			responses: []string{`{"result":"error","reason":"InvalidNonce"}`, `[]`},
+			wantCalls: 2,
+		},
+		{
+			name:      "error_rejected_again",
+			responses: []string{`{"result":"error","reason":"InvalidNonce"}`, `{"result":"error","reason":"InvalidNonce"}`},
+			wantCalls: 2,
+			wantErr:   ErrInvalidNonce,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer httpmock.Reset()
+			var payloads []string
+			httpmock.RegisterResponder(http.MethodPost, BalancesURI, func(req *http.Request) (*http.Response, error) {
+				payloads = append(payloads, req.Header.Get("X-GEMINI-PAYLOAD"))
+				body := tt.responses[len(payloads)-1]
+				if strings.Contains(body, "error") {
+					return httpmock.NewStringResponse(http.StatusBadRequest, body), nil
+				}
+				return httpmock.NewStringResponse(http.StatusOK, body), nil
+			})
+
+			api := New("key", "secret", WithBaseURL(""))
+			_, err := api.request(util.TestContext(), http.MethodPost, BalancesURI, map[string]any{"request": BalancesURI})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, payloads, tt.wantCalls)
+			assert.NotEqual(t, payloads[0], payloads[1], "should be resent with a fresh nonce")
+		})
+	}
+}
@@ -1,14 +1,84 @@
 package gemini
 
 import (
+	"context"
+
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
 var api *Api
 
 func MustInitClient() {
+	location := "gemini.MustInitClient"
 	c := config.Get().GeminiApi
-	api = New(c.ApiKey, c.ApiSecret, true)
+	nonces, err := NewNonceProvider(c)
+	if err != nil {
+		logger.Panic(location, "Failed to initialise nonces", err)
+	}
+	opts := []Option{
+		WithRateLimits(c.RateLimits, c.RateLimitWait),
+		WithNonceProvider(nonces),
+		WithTimeout(c.RequestTimeout),
+	}
+	if config.Get().IsSandboxEnv {
+		opts = append(opts, WithSandbox())
+	}
+	if c.BaseUrl != "" {
+		opts = append(opts, WithBaseURL(c.BaseUrl))
+	}
+	api = New(c.ApiKey, c.ApiSecret, opts...)
+	exchange.Set(config.ExchangeGemini, api)
+}
+
+// Trading pairs of the tickers bought on Gemini must be listed on Gemini
+func MustValidateTradingPairs(ctx context.Context) {
+	location := "gemini.MustValidateTradingPairs"
+	if err := api.ValidateTradingPairs(ctx, tickers()); err != nil {
+		logger.Panic(location, "Invalid trading pairs", err)
+	}
+}
+
+// Order updates are pushed over the order events websocket for as long as ctx is not done, unless they are configured
+// to be polled or no ticker is bought on Gemini
+func StartOrderEvents(ctx context.Context) {
+	location := "gemini.StartOrderEvents"
+	if config.Get().GeminiApi.OrderUpdates != config.OrderUpdatesWebsocket || len(tickers()) == 0 {
+		return
+	}
+	api.orderEvents = NewOrderEvents(api)
+	logger.Info(location, "Receiving order updates from %s", api.orderEvents.url)
+	go api.orderEvents.Run(ctx)
+}
+
+// Order books of the tickers bought on Gemini are streamed over the market data websocket for as long as ctx is not
+// done, unless market data is configured to be polled
+func StartMarketData(ctx context.Context) {
+	location := "gemini.StartMarketData"
+	tickers := tickers()
+	if config.Get().GeminiApi.MarketData != config.MarketDataWebsocket || len(tickers) == 0 {
+		return
+	}
+	symbols := make([]string, 0, len(tickers))
+	for _, ticker := range tickers {
+		symbols = append(symbols, AppendTickerWithQuoteCurrency(ticker))
+	}
+	api.marketData = NewMarketData(api, symbols)
+	logger.Info(location, "Streaming %v from %s", symbols, api.marketData.url)
+	go api.marketData.Run(ctx)
+}
+
+// Tickers bought on Gemini
+func tickers() []string {
+	c := config.Get()
+	var tickers []string
+	for ticker := range c.CryptoTickers {
+		if c.GetExchange(ticker) == config.ExchangeGemini {
+			tickers = append(tickers, ticker)
+		}
+	}
+	return tickers
 }
 
 func GetClient() *Api {
@@ -0,0 +1,44 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustInitClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		isSandboxEnv bool
+		baseUrl      string
+		want         string
+	}{
+		{
+			name: "production",
+			want: baseURL,
+		},
+		{
+			name:         "sandbox",
+			isSandboxEnv: true,
+			want:         sandboxURL,
+		},
+		{
+			name:         "base_url",
+			isSandboxEnv: true,
+			baseUrl:      "http://127.0.0.1:8080",
+			want:         "http://127.0.0.1:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.TestInit(&config.ConfigUpdateable{
+				IsSandboxEnv:  util.PtrOf(tt.isSandboxEnv),
+				GeminiBaseUrl: tt.baseUrl,
+			}, nil)
+			MustInitClient()
+			assert.Equal(t, tt.want, GetClient().url)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Client of the public market data websocket, which keeps the order book of every subscribed symbol up to date from
+// its level 2 updates
+type MarketData struct {
+	url     string
+	symbols []string // e.g. btcsgd
+	dialer  *websocket.Dialer
+
+	mu             sync.Mutex
+	connectedSince time.Time // zero while disconnected
+	books          map[string]*marketDataBook
+	bidSubs        map[string]map[chan float64]bool
+}
+
+// Price levels keyed by price, of amount
+type marketDataBook struct {
+	bids map[float64]float64
+	asks map[float64]float64
+}
+
+func NewMarketData(api *Api, symbols []string) *MarketData {
+	return &MarketData{
+		url:     websocketURL(api.url, MarketDataURI),
+		symbols: symbols,
+		dialer:  websocket.DefaultDialer,
+		books:   make(map[string]*marketDataBook),
+		bidSubs: make(map[string]map[chan float64]bool),
+	}
+}
+
+// Run reads order book updates until ctx is done, reconnecting whenever the connection is lost. Snapshots are not
+// available while disconnected, see Snapshot
+func (m *MarketData) Run(ctx context.Context) {
+	runWebsocket(ctx, "gemini.MarketData.Run", m.url, m.connect, m.read, m.setConnected)
+}
+
+func (m *MarketData) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := m.dialer.DialContext(ctx, m.url, nil)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("%w: %w", newError(resp.StatusCode, nil), err)
+		}
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(m.symbols))
+	for _, symbol := range m.symbols {
+		symbols = append(symbols, strings.ToUpper(symbol))
+	}
+	req := &MarketDataSubscribeRequest{
+		Type:          "subscribe",
+		Subscriptions: []MarketDataSubscription{{Name: "l2", Symbols: symbols}},
+	}
+	if err := conn.WriteJSON(req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Order books are rebuilt from scratch on every connection, as updates may have been missed while disconnected
+func (m *MarketData) read(ctx context.Context, conn *websocket.Conn) error {
+	location := "gemini.MarketData.read"
+	m.mu.Lock()
+	m.books = make(map[string]*marketDataBook)
+	m.mu.Unlock()
+	m.setConnected(true)
+
+	return readMessages(ctx, conn, func(message []byte) {
+		msg := &MarketDataMessage{}
+		if err := json.Unmarshal(message, msg); err != nil {
+			logger.Error(location, "Unable to parse message: %s", err, string(message))
+			return
+		}
+		if msg.Type != "l2_updates" {
+			return
+		}
+		if err := m.apply(strings.ToLower(msg.Symbol), msg.Changes); err != nil {
+			logger.Error(location, "Unable to apply changes: %s", err, string(message))
+		}
+	})
+}
+
+// Subscribers of the symbol are notified if its best bid changes
+func (m *MarketData) apply(symbol string, changes [][]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.books[symbol]
+	if !ok {
+		b = &marketDataBook{bids: make(map[float64]float64), asks: make(map[float64]float64)}
+		m.books[symbol] = b
+	}
+	prevBid, hadBid := b.bestBid()
+
+	for _, change := range changes {
+		if len(change) != 3 {
+			return fmt.Errorf("invalid_change: %v", change)
+		}
+		price, err := strconv.ParseFloat(change[1], 64)
+		if err != nil {
+			return err
+		}
+		amount, err := strconv.ParseFloat(change[2], 64)
+		if err != nil {
+			return err
+		}
+		levels := b.asks
+		if change[0] == "buy" {
+			levels = b.bids
+		}
+		if amount == 0 {
+			delete(levels, price)
+		} else {
+			levels[price] = amount
+		}
+	}
+
+	if bid, ok := b.bestBid(); ok && (!hadBid || bid != prevBid) {
+		for ch := range m.bidSubs[symbol] {
+			sendLatest(ch, bid)
+		}
+	}
+	return nil
+}
+
+// Top marketDataDepth price levels of the ticker, false while disconnected or if either side of the book is empty
+func (m *MarketData) Snapshot(ticker string) (*exchange.OrderBook, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.books[AppendTickerWithQuoteCurrency(ticker)]
+	if m.connectedSince.IsZero() || !ok || len(b.bids) == 0 || len(b.asks) == 0 {
+		return nil, false
+	}
+	return &exchange.OrderBook{
+		Bids: toOrderBookEntries(b.bids, func(a, b float64) bool { return a > b }),
+		Asks: toOrderBookEntries(b.asks, func(a, b float64) bool { return a < b }),
+	}, true
+}
+
+// Best bid of the ticker whenever it changes, starting with its current best bid if known, until unsubscribed
+func (m *MarketData) SubscribeBestBid(ticker string) (<-chan float64, func()) {
+	symbol := AppendTickerWithQuoteCurrency(ticker)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan float64, 1)
+	if b, ok := m.books[symbol]; ok && !m.connectedSince.IsZero() {
+		if bid, ok := b.bestBid(); ok {
+			ch <- bid
+		}
+	}
+	if m.bidSubs[symbol] == nil {
+		m.bidSubs[symbol] = make(map[chan float64]bool)
+	}
+	m.bidSubs[symbol][ch] = true
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.bidSubs[symbol], ch)
+	}
+}
+
+func (m *MarketData) setConnected(isConnected bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !isConnected {
+		m.connectedSince = time.Time{}
+	} else if m.connectedSince.IsZero() {
+		m.connectedSince = time.Now()
+	}
+}
+
+func (b *marketDataBook) bestBid() (float64, bool) {
+	best, ok := 0.0, false
+	for price := range b.bids {
+		if !ok || price > best {
+			best, ok = price, true
+		}
+	}
+	return best, ok
+}
+
+// Up to marketDataDepth levels, best first
+func toOrderBookEntries(levels map[float64]float64, isBetter func(a, b float64) bool) []exchange.OrderBookEntry {
+	prices := make([]float64, 0, len(levels))
+	for price := range levels {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, k int) bool { return isBetter(prices[i], prices[k]) })
+	if len(prices) > marketDataDepth {
+		prices = prices[:marketDataDepth]
+	}
+	entries := make([]exchange.OrderBookEntry, 0, len(prices))
+	for _, price := range prices {
+		entries = append(entries, exchange.OrderBookEntry{Price: price, Amount: levels[price]})
+	}
+	return entries
+}
+
+// The receiver only cares about the latest value, so a value it has yet to receive is replaced
+func sendLatest[T any](ch chan T, v T) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- v
+}
@@ -0,0 +1,143 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/stretchr/testify/assert"
+)
+
+// Market data of btcsgd with the changes applied, as if streamed
+func newTestMarketData(t *testing.T, isConnected bool, changes [][]string) *MarketData {
+	m := NewMarketData(&Api{url: ""}, []string{"btcsgd"})
+	m.setConnected(isConnected)
+	assert.NoError(t, m.apply("btcsgd", changes))
+	return m
+}
+
+func TestMarketData_Run(t *testing.T) {
+	config.TestInit(nil, nil)
+	subscriptions := make(chan *MarketDataSubscribeRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		req := &MarketDataSubscribeRequest{}
+		assert.NoError(t, conn.ReadJSON(req))
+		subscriptions <- req
+
+		for _, message := range []string{
+			`{"type":"heartbeat","timestamp":1700000000000}`,
+			`{"type":"l2_updates","symbol":"BTCSGD","changes":[["buy","100.00","1"],["buy","99.00","2"],["sell","101.00","3"]]}`,
+			`{"type":"trade","symbol":"BTCSGD","event_id":1,"timestamp":1700000000000,"price":"100.00","quantity":"1","side":"sell"}`,
+			`{"type":"l2_updates","symbol":"BTCSGD","changes":[["buy","100.00","0"]]}`,
+		} {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		// Held open until the client disconnects
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	m := NewMarketData(&Api{url: server.URL}, []string{"btcsgd"})
+	bids, unsubscribe := m.SubscribeBestBid("BTC")
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	assert.Equal(t, &MarketDataSubscribeRequest{
+		Type:          "subscribe",
+		Subscriptions: []MarketDataSubscription{{Name: "l2", Symbols: []string{"BTCSGD"}}},
+	}, <-subscriptions)
+
+	// The best bid of 100 is removed by the last update, it may have been replaced before it was received
+	for bid := 0.0; bid != 99; {
+		select {
+		case bid = <-bids:
+			assert.Contains(t, []float64{100, 99}, bid)
+		case <-time.After(5 * time.Second):
+			t.Fatal("best bid not received")
+		}
+	}
+	orderBook, ok := m.Snapshot("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, &exchange.OrderBook{
+		Bids: []exchange.OrderBookEntry{{Price: 99, Amount: 2}},
+		Asks: []exchange.OrderBookEntry{{Price: 101, Amount: 3}},
+	}, orderBook)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not stopped once ctx is done")
+	}
+	_, ok = m.Snapshot("BTC")
+	assert.False(t, ok)
+}
+
+func TestMarketData_apply(t *testing.T) {
+	config.TestInit(nil, nil)
+
+	t.Run("sorted_snapshot", func(t *testing.T) {
+		m := newTestMarketData(t, true, [][]string{
+			{"buy", "99", "1"}, {"buy", "100", "2"}, {"sell", "102", "3"}, {"sell", "101", "4"},
+		})
+		orderBook, ok := m.Snapshot("BTC")
+		assert.True(t, ok)
+		assert.Equal(t, &exchange.OrderBook{
+			Bids: []exchange.OrderBookEntry{{Price: 100, Amount: 2}, {Price: 99, Amount: 1}},
+			Asks: []exchange.OrderBookEntry{{Price: 101, Amount: 4}, {Price: 102, Amount: 3}},
+		}, orderBook)
+	})
+
+	t.Run("depth_limited", func(t *testing.T) {
+		var changes [][]string
+		for i := 0; i < marketDataDepth+10; i++ {
+			changes = append(changes, []string{"buy", fmt.Sprint(1000 - i), "1"}, []string{"sell", fmt.Sprint(2000 + i), "1"})
+		}
+		orderBook, ok := newTestMarketData(t, true, changes).Snapshot("BTC")
+		assert.True(t, ok)
+		assert.Len(t, orderBook.Bids, marketDataDepth)
+		assert.Len(t, orderBook.Asks, marketDataDepth)
+		assert.Equal(t, 1000.0, orderBook.Bids[0].Price)
+		assert.Equal(t, 2000.0, orderBook.Asks[0].Price)
+	})
+
+	t.Run("best_bid_only_on_change", func(t *testing.T) {
+		m := newTestMarketData(t, true, [][]string{{"buy", "100", "1"}, {"sell", "101", "1"}})
+		bids, unsubscribe := m.SubscribeBestBid("BTC")
+		defer unsubscribe()
+		assert.Equal(t, 100.0, <-bids)
+
+		assert.NoError(t, m.apply("btcsgd", [][]string{{"buy", "99", "1"}, {"sell", "101", "2"}}))
+		assert.Empty(t, bids)
+		assert.NoError(t, m.apply("btcsgd", [][]string{{"buy", "100.5", "1"}}))
+		assert.Equal(t, 100.5, <-bids)
+	})
+
+	t.Run("not_connected", func(t *testing.T) {
+		_, ok := newTestMarketData(t, false, [][]string{{"buy", "100", "1"}, {"sell", "101", "1"}}).Snapshot("BTC")
+		assert.False(t, ok)
+	})
+
+	t.Run("error_invalid_change", func(t *testing.T) {
+		m := NewMarketData(&Api{url: ""}, []string{"btcsgd"})
+		assert.Error(t, m.apply("btcsgd", [][]string{{"buy", "100"}}))
+		assert.Error(t, m.apply("btcsgd", [][]string{{"buy", "abc", "1"}}))
+	})
+}
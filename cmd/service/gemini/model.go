@@ -1,14 +1,12 @@
 package gemini
 
-import "time"
-
 type TickerDetails struct {
 	Symbol                string  `json:"symbol"`
 	BaseCurrency          string  `json:"base_currency"`
 	QuoteCurrency         string  `json:"quote_currency"`
 	TickSize              float64 `json:"tick_size"`
 	QuoteIncrement        float64 `json:"quote_increment"`
-	MinOrderSize          string  `json:"min_order_size"`
+	MinOrderSize          float64 `json:"min_order_size,string"`
 	Status                string  `json:"status"`
 	WrapEnabled           bool    `json:"wrap_enabled"`
 	ProductType           string  `json:"product_type"`
@@ -27,40 +25,6 @@ type TickerV2 struct {
 	Ask     float64  `json:"ask,string"`
 }
 
-type Order struct {
-	OrderID           string   `json:"order_id"`
-	ClientOrderID     string   `json:"client_order_id"`
-	Symbol            string   `json:"symbol"`
-	Exchange          string   `json:"exchange"`
-	Price             float64  `json:"price,string"`
-	AvgExecutionPrice float64  `json:"avg_execution_price,string"`
-	Side              string   `json:"side"`
-	Type              string   `json:"type"`
-	Options           []string `json:"options"`
-	Timestamp         string   `json:"timestamp"`
-	Timestampms       int64    `json:"timestampms"`
-	IsLive            bool     `json:"is_live"`
-	IsCancelled       bool     `json:"is_cancelled"`
-	Reason            string   `json:"reason"`
-	WasForced         bool     `json:"was_forced"`
-	ExecutedAmount    float64  `json:"executed_amount,string"`
-	RemainingAmount   float64  `json:"remaining_amount,string"`
-	OriginalAmount    float64  `json:"original_amount,string"`
-	IsHidden          bool     `json:"is_hidden"`
-}
-
-type Trade struct {
-	Timestamp    int64     `json:"timestamp"`
-	Timestampms  int64     `json:"timestampms"`
-	TimestampmsT time.Time `json:"timestampmst,omitempty"`
-	TradeID      int64     `json:"tid"`
-	Price        float64   `json:"price,string"`
-	Amount       float64   `json:"amount,string"`
-	Exchange     string    `json:"exchange"`
-	Type         string    `json:"type"`
-	Broken       bool      `json:"broken,omitempty"`
-}
-
 type CancelResult struct {
 	Result  string              `json:"result"`
 	Details CancelResultDetails `json:"details"`
@@ -78,3 +42,48 @@ type FundBalance struct {
 	AvailableForWithdrawal float64 `json:"availableForWithdrawal,string"`
 	Type                   string  `json:"type"`
 }
+
+// Update of an order on the order events websocket, of type e.g. initial, accepted, rejected, booked, fill, cancelled,
+// closed. Amounts are of the order so far, not of the update
+type OrderEvent struct {
+	Type              string  `json:"type"`
+	OrderID           string  `json:"order_id"`
+	ClientOrderID     string  `json:"client_order_id"`
+	Symbol            string  `json:"symbol"`
+	Side              string  `json:"side"`
+	OrderType         string  `json:"order_type"`
+	Timestampms       int64   `json:"timestampms"`
+	IsLive            bool    `json:"is_live"`
+	IsCancelled       bool    `json:"is_cancelled"`
+	Price             float64 `json:"price,string"`
+	AvgExecutionPrice float64 `json:"avg_execution_price,string"`
+	ExecutedAmount    float64 `json:"executed_amount,string"`
+	RemainingAmount   float64 `json:"remaining_amount,string"`
+	OriginalAmount    float64 `json:"original_amount,string"`
+	SocketSequence    int64   `json:"socket_sequence"`
+}
+
+// Subscribes to the level 2 updates, i.e. order book changes, of the symbols
+type MarketDataSubscribeRequest struct {
+	Type          string                   `json:"type"`
+	Subscriptions []MarketDataSubscription `json:"subscriptions"`
+}
+
+type MarketDataSubscription struct {
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+// Only level 2 updates are used, the first update of a symbol after subscribing is its whole order book
+type MarketDataMessage struct {
+	Type    string     `json:"type"`
+	Symbol  string     `json:"symbol"`
+	Changes [][]string `json:"changes"` // side, price & quantity, where a quantity of 0 removes the price level
+}
+
+// Body of every error response, e.g. {"result":"error","reason":"InsufficientFunds","message":"..."}
+type ErrorResponse struct {
+	Result  string `json:"result"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,24 @@
+package gemini
+
+import (
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+)
+
+// Nonces as per the nonce mode of the API key, see config.NonceModeIncreasing & config.NonceModeTimeWindow
+func NewNonceProvider(c config.GeminiApi) (exchange.NonceProvider, error) {
+	if c.NonceMode == config.NonceModeTimeWindow {
+		return &TimeWindowNonceProvider{}, nil
+	}
+	return exchange.NewIncreasingNonceProvider(c.NoncePath)
+}
+
+// Gemini's time based nonce, for API keys created with "Uses a time based nonce": the nonce is the current time in
+// seconds and is accepted within 30 seconds of Gemini's time, so nothing is persisted
+type TimeWindowNonceProvider struct{}
+
+func (p *TimeWindowNonceProvider) Next() (int64, error) {
+	return time.Now().Unix(), nil
+}
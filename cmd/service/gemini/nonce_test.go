@@ -0,0 +1,23 @@
+package gemini
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNonceProvider(t *testing.T) {
+	p, err := NewNonceProvider(config.GeminiApi{NonceMode: config.NonceModeTimeWindow})
+	assert.NoError(t, err)
+	nonce, err := p.Next()
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), nonce, 1)
+
+	p, err = NewNonceProvider(config.GeminiApi{NonceMode: config.NonceModeIncreasing, NoncePath: filepath.Join(t.TempDir(), "nonce.json")})
+	assert.NoError(t, err)
+	assert.IsType(t, &exchange.IncreasingNonceProvider{}, p)
+}
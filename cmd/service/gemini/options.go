@@ -0,0 +1,79 @@
+package gemini
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+)
+
+// Option configures the Api on New
+type Option func(*Api)
+
+type Middleware = exchange.Middleware
+
+// Requests are sent to the sandbox instead of the live API
+func WithSandbox() Option {
+	return func(api *Api) {
+		api.url = sandboxURL
+	}
+}
+
+// Requests are sent to the given URL instead of the live API, e.g. of a local fake exchange
+func WithBaseURL(url string) Option {
+	return func(api *Api) {
+		api.url = url
+	}
+}
+
+// Requests are sent with the given client instead of http.DefaultClient, the client's own timeout still applies
+func WithHTTPClient(client *http.Client) Option {
+	return func(api *Api) {
+		api.client = client
+	}
+}
+
+// Every request, including its wait for the rate limiter, fails once it takes longer than the timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(api *Api) {
+		api.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(api *Api) {
+		api.userAgent = userAgent
+	}
+}
+
+// Middlewares wrap the transport in the given order, i.e. the first middleware sees the request first
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(api *Api) {
+		api.middlewares = append(api.middlewares, middlewares...)
+	}
+}
+
+// Not rate limited unless given, a request fails instead of waiting beyond wait for the rate limiter
+func WithRateLimits(rateLimits config.GeminiRateLimits, wait time.Duration) Option {
+	return func(api *Api) {
+		api.publicLimiter = newLimiter(rateLimits.Public)
+		api.privateLimiter = newLimiter(rateLimits.Private)
+		api.limiterWait = wait
+	}
+}
+
+// Nonces of private requests are the current time in nanoseconds unless given
+func WithNonceProvider(nonces exchange.NonceProvider) Option {
+	return func(api *Api) {
+		api.nonces = nonces
+	}
+}
+
+// The client of the Api with its middlewares applied
+func (api *Api) buildClient() {
+	if len(api.middlewares) == 0 {
+		return
+	}
+	api.client = exchange.WrapClient(api.client, api.middlewares)
+}
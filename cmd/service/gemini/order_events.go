@@ -0,0 +1,176 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Client of the order events websocket, which pushes every update of the orders of the account, e.g. fills &
+// cancellations. Updates are passed on to the subscribers of the order
+type OrderEvents struct {
+	api    *Api // signs the subscription with its key, secret & nonces
+	url    string
+	dialer *websocket.Dialer
+
+	mu             sync.Mutex
+	connectedSince time.Time // zero while disconnected
+	subs           map[string]chan *exchange.Order
+	latest         map[string]*orderEventAt
+}
+
+type orderEventAt struct {
+	order *exchange.Order
+	at    time.Time
+}
+
+func NewOrderEvents(api *Api) *OrderEvents {
+	return &OrderEvents{
+		api:    api,
+		url:    websocketURL(api.url, OrderEventsURI),
+		dialer: websocket.DefaultDialer,
+		subs:   make(map[string]chan *exchange.Order),
+		latest: make(map[string]*orderEventAt),
+	}
+}
+
+// Run reads order events until ctx is done, reconnecting whenever the connection is lost. Subscribers fall back to
+// polling while disconnected, see ConnectedSince
+func (e *OrderEvents) Run(ctx context.Context) {
+	runWebsocket(ctx, "gemini.OrderEvents.Run", e.url, e.connect, e.read, e.setConnected)
+}
+
+// The subscription is authenticated with the same headers as private requests, its nonce is taken in order with theirs.
+// The handshake is not traced as written, the next nonce is only taken once its response starts instead
+func (e *OrderEvents) connect(ctx context.Context) (*websocket.Conn, error) {
+	nonce, nonceCtx, release, err := e.api.nonceOrder.Take(ctx, e.api.nextNonce)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	nonceCtx = httptrace.WithClientTrace(nonceCtx, &httptrace.ClientTrace{
+		GotFirstResponseByte: release,
+	})
+	header := e.api.buildHeader(map[string]any{"request": OrderEventsURI, "nonce": nonce})
+	header.Del("Content-Length")
+	header.Del("Content-Type")
+
+	conn, resp, err := e.dialer.DialContext(nonceCtx, e.url, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("%w: %w", newError(resp.StatusCode, nil), err)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (e *OrderEvents) read(ctx context.Context, conn *websocket.Conn) error {
+	location := "gemini.OrderEvents.read"
+	e.setConnected(true)
+	return readMessages(ctx, conn, func(message []byte) {
+		events, err := parseOrderEvents(message)
+		if err != nil {
+			logger.Error(location, "Unable to parse message: %s", err, string(message))
+			return
+		}
+		e.dispatch(events)
+	})
+}
+
+// Order events are sent as arrays, while heartbeats & the subscription acknowledgement are sent as objects
+func parseOrderEvents(message []byte) ([]*OrderEvent, error) {
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 || message[0] != '[' {
+		return nil, nil
+	}
+	var events []*OrderEvent
+	if err := json.Unmarshal(message, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Only the latest update of an order matters, as its amounts are of the order so far. A subscriber that has yet to
+// receive the previous update has it replaced
+func (e *OrderEvents) dispatch(events []*OrderEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	for orderID, latest := range e.latest {
+		if now.Sub(latest.at) > orderEventsLatestTTL {
+			delete(e.latest, orderID)
+		}
+	}
+	for _, event := range events {
+		if event == nil || event.OrderID == "" {
+			continue
+		}
+		order := event.toOrder()
+		e.latest[event.OrderID] = &orderEventAt{order: order, at: now}
+		if ch, ok := e.subs[event.OrderID]; ok {
+			sendLatest(ch, order)
+		}
+	}
+}
+
+func (e *OrderEvents) Subscribe(orderID string) (<-chan *exchange.Order, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ch := make(chan *exchange.Order, 1)
+	if latest, ok := e.latest[orderID]; ok {
+		ch <- latest.order
+	}
+	e.subs[orderID] = ch
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.subs[orderID] == ch {
+			delete(e.subs, orderID)
+		}
+	}
+}
+
+func (e *OrderEvents) ConnectedSince() (time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.connectedSince, !e.connectedSince.IsZero()
+}
+
+func (e *OrderEvents) setConnected(isConnected bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !isConnected {
+		e.connectedSince = time.Time{}
+	} else if e.connectedSince.IsZero() {
+		e.connectedSince = time.Now()
+	}
+}
+
+// A rejected order is never live, it is treated as cancelled
+func (event *OrderEvent) toOrder() *exchange.Order {
+	return &exchange.Order{
+		OrderID:           event.OrderID,
+		ClientOrderID:     event.ClientOrderID,
+		Symbol:            event.Symbol,
+		Exchange:          "gemini",
+		Price:             event.Price,
+		AvgExecutionPrice: event.AvgExecutionPrice,
+		Side:              event.Side,
+		Type:              event.OrderType,
+		Timestampms:       event.Timestampms,
+		IsLive:            event.IsLive,
+		IsCancelled:       event.IsCancelled || event.Type == "rejected",
+		ExecutedAmount:    event.ExecutedAmount,
+		RemainingAmount:   event.RemainingAmount,
+		OriginalAmount:    event.OriginalAmount,
+	}
+}
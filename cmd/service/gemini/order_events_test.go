@@ -0,0 +1,152 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderEvents_Run(t *testing.T) {
+	requests := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := base64.StdEncoding.DecodeString(r.Header.Get("X-GEMINI-PAYLOAD"))
+		assert.NoError(t, err)
+		var req map[string]any
+		assert.NoError(t, json.Unmarshal(payload, &req))
+		assert.Equal(t, "key", r.Header.Get("X-GEMINI-APIKEY"))
+		assert.NotEmpty(t, r.Header.Get("X-GEMINI-SIGNATURE"))
+		requests <- req
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		for _, message := range []string{
+			`{"type":"subscription_ack","accountId":1,"subscriptionId":"ws-order-events"}`,
+			`{"type":"heartbeat","timestampms":1700000000000,"sequence":0}`,
+			`[{"type":"fill","order_id":"1","symbol":"btcsgd","side":"buy","order_type":"exchange limit","timestampms":1700000000000,"is_live":false,"is_cancelled":false,"price":"100.00","avg_execution_price":"100.00","executed_amount":"0.1","remaining_amount":"0","original_amount":"0.1","socket_sequence":1}]`,
+		} {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		// Held open until the client disconnects
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	api := &Api{url: server.URL, key: "key", secret: "secret"}
+	e := NewOrderEvents(api)
+	events, unsubscribe := e.Subscribe("1")
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	req := <-requests
+	assert.Equal(t, OrderEventsURI, req["request"])
+	assert.NotZero(t, req["nonce"])
+
+	select {
+	case order := <-events:
+		assert.Equal(t, &exchange.Order{
+			OrderID:           "1",
+			Symbol:            "btcsgd",
+			Exchange:          "gemini",
+			Price:             100,
+			AvgExecutionPrice: 100,
+			Side:              "buy",
+			Type:              "exchange limit",
+			Timestampms:       1700000000000,
+			ExecutedAmount:    0.1,
+			OriginalAmount:    0.1,
+		}, order)
+	case <-time.After(5 * time.Second):
+		t.Fatal("order event not received")
+	}
+	_, isConnected := e.ConnectedSince()
+	assert.True(t, isConnected)
+
+	// The nonce of the subscription no longer holds up private requests
+	taken := make(chan struct{})
+	go func() {
+		_, _, release, _ := api.nonceOrder.Take(ctx, func() (int64, error) { return 0, nil })
+		release()
+		close(taken)
+	}()
+	select {
+	case <-taken:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nonce order not released once connected")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not stopped once ctx is done")
+	}
+	_, isConnected = e.ConnectedSince()
+	assert.False(t, isConnected)
+}
+
+func TestOrderEvents_dispatch(t *testing.T) {
+	e := NewOrderEvents(&Api{url: "https://api.gemini.com"})
+	assert.Equal(t, "wss://api.gemini.com"+OrderEventsURI, e.url)
+
+	t.Run("latest_before_subscribing", func(t *testing.T) {
+		e.dispatch([]*OrderEvent{{Type: "accepted", OrderID: "1", IsLive: true}})
+		events, unsubscribe := e.Subscribe("1")
+		defer unsubscribe()
+		assert.Equal(t, "1", (<-events).OrderID)
+	})
+
+	t.Run("latest_wins", func(t *testing.T) {
+		events, unsubscribe := e.Subscribe("2")
+		defer unsubscribe()
+		e.dispatch([]*OrderEvent{{Type: "accepted", OrderID: "2", IsLive: true}})
+		e.dispatch([]*OrderEvent{{Type: "fill", OrderID: "2", ExecutedAmount: 1}})
+		order := <-events
+		assert.False(t, order.IsLive)
+		assert.Equal(t, 1.0, order.ExecutedAmount)
+		assert.Empty(t, events)
+	})
+
+	t.Run("rejected_is_cancelled", func(t *testing.T) {
+		events, unsubscribe := e.Subscribe("3")
+		defer unsubscribe()
+		e.dispatch([]*OrderEvent{{Type: "rejected", OrderID: "3"}})
+		assert.True(t, (<-events).IsCancelled)
+	})
+
+	t.Run("unsubscribed", func(t *testing.T) {
+		_, unsubscribe := e.Subscribe("4")
+		unsubscribe()
+		e.dispatch([]*OrderEvent{{Type: "fill", OrderID: "4"}})
+		assert.NotContains(t, e.subs, "4")
+	})
+}
+
+func Test_parseOrderEvents(t *testing.T) {
+	events, err := parseOrderEvents([]byte(`{"type":"heartbeat"}`))
+	assert.NoError(t, err)
+	assert.Nil(t, events)
+
+	events, err = parseOrderEvents([]byte(`[{"type":"cancelled","order_id":"1","is_cancelled":true}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []*OrderEvent{{Type: "cancelled", OrderID: "1", IsCancelled: true}}, events)
+
+	_, err = parseOrderEvents([]byte(`[{"price":1}]`))
+	assert.Error(t, err)
+}
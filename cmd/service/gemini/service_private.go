@@ -1,36 +1,37 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
 // New Order
-func (api *Api) newOrder(ticker, price, amount string) (*Order, error) {
+func (api *Api) newOrder(ctx context.Context, ticker, clientOrderID, price, amount string, options []string) (*exchange.Order, error) {
 	location := "gemini.newOrder"
-	now := config.GetTime().NowTimestamp()
-	quoteCurrency := AppendTickerWithQuoteCurrency(ticker)
 	params := map[string]any{
 		"request":         NewOrderURI,
-		"nonce":           now,
-		"client_order_id": fmt.Sprintf("%v_%v", now, quoteCurrency),
-		"symbol":          quoteCurrency,
+		"client_order_id": clientOrderID,
+		"symbol":          AppendTickerWithQuoteCurrency(ticker),
 		"price":           price,
 		"amount":          amount,
 		"side":            "buy",
 		"type":            "exchange limit",
 	}
+	if len(options) > 0 {
+		params["options"] = options
+	}
 
 	logger.Info(location, "params:%+v", params)
 
-	order := &Order{}
+	order := &exchange.Order{}
 
-	body, err := api.request(http.MethodPost, NewOrderURI, params)
+	body, err := api.request(ctx, http.MethodPost, NewOrderURI, params)
 	if err != nil {
 		return nil, err
 	}
@@ -45,17 +46,15 @@ func (api *Api) newOrder(ticker, price, amount string) (*Order, error) {
 }
 
 // Get Active orders
-func (api *Api) getActiveOrders() ([]*Order, error) {
+func (api *Api) getActiveOrders(ctx context.Context) ([]*exchange.Order, error) {
 	location := "gemini.getActiveOrders"
-	now := config.GetTime().NowTimestamp()
 	params := map[string]any{
 		"request": ActiveOrdersURI,
-		"nonce":   now,
 	}
 
-	var orders []*Order
+	var orders []*exchange.Order
 
-	body, err := api.request(http.MethodPost, ActiveOrdersURI, params)
+	body, err := api.request(ctx, http.MethodPost, ActiveOrdersURI, params)
 	if err != nil {
 		return nil, err
 	}
@@ -69,20 +68,75 @@ func (api *Api) getActiveOrders() ([]*Order, error) {
 	return orders, nil
 }
 
+// Order History - closed orders of a symbol since a timestamp
+func (api *Api) getOrderHistory(ctx context.Context, ticker string, since time.Time) ([]*exchange.Order, error) {
+	location := "gemini.getOrderHistory"
+	params := map[string]any{
+		"request":      OrderHistoryURI,
+		"symbol":       AppendTickerWithQuoteCurrency(ticker),
+		"timestamp":    since.Unix(),
+		"limit_orders": OrderHistoryLimit,
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	var orders []*exchange.Order
+
+	body, err := api.request(ctx, http.MethodPost, OrderHistoryURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "orders: %v", util.SafeJsonDump(orders))
+
+	return orders, nil
+}
+
+// My Trades - trades of a symbol since a timestamp
+func (api *Api) getMyTrades(ctx context.Context, ticker string, since time.Time) ([]*exchange.Trade, error) {
+	location := "gemini.getMyTrades"
+	params := map[string]any{
+		"request":      MyTradesURI,
+		"symbol":       AppendTickerWithQuoteCurrency(ticker),
+		"timestamp":    since.Unix(),
+		"limit_trades": MyTradesLimit,
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	var trades []*exchange.Trade
+
+	body, err := api.request(ctx, http.MethodPost, MyTradesURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, &trades); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "trades: %v", util.SafeJsonDump(trades))
+
+	return trades, nil
+}
+
 // Order Status
-func (api *Api) orderStatus(orderID string) (*Order, error) {
+func (api *Api) orderStatus(ctx context.Context, orderID string) (*exchange.Order, error) {
 	location := "gemini.orderStatus"
 	params := map[string]any{
 		"request":  OrderStatusURI,
-		"nonce":    config.GetTime().NowTimestamp(),
 		"order_id": orderID,
 	}
 
 	logger.Info(location, "params:%+v", params)
 
-	order := &Order{}
+	order := &exchange.Order{}
 
-	body, err := api.request(http.MethodPost, OrderStatusURI, params)
+	body, err := api.request(ctx, http.MethodPost, OrderStatusURI, params)
 	if err != nil {
 		return nil, err
 	}
@@ -96,20 +150,45 @@ func (api *Api) orderStatus(orderID string) (*Order, error) {
 	return order, nil
 }
 
+// Order Status by client order id - unlike by order id, all orders sharing the client order id are returned
+func (api *Api) orderStatusByClientOrderID(ctx context.Context, clientOrderID string) ([]*exchange.Order, error) {
+	location := "gemini.orderStatusByClientOrderID"
+	params := map[string]any{
+		"request":         OrderStatusURI,
+		"client_order_id": clientOrderID,
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	var orders []*exchange.Order
+
+	body, err := api.request(ctx, http.MethodPost, OrderStatusURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "orders: %v", util.SafeJsonDump(orders))
+
+	return orders, nil
+}
+
 // Cancel Order
-func (api *Api) cancelOrder(orderID string) (*Order, error) {
+func (api *Api) cancelOrder(ctx context.Context, orderID string) (*exchange.Order, error) {
 	location := "gemini.cancelOrder"
 	params := map[string]any{
 		"request":  CancelOrderURI,
-		"nonce":    config.GetTime().NowTimestamp(),
 		"order_id": orderID,
 	}
 
 	logger.Info(location, "params:%+v", params)
 
-	order := &Order{}
+	order := &exchange.Order{}
 
-	body, err := api.request(http.MethodPost, CancelOrderURI, params)
+	body, err := api.request(ctx, http.MethodPost, CancelOrderURI, params)
 	if err != nil {
 		return nil, err
 	}
@@ -122,3 +201,26 @@ func (api *Api) cancelOrder(orderID string) (*Order, error) {
 
 	return order, nil
 }
+
+// Available Balances
+func (api *Api) getBalances(ctx context.Context) ([]*FundBalance, error) {
+	location := "gemini.getBalances"
+	params := map[string]any{
+		"request": BalancesURI,
+	}
+
+	var balances []*FundBalance
+
+	body, err := api.request(ctx, http.MethodPost, BalancesURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, &balances); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "balances: %v", util.SafeJsonDump(balances))
+
+	return balances, nil
+}
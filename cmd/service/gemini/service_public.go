@@ -1,15 +1,39 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
+// Symbols - every trading pair on the exchange, e.g. btcsgd
+func (api *Api) symbols(ctx context.Context) ([]string, error) {
+	location := "gemini.symbols"
+
+	logger.Info(location, "path:%s", SymbolsURI)
+
+	var symbols []string
+
+	body, err := api.request(ctx, http.MethodGet, SymbolsURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, &symbols); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "symbols: %v", symbols)
+
+	return symbols, nil
+}
+
 // Ticker Details
-func (api *Api) tickerDetails(ticker string) (TickerDetails, error) {
+func (api *Api) tickerDetails(ctx context.Context, ticker string) (TickerDetails, error) {
 	location := "gemini.tickerDetails"
 	quoteCurrency := AppendTickerWithQuoteCurrency(ticker)
 	path := fmt.Sprintf(TickerDetailsURI, quoteCurrency)
@@ -18,7 +42,7 @@ func (api *Api) tickerDetails(ticker string) (TickerDetails, error) {
 
 	var tickerDetails TickerDetails
 
-	body, err := api.request(http.MethodGet, path, nil)
+	body, err := api.request(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return tickerDetails, err
 	}
@@ -33,7 +57,7 @@ func (api *Api) tickerDetails(ticker string) (TickerDetails, error) {
 }
 
 // TickerV2
-func (api *Api) tickerV2(ticker string) (TickerV2, error) {
+func (api *Api) tickerV2(ctx context.Context, ticker string) (TickerV2, error) {
 	location := "gemini.tickerV2"
 	quoteCurrency := AppendTickerWithQuoteCurrency(ticker)
 	path := fmt.Sprintf(TickerV2URI, quoteCurrency)
@@ -42,7 +66,7 @@ func (api *Api) tickerV2(ticker string) (TickerV2, error) {
 
 	var tickerV2 TickerV2
 
-	body, err := api.request(http.MethodGet, path, nil)
+	body, err := api.request(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return tickerV2, err
 	}
@@ -55,3 +79,31 @@ func (api *Api) tickerV2(ticker string) (TickerV2, error) {
 
 	return tickerV2, nil
 }
+
+// Order Book
+func (api *Api) orderBook(ctx context.Context, ticker string) (*exchange.OrderBook, error) {
+	location := "gemini.orderBook"
+	quoteCurrency := AppendTickerWithQuoteCurrency(ticker)
+	path := fmt.Sprintf(OrderBookURI, quoteCurrency)
+	params := map[string]any{
+		"limit_bids": OrderBookLimit,
+		"limit_asks": OrderBookLimit,
+	}
+
+	logger.Info(location, "path:%s, params:%+v", path, params)
+
+	orderBook := &exchange.OrderBook{}
+
+	body, err := api.request(ctx, http.MethodGet, path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, orderBook); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "orderBook: %+v", orderBook)
+
+	return orderBook, nil
+}
@@ -1,79 +1,33 @@
 package gemini
 
 import (
-	"context"
-	"fmt"
-	"reflect"
 	"strings"
-	"time"
 
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
-	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 	"github.com/shopspring/decimal"
 )
 
-// Expect all functions to have error as last return value
-func RetryWrapper(ctx context.Context, fnName string, fn any, args ...any) ([]reflect.Value, error) {
-	fnValue := reflect.ValueOf(fn)
-	fnType := fnValue.Type()
-
-	if fnType.Kind() != reflect.Func {
-		return nil, fmt.Errorf("expected a function, got %s", fnType.Kind())
-	}
-
-	if len(args) != fnType.NumIn() {
-		return nil, fmt.Errorf("argument count mismatch: expected %d, got %d", fnType.NumIn(), len(args))
-	}
-	in := make([]reflect.Value, len(args))
-	for i, arg := range args {
-		in[i] = reflect.ValueOf(arg)
-	}
-
-	var err error
-	var ok bool
-	for i := 0; i < MaxRetryCount; i++ {
-		results := fnValue.Call(in)
-
-		errorResult := results[len(results)-1]
-		if errorResult.IsNil() {
-			return results[:len(results)-1], nil
-		}
-		err, ok = errorResult.Interface().(error)
-		if !ok {
-			err := fmt.Errorf("invalid_return_type")
-			logger.Error(fnName, "Last return type is not error, is %v instead", err, errorResult.Type().String())
-			return nil, err
-		}
-		if err == nil {
-			return results[:len(results)-1], nil
-		}
-
-		logger.Error(fnName, "Has some error, retrying in 5 seconds", err)
-		if !util.IsTestFlow(ctx) {
-			time.Sleep(5 * time.Second)
-		}
-	}
-
-	logger.Error(fnName, "Retried %v times, returning error\n", err, MaxRetryCount)
-	return nil, err
-}
-
 // return orderPriceStr, orderAmountStr
-func formCreateOrderReq(ticker string, bestBid float64, quoteIncrement, tickSize int) (string, string) {
-	orderMetadata := config.Get().OrderMetadata
-	orderPrice := bestBid * orderMetadata.OrderPriceToBidPriceRatio
-	orderAmount := decimal.NewFromFloat(orderMetadata.DailyFiatAmount[ticker]).Div(decimal.NewFromFloat(orderPrice))
+func formCreateOrderReq(orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (string, string) {
+	orderAmount := decimal.NewFromFloat(fiatAmount).Div(decimal.NewFromFloat(orderPrice))
 	orderPriceStr := util.ConvertFloatToPrecString(orderPrice, quoteIncrement)
 	orderAmountStr := util.ConvertFloatToPrecString(orderAmount, tickSize)
 
 	return orderPriceStr, orderAmountStr
 }
 
-// To hardcode this, since metadata does not change
-func AppendTickerWithQuoteCurrency(ticker string) string {
-	if ticker == BTC || ticker == ETH {
-		return strings.ToLower(ticker + SGD)
+func matchClientOrderID(orders []*exchange.Order, ticker, clientOrderID string) *exchange.Order {
+	for _, order := range orders {
+		if order != nil && order.ClientOrderID == clientOrderID && order.Symbol == AppendTickerWithQuoteCurrency(ticker) && order.Side == "buy" {
+			return order
+		}
 	}
-	return strings.ToLower(ticker + USD)
+	return nil
+}
+
+// Symbol as accepted by Gemini, e.g. btcsgd
+func AppendTickerWithQuoteCurrency(ticker string) string {
+	return strings.ToLower(ticker + config.Get().GetQuoteCurrency(ticker))
 }
@@ -0,0 +1,61 @@
+package gemini
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Websocket of the same host as the REST API, e.g. wss://api.gemini.com/v2/marketdata
+func websocketURL(apiURL, path string) string {
+	return "ws" + strings.TrimPrefix(apiURL, "http") + path
+}
+
+// runWebsocket connects and reads messages until ctx is done, reconnecting with exponential backoff whenever the
+// connection is lost. setConnected is called with false once disconnected
+func runWebsocket(ctx context.Context, location, url string, connect func(context.Context) (*websocket.Conn, error), read func(context.Context, *websocket.Conn) error, setConnected func(bool)) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		conn, err := connect(ctx)
+		if err == nil {
+			logger.Info(location, "Connected to %s", url)
+			backoff = time.Second
+			err = read(ctx, conn)
+			conn.Close()
+		}
+		setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error(location, "Disconnected, reconnecting in %v", err, backoff)
+		if err := util.Sleep(ctx, backoff); err != nil {
+			return
+		}
+		backoff = min(2*backoff, websocketMaxBackoff)
+	}
+}
+
+// readMessages passes every message to handle until the connection fails, or no message, not even a heartbeat, is
+// received in time
+func readMessages(ctx context.Context, conn *websocket.Conn, handle func([]byte)) error {
+	// Unblocks the read once ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(websocketReadTimeout)); err != nil {
+			return err
+		}
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		handle(message)
+	}
+}
@@ -0,0 +1,67 @@
+package journal
+
+import (
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+	"github.com/supabase-community/postgrest-go"
+	supabase "github.com/supabase-community/supabase-go"
+)
+
+// Unique column of the Journal table, see migrations
+const journalUniqueColumns = "ticker"
+
+// Journal stored in the Journal table of the db, one row per ticker, so that it survives ephemeral filesystems
+type DbJournal struct {
+	db *supabase.Client
+}
+
+func NewDbJournal(db *supabase.Client) *DbJournal {
+	return &DbJournal{db: db}
+}
+
+func (Entry) TableName() string {
+	return "Journal"
+}
+
+func (j *DbJournal) Get(ticker string) (*Entry, error) {
+	location := "journal.DbJournal.Get"
+	var rows []*Entry
+	if _, err := j.db.From(Entry{}.TableName()).Select("*", "", false).Eq("ticker", ticker).ExecuteTo(&rows); err != nil {
+		logger.Error(location, "Failed to get entry of '%s'", err, ticker)
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// Sorted by ticker
+func (j *DbJournal) GetAll() ([]*Entry, error) {
+	location := "journal.DbJournal.GetAll"
+	rows := make([]*Entry, 0)
+	if _, err := j.db.From(Entry{}.TableName()).Select("*", "", false).
+		Order("ticker", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&rows); err != nil {
+		logger.Error(location, "Failed to get entries", err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (j *DbJournal) Put(entry *Entry) error {
+	location := "journal.DbJournal.Put"
+	if _, _, err := j.db.From(Entry{}.TableName()).Upsert(entry, journalUniqueColumns, "minimal", "").Execute(); err != nil {
+		logger.Error(location, "Failed to upsert entry of '%s'", err, entry.Ticker)
+		return err
+	}
+	return nil
+}
+
+func (j *DbJournal) Delete(ticker string) error {
+	location := "journal.DbJournal.Delete"
+	if _, _, err := j.db.From(Entry{}.TableName()).Delete("minimal", "").Eq("ticker", ticker).Execute(); err != nil {
+		logger.Error(location, "Failed to delete entry of '%s'", err, ticker)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	supabase "github.com/supabase-community/supabase-go"
+)
+
+// Serves the Journal table as PostgREST does, for the requests made by DbJournal only
+func newTestPostgrest(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	rows := make(map[string]json.RawMessage)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "/rest/v1/Journal", r.URL.Path)
+		ticker := strings.TrimPrefix(r.URL.Query().Get("ticker"), "eq.")
+		switch r.Method {
+		case http.MethodGet:
+			tickers := make([]string, 0, len(rows))
+			for k := range rows {
+				if ticker == "" || k == ticker {
+					tickers = append(tickers, k)
+				}
+			}
+			sort.Strings(tickers)
+			got := make([]json.RawMessage, 0, len(tickers))
+			for _, k := range tickers {
+				got = append(got, rows[k])
+			}
+			_ = json.NewEncoder(w).Encode(got)
+		case http.MethodPost:
+			assert.Equal(t, journalUniqueColumns, r.URL.Query().Get("on_conflict"))
+			assert.Contains(t, r.Header.Get("Prefer"), "resolution=merge-duplicates")
+			var row json.RawMessage
+			_ = json.NewDecoder(r.Body).Decode(&row)
+			var entry Entry
+			_ = json.Unmarshal(row, &entry)
+			rows[entry.Ticker] = row
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			delete(rows, ticker)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestDbJournal(t *testing.T) {
+	server := newTestPostgrest(t)
+	defer server.Close()
+	client, err := supabase.NewClient(server.URL, "db_api_key", &supabase.ClientOptions{})
+	assert.NoError(t, err)
+	j := NewDbJournal(client)
+	day := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	got, err := j.Get("BTC")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	btc := &Entry{Ticker: "BTC", CreatedForDay: day, OrderID: "106817811", ExecutedAmount: 0.001, FiatSpent: 1, OrderIDs: []string{"106817810"}}
+	eth := &Entry{Ticker: "ETH", CreatedForDay: day, IsCompleted: true}
+	assert.NoError(t, j.Put(eth))
+	assert.NoError(t, j.Put(btc))
+
+	// Survives a fresh instance, as if on a fresh dyno
+	j = NewDbJournal(client)
+	got, err = j.Get("BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, btc, got)
+
+	all, err := j.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Entry{btc, eth}, all)
+
+	assert.NoError(t, j.Delete("BTC"))
+	assert.NoError(t, j.Delete("SOL"))
+	all, err = j.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Entry{eth}, all)
+}
+
+func TestDbJournal_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"42P01","message":"relation \"public.Journal\" does not exist"}`))
+	}))
+	defer server.Close()
+	client, err := supabase.NewClient(server.URL, "db_api_key", &supabase.ClientOptions{})
+	assert.NoError(t, err)
+	j := NewDbJournal(client)
+
+	_, err = j.Get("BTC")
+	assert.Error(t, err)
+	_, err = j.GetAll()
+	assert.Error(t, err)
+	assert.Error(t, j.Put(&Entry{Ticker: "BTC"}))
+	assert.Error(t, j.Delete("BTC"))
+}
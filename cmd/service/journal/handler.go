@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Journal stored as a single json file, which is rewritten atomically on every change
+type FileJournal struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileJournal(path string) *FileJournal {
+	return &FileJournal{path: path}
+}
+
+func (j *FileJournal) Get(ticker string) (*Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+	return entries[ticker], nil
+}
+
+// Sorted by ticker
+func (j *FileJournal) GetAll() ([]*Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+	all := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		all = append(all, entry)
+	}
+	sort.Slice(all, func(i, k int) bool {
+		return all[i].Ticker < all[k].Ticker
+	})
+	return all, nil
+}
+
+func (j *FileJournal) Put(entry *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+	entries[entry.Ticker] = entry
+	return j.write(entries)
+}
+
+func (j *FileJournal) Delete(ticker string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[ticker]; !ok {
+		return nil
+	}
+	delete(entries, ticker)
+	return j.write(entries)
+}
+
+// A missing journal file is an empty journal
+func (j *FileJournal) read() (map[string]*Entry, error) {
+	location := "journal.read"
+	entries := make(map[string]*Entry)
+	b, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		logger.Error(location, "Failed to read journal file '%s'", err, j.path)
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		logger.Error(location, "Failed to unmarshal journal file '%s'", err, j.path)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Written atomically, so that a crash mid-write cannot corrupt the journal
+func (j *FileJournal) write(entries map[string]*Entry) error {
+	location := "journal.write"
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomic(j.path, b); err != nil {
+		logger.Error(location, "Failed to write journal file '%s'", err, j.path)
+		return err
+	}
+	return nil
+}
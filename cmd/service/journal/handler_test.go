@@ -0,0 +1,55 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	j := NewFileJournal(path)
+	day := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	// Missing file is an empty journal
+	got, err := j.Get("BTC")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	btc := &Entry{Ticker: "BTC", CreatedForDay: day, OrderID: "106817811", ExecutedAmount: 0.001, FiatSpent: 1}
+	eth := &Entry{Ticker: "ETH", CreatedForDay: day, IsCompleted: true}
+	assert.NoError(t, j.Put(eth))
+	assert.NoError(t, j.Put(btc))
+
+	// Survives a fresh instance, as if after a crash
+	j = NewFileJournal(path)
+	got, err = j.Get("BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, btc, got)
+
+	all, err := j.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Entry{btc, eth}, all)
+
+	assert.NoError(t, j.Delete("BTC"))
+	assert.NoError(t, j.Delete("SOL"))
+	all, err = j.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Entry{eth}, all)
+
+	// No temp files left behind
+	files, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestFileJournal_corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := NewFileJournal(path).GetAll()
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package journal
+
+import (
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+	supabase "github.com/supabase-community/supabase-go"
+)
+
+// Persists the progress of every ticker's order loop, so that a crashed run can be resumed
+type JournalRepository interface {
+	Get(ticker string) (*Entry, error)
+	GetAll() ([]*Entry, error)
+	Put(entry *Entry) error
+	Delete(ticker string) error
+}
+
+var orderJournal JournalRepository
+
+func MustInit() {
+	location := "journal.MustInit"
+	c := config.Get()
+	if c.Journal.Store == config.JournalStoreFile {
+		Set(NewFileJournal(c.Journal.Path))
+		return
+	}
+	client, err := supabase.NewClient(c.Db.ApiUrl, c.Db.ApiKey, &supabase.ClientOptions{})
+	if err != nil {
+		logger.Panic(location, "Failed to initialise journal", err)
+	}
+	Set(NewDbJournal(client))
+}
+
+func Get() JournalRepository {
+	return orderJournal
+}
+
+func Set(j JournalRepository) {
+	orderJournal = j
+}
@@ -0,0 +1,23 @@
+package journal
+
+import "time"
+
+// One entry per ticker
+//
+// OrderID is empty in between order windows, i.e. when there is no live order on the exchange. ClientOrderID without
+// an OrderID is that of an order being created, which may or may not have reached the exchange
+type Entry struct {
+	Ticker                            string    `json:"ticker"`
+	CreatedForDay                     time.Time `json:"createdForDay"`
+	OrderID                           string    `json:"orderId"`
+	ClientOrderID                     string    `json:"clientOrderId"`
+	OrderOpenThenCancelWindowCounter  int       `json:"orderOpenThenCancelWindowCounter"`
+	OrderOpenQueryStatusWindowCounter int       `json:"orderOpenQueryStatusWindowCounter"`
+	ExecutedAmount                    float64   `json:"executedAmount"`
+	FiatSpent                         float64   `json:"fiatSpent"`
+	OrderIDs                          []string  `json:"orderIds"`
+	FeeAmount                         float64   `json:"feeAmount"`   // only set once reconciled with the trade history
+	FeeCurrency                       string    `json:"feeCurrency"` // only set once reconciled with the trade history
+	IsCompleted                       bool      `json:"isCompleted"` // order loop has ended, pending write to google sheets & db
+	UpdatedAt                         time.Time `json:"updatedAt"`
+}
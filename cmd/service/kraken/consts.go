@@ -0,0 +1,48 @@
+package kraken
+
+const (
+	baseURL = "https://api.kraken.com"
+
+	// public
+	AssetPairsURI = "/0/public/AssetPairs"
+	TickerURI     = "/0/public/Ticker"
+	DepthURI      = "/0/public/Depth"
+
+	// authenticated
+	AddOrderURI      = "/0/private/AddOrder"
+	OpenOrdersURI    = "/0/private/OpenOrders"
+	ClosedOrdersURI  = "/0/private/ClosedOrders"
+	QueryOrdersURI   = "/0/private/QueryOrders"
+	CancelOrderURI   = "/0/private/CancelOrder"
+	TradesHistoryURI = "/0/private/TradesHistory"
+	BalanceExURI     = "/0/private/BalanceEx"
+)
+
+const (
+	MakerTradingFee float64 = 0.0025
+)
+
+const (
+	defaultUserAgent = "crypto_dca_go"
+)
+
+const (
+	TimeInForceImmediateOrCancel = "IOC" // fills what it can immediately, cancels the rest
+)
+
+const (
+	DepthCount = "50" // number of price levels to fetch on each side of the order book
+)
+
+// Order statuses
+const (
+	OrderStatusPending  = "pending"
+	OrderStatusOpen     = "open"
+	OrderStatusClosed   = "closed"
+	OrderStatusCanceled = "canceled"
+	OrderStatusExpired  = "expired"
+)
+
+const (
+	exchangeName = "kraken"
+)
@@ -0,0 +1,88 @@
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+)
+
+// Classes of errors returned by Kraken, to be checked with errors.Is. Those of rejected requests wrap the classes of
+// exchange, see exchange.IsRejected
+var (
+	ErrBadRequest        = fmt.Errorf("kraken_bad_request: %w", exchange.ErrRejected)
+	ErrAuth              = fmt.Errorf("kraken_auth: %w", exchange.ErrAuth)
+	ErrNotFound          = fmt.Errorf("kraken_not_found: %w", exchange.ErrRejected)
+	ErrInsufficientFunds = fmt.Errorf("kraken_insufficient_funds: %w", exchange.ErrInsufficientFunds)
+	ErrInvalidNonce      = fmt.Errorf("kraken_invalid_nonce: %w", exchange.ErrRejected)
+	ErrRateLimited       = fmt.Errorf("kraken_rate_limited: %w", exchange.ErrRejected)
+	ErrServer            = errors.New("kraken_server")
+	ErrUnknown           = errors.New("kraken_unknown")
+)
+
+// Error of a response with errors, which Kraken reports as strings of "<severity><category>:<message>", e.g.
+// "EOrder:Insufficient funds"
+type Error struct {
+	Messages []string
+	class    error
+}
+
+func (e *Error) Error() string {
+	return strings.Join(e.Messages, ", ")
+}
+
+func (e *Error) Unwrap() error {
+	return e.class
+}
+
+// Retrying does not help with a request that is rejected for its content, its credentials or an unknown order
+func (e *Error) Retryable() bool {
+	for _, class := range []error{ErrBadRequest, ErrAuth, ErrNotFound, ErrInsufficientFunds} {
+		if errors.Is(e.class, class) {
+			return false
+		}
+	}
+	return true
+}
+
+// Errors are matched by prefix, as some are followed by details, e.g. "EGeneral:Invalid arguments:volume"
+var errorClasses = map[string]error{
+	"EOrder:Insufficient funds":           ErrInsufficientFunds,
+	"EOrder:Insufficient margin":          ErrInsufficientFunds,
+	"EAPI:Invalid key":                    ErrAuth,
+	"EAPI:Invalid signature":              ErrAuth,
+	"EAPI:Feature disabled":               ErrAuth,
+	"EGeneral:Permission denied":          ErrAuth,
+	"EAPI:Invalid nonce":                  ErrInvalidNonce,
+	"EAPI:Rate limit exceeded":            ErrRateLimited,
+	"EOrder:Rate limit exceeded":          ErrRateLimited,
+	"EGeneral:Too many requests":          ErrRateLimited,
+	"EGeneral:Invalid arguments":          ErrBadRequest,
+	"EGeneral:Unknown method":             ErrBadRequest,
+	"EQuery:Unknown asset pair":           ErrBadRequest,
+	"EOrder:Order minimum not met":        ErrBadRequest,
+	"EOrder:Cost minimum not met":         ErrBadRequest,
+	"EOrder:Tick size check failed":       ErrBadRequest,
+	"EOrder:Invalid price":                ErrBadRequest,
+	"EOrder:Unknown order":                ErrNotFound,
+	"EService:Unavailable":                ErrServer,
+	"EService:Busy":                       ErrServer,
+	"EService:Deadline elapsed":           ErrServer,
+	"EService:Market in cancel_only mode": ErrServer,
+	"EGeneral:Internal error":             ErrServer,
+}
+
+// newError classifies the errors of a response by the first one known
+func newError(messages []string) *Error {
+	e := &Error{Messages: messages, class: ErrUnknown}
+	for _, message := range messages {
+		for prefix, class := range errorClasses {
+			if strings.HasPrefix(message, prefix) {
+				e.class = class
+				return e
+			}
+		}
+	}
+	return e
+}
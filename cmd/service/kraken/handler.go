@@ -0,0 +1,239 @@
+package kraken
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+func (api *Api) GetMakerTradingFee() float64 {
+	return MakerTradingFee
+}
+
+func (api *Api) GetQuoteIncrementAndTickSize(ctx context.Context, ticker string) (int, int, error) {
+	location := "kraken.GetQuoteIncrementAndTickSize"
+	assetPair, err := api.assetPair(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, 0, err
+	}
+	return assetPair.PairDecimals, assetPair.LotDecimals, nil
+}
+
+func (api *Api) GetMinOrderSize(ctx context.Context, ticker string) (float64, error) {
+	location := "kraken.GetMinOrderSize"
+	assetPair, err := api.assetPair(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, err
+	}
+	return assetPair.OrderMin, nil
+}
+
+func (api *Api) GetTickerBestBidPrice(ctx context.Context, ticker string) (float64, error) {
+	location := "kraken.GetTickerBestBidPrice"
+	bestBid, _, err := api.GetTickerBestBidAskPrice(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, err
+	}
+	return bestBid, nil
+}
+
+func (api *Api) GetTickerBestBidAskPrice(ctx context.Context, ticker string) (float64, float64, error) {
+	location := "kraken.GetTickerBestBidAskPrice"
+	tickerInfo, err := api.ticker(ctx, ticker)
+	if err == nil && (len(tickerInfo.Bid) == 0 || len(tickerInfo.Ask) == 0) {
+		err = errors.New("empty_ticker")
+	}
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, 0, err
+	}
+	bestBid, err := strconv.ParseFloat(tickerInfo.Bid[0], 64)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, 0, err
+	}
+	bestAsk, err := strconv.ParseFloat(tickerInfo.Ask[0], 64)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return 0, 0, err
+	}
+	return bestBid, bestAsk, nil
+}
+
+func (api *Api) GetOrderBook(ctx context.Context, ticker string) (*exchange.OrderBook, error) {
+	location := "kraken.GetOrderBook"
+	depth, err := api.depth(ctx, ticker)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	bids, err := toOrderBookEntries(depth.Bids)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	asks, err := toOrderBookEntries(depth.Asks)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	return &exchange.OrderBook{Bids: bids, Asks: asks}, nil
+}
+
+// clientOrderID should be formed with exchange.FormClientOrderID, so that the order can be matched after an ambiguous
+// failure
+//
+// Kraken only returns the transaction id of a new order, which is then queried for its status
+func (api *Api) CreateOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*exchange.Order, error) {
+	location := "kraken.CreateOrder"
+	order, err := api.createOrder(ctx, ticker, clientOrderID, orderPrice, fiatAmount, quoteIncrement, tickSize, "")
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	return order, nil
+}
+
+// Same as CreateOrder, but the order is never live - it is filled immediately, and the unfilled remainder is cancelled
+func (api *Api) CreateImmediateOrCancelOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (*exchange.Order, error) {
+	location := "kraken.CreateImmediateOrCancelOrder"
+	order, err := api.createOrder(ctx, ticker, clientOrderID, orderPrice, fiatAmount, quoteIncrement, tickSize, TimeInForceImmediateOrCancel)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	return order, nil
+}
+
+func (api *Api) createOrder(ctx context.Context, ticker, clientOrderID string, orderPrice, fiatAmount float64, quoteIncrement, tickSize int, timeInForce string) (*exchange.Order, error) {
+	orderPriceStr, orderVolumeStr := formAddOrderReq(orderPrice, fiatAmount, quoteIncrement, tickSize)
+	txID, err := api.addOrder(ctx, ticker, formClientOrderID(clientOrderID), orderPriceStr, orderVolumeStr, timeInForce)
+	if err != nil {
+		return nil, err
+	}
+	orderInfo, err := api.queryOrder(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(txID, orderInfo), nil
+}
+
+// Finds the order submitted with clientOrderID, in case creating it had failed ambiguously
+//
+// Open orders are searched first, then closed orders, which cover orders that are no longer live
+func (api *Api) MatchActiveOrders(ctx context.Context, ticker, clientOrderID string) (*exchange.Order, error) {
+	location := "kraken.MatchActiveOrders"
+	krakenClientOrderID := formClientOrderID(clientOrderID)
+	orders, err := api.openOrders(ctx, krakenClientOrderID)
+	if err != nil {
+		logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
+		return nil, err
+	}
+	if order := matchClientOrderID(orders, ticker, krakenClientOrderID); order != nil {
+		return order, nil
+	}
+
+	// Client order ids are formed per day
+	orders, err = api.closedOrders(ctx, config.GetTime().GetTodayDate(), krakenClientOrderID)
+	if err != nil {
+		logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
+		return nil, err
+	}
+	if order := matchClientOrderID(orders, ticker, krakenClientOrderID); order != nil {
+		return order, nil
+	}
+
+	err = errors.New("order_not_found")
+	logger.Error(location, "ticker: %s, clientOrderID: %s", err, ticker, clientOrderID)
+	return nil, err
+}
+
+// Whether any buy order of the ticker has been (partially) filled since the given time
+func (api *Api) HasFilledBuyOrderSince(ctx context.Context, ticker string, since time.Time) (bool, error) {
+	location := "kraken.HasFilledBuyOrderSince"
+	orders, err := api.closedOrders(ctx, since, "")
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return false, err
+	}
+	pair := appendTickerWithQuoteCurrency(ticker)
+	for _, order := range orders {
+		if order != nil && order.Descr.Pair == pair && order.Descr.Type == "buy" && order.VolExec > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Trades of the given orders since the given time, i.e. the actual fills and fees of the orders
+func (api *Api) GetOrderTrades(ctx context.Context, ticker string, orderIDs []string, since time.Time) ([]*exchange.Trade, error) {
+	location := "kraken.GetOrderTrades"
+	trades, err := api.tradesHistory(ctx, since)
+	if err != nil {
+		logger.Error(location, "ticker: %s", err, ticker)
+		return nil, err
+	}
+	isOrderID := make(map[string]bool, len(orderIDs))
+	for _, orderID := range orderIDs {
+		isOrderID[orderID] = true
+	}
+	orderTrades := make([]*exchange.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade == nil || !isOrderID[trade.OrderTxID] {
+			continue
+		}
+		orderTrade, err := toTrade(ticker, trade)
+		if err != nil {
+			logger.Error(location, "ticker: %s", err, ticker)
+			return nil, err
+		}
+		orderTrades = append(orderTrades, orderTrade)
+	}
+	return orderTrades, nil
+}
+
+func (api *Api) GetOrderStatus(ctx context.Context, orderID string) (*exchange.Order, error) {
+	location := "kraken.GetOrderStatus"
+	orderInfo, err := api.queryOrder(ctx, orderID)
+	if err != nil {
+		logger.Error(location, "orderID: %s", err, orderID)
+		return nil, err
+	}
+	return toOrder(orderID, orderInfo), nil
+}
+
+// Kraken only returns the number of orders cancelled, the order is then queried for its partial fills
+func (api *Api) CancelOrder(ctx context.Context, orderID string) (*exchange.Order, error) {
+	location := "kraken.CancelOrder"
+	if _, err := api.cancelOrder(ctx, orderID); err != nil {
+		logger.Error(location, "orderID: %s", err, orderID)
+		return nil, err
+	}
+	return api.GetOrderStatus(ctx, orderID)
+}
+
+// Available balance, i.e. less the balance held by open orders, keyed by upper case currency, e.g. USD
+func (api *Api) GetAvailableBalances(ctx context.Context) (map[string]float64, error) {
+	location := "kraken.GetAvailableBalances"
+	balances, err := api.balanceEx(ctx)
+	if err != nil {
+		logger.Error(location, "Error getting balances", err)
+		return nil, err
+	}
+	availableBalances := make(map[string]float64, len(balances))
+	for asset, balance := range balances {
+		if balance != nil {
+			availableBalances[strings.ToUpper(normaliseAsset(asset))] += balance.Balance - balance.HoldTrade
+		}
+	}
+	return availableBalances, nil
+}
@@ -0,0 +1,164 @@
+package kraken
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApi_GetQuoteIncrementAndTickSize(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "USD"}}, nil)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	api := &Api{url: ""}
+
+	t.Run("ok", func(t *testing.T) {
+		defer httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, AssetPairsURI, httpmock.NewStringResponder(http.StatusOK, `{
+			"error": [],
+			"result": {"XXBTZUSD": {"altname": "XBTUSD", "pair_decimals": 1, "lot_decimals": 8, "ordermin": "0.00005"}}
+		}`))
+		quoteIncrement, tickSize, err := api.GetQuoteIncrementAndTickSize(util.TestContext(), "BTC")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, quoteIncrement)
+		assert.Equal(t, 8, tickSize)
+	})
+
+	t.Run("error_unknown_pair", func(t *testing.T) {
+		defer httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, AssetPairsURI, httpmock.NewStringResponder(http.StatusOK, `{
+			"error": ["EQuery:Unknown asset pair"]
+		}`))
+		_, _, err := api.GetQuoteIncrementAndTickSize(util.TestContext(), "BTC")
+		assert.Error(t, err)
+	})
+}
+
+func TestApi_GetTickerBestBidAskPrice(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "USD"}}, nil)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	api := &Api{url: ""}
+
+	httpmock.RegisterResponder(http.MethodGet, TickerURI, httpmock.NewStringResponder(http.StatusOK, `{
+		"error": [],
+		"result": {"XXBTZUSD": {"a": ["30300.10000", "1", "1.000"], "b": ["30300.00000", "1", "1.000"]}}
+	}`))
+	bestBid, bestAsk, err := api.GetTickerBestBidAskPrice(util.TestContext(), "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, 30300.0, bestBid)
+	assert.Equal(t, 30300.1, bestAsk)
+}
+
+func TestApi_CreateOrder(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "USD"}}, nil)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	api := &Api{url: "", key: "key", secret: "c2VjcmV0"}
+	clientOrderID := "20241103_btcusd_w0_a0"
+
+	t.Run("ok", func(t *testing.T) {
+		defer httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, AddOrderURI, func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				return nil, err
+			}
+			assert.Equal(t, "XBTUSD", req.PostForm.Get("pair"))
+			assert.Equal(t, "30000.0", req.PostForm.Get("price"))
+			assert.Equal(t, "0.00033333", req.PostForm.Get("volume"))
+			assert.Equal(t, formClientOrderID(clientOrderID), req.PostForm.Get("cl_ord_id"))
+			return httpmock.NewStringResponse(http.StatusOK, `{
+				"error": [],
+				"result": {"descr": {"order": "buy 0.00033333 XBTUSD @ limit 30000.0"}, "txid": ["OUF4EM-FRGI2-MQMWZD"]}
+			}`), nil
+		})
+		httpmock.RegisterResponder(http.MethodPost, QueryOrdersURI, httpmock.NewStringResponder(http.StatusOK, `{
+			"error": [],
+			"result": {"OUF4EM-FRGI2-MQMWZD": {
+				"cl_ord_id": "`+formClientOrderID(clientOrderID)+`",
+				"status": "open",
+				"opentm": 1730644200.5,
+				"descr": {"pair": "XBTUSD", "type": "buy", "ordertype": "limit", "price": "30000.0"},
+				"vol": "0.00033333",
+				"vol_exec": "0.00000000",
+				"price": "0.0"
+			}}
+		}`))
+		got, err := api.CreateOrder(util.TestContext(), "BTC", clientOrderID, 30000, 10, 1, 8)
+		assert.NoError(t, err)
+		assert.Equal(t, &exchange.Order{
+			OrderID:         "OUF4EM-FRGI2-MQMWZD",
+			ClientOrderID:   formClientOrderID(clientOrderID),
+			Symbol:          "XBTUSD",
+			Exchange:        exchangeName,
+			Price:           30000,
+			Side:            "buy",
+			Type:            "limit",
+			Timestampms:     1730644200500,
+			IsLive:          true,
+			RemainingAmount: 0.00033333,
+			OriginalAmount:  0.00033333,
+		}, got)
+	})
+
+	t.Run("error_insufficient_funds", func(t *testing.T) {
+		defer httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, AddOrderURI, httpmock.NewStringResponder(http.StatusOK, `{
+			"error": ["EOrder:Insufficient funds"]
+		}`))
+		_, err := api.CreateOrder(util.TestContext(), "BTC", clientOrderID, 30000, 10, 1, 8)
+		assert.Error(t, err)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+}
+
+func TestApi_CancelOrder(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "USD"}}, nil)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	api := &Api{url: "", key: "key", secret: "c2VjcmV0"}
+
+	httpmock.RegisterResponder(http.MethodPost, CancelOrderURI, httpmock.NewStringResponder(http.StatusOK, `{
+		"error": [],
+		"result": {"count": 1}
+	}`))
+	httpmock.RegisterResponder(http.MethodPost, QueryOrdersURI, httpmock.NewStringResponder(http.StatusOK, `{
+		"error": [],
+		"result": {"OUF4EM-FRGI2-MQMWZD": {
+			"status": "canceled",
+			"descr": {"pair": "XBTUSD", "type": "buy", "ordertype": "limit", "price": "30000.0"},
+			"vol": "0.00033333",
+			"vol_exec": "0.00010000",
+			"price": "30000.0"
+		}}
+	}`))
+	got, err := api.CancelOrder(util.TestContext(), "OUF4EM-FRGI2-MQMWZD")
+	assert.NoError(t, err)
+	assert.True(t, got.IsCancelled)
+	assert.False(t, got.IsLive)
+	assert.Equal(t, 0.0001, got.ExecutedAmount)
+	assert.InDelta(t, 0.00023333, got.RemainingAmount, 1e-12)
+}
+
+func TestApi_GetAvailableBalances(t *testing.T) {
+	config.TestInit(&config.ConfigUpdateable{QuoteCurrencies: map[string]string{"BTC": "USD"}}, nil)
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	api := &Api{url: "", key: "key", secret: "c2VjcmV0"}
+
+	httpmock.RegisterResponder(http.MethodPost, BalanceExURI, httpmock.NewStringResponder(http.StatusOK, `{
+		"error": [],
+		"result": {
+			"ZUSD": {"balance": "100.50", "hold_trade": "20.50"},
+			"XXBT": {"balance": "0.1", "hold_trade": "0"}
+		}
+	}`))
+	got, err := api.GetAvailableBalances(util.TestContext())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"USD": 80, "BTC": 0.1}, got)
+}
@@ -0,0 +1,172 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+type Api struct {
+	url    string
+	key    string
+	secret string // base64 encoded
+
+	client      *http.Client // http.DefaultClient if nil
+	timeout     time.Duration
+	userAgent   string
+	middlewares []exchange.Middleware
+
+	nonces     exchange.NonceProvider
+	nonceOrder exchange.NonceOrder
+}
+
+// Requests are sent to the live API unless configured otherwise with opts
+func New(key, secret string, opts ...Option) *Api {
+	api := &Api{
+		url:       baseURL,
+		key:       key,
+		secret:    secret,
+		userAgent: defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	if len(api.middlewares) > 0 {
+		api.client = exchange.WrapClient(api.client, api.middlewares)
+	}
+	return api
+}
+
+// Nonces of an Api without a nonce provider, e.g. in unit tests, are the current time in nanoseconds
+func (api *Api) nextNonce() (int64, error) {
+	if api.nonces == nil {
+		return time.Now().UnixNano(), nil
+	}
+	return api.nonces.Next()
+}
+
+// sign handles the signature of private requests according to Kraken specification, i.e. the HMAC-SHA512 of the path
+// and the SHA256 of the nonce & post data, keyed by the base64 decoded secret
+func (api *Api) sign(path, nonce, postData string) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(api.secret)
+	if err != nil {
+		return "", err
+	}
+
+	sha := sha256.Sum256([]byte(nonce + postData))
+	mac := hmac.New(sha512.New, secret)
+	if _, err := mac.Write(append([]byte(path), sha[:]...)); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// request makes the HTTP request to Kraken and handles any returned errors, returning the result of the response
+//
+// The nonce of private requests, i.e. POST, is set here. A private request rejected for its nonce, e.g. as it was
+// overtaken by a request with a later nonce on another connection, is sent once more with a fresh nonce, as it had no
+// effect
+func (api *Api) request(ctx context.Context, verb, path string, params url.Values) (json.RawMessage, error) {
+	location := "kraken.request"
+	if api.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, api.timeout)
+		defer cancel()
+	}
+
+	result, err := api.send(ctx, verb, path, params)
+	if verb == http.MethodPost && errors.Is(err, ErrInvalidNonce) {
+		logger.Warn(location, "Nonce of %s is rejected, resending with a fresh nonce", path)
+		result, err = api.send(ctx, verb, path, params)
+	}
+	return result, err
+}
+
+func (api *Api) send(ctx context.Context, verb, path string, params url.Values) (json.RawMessage, error) {
+	location := "kraken.send"
+	reqURL := api.url + path
+
+	var postData string
+	// The next nonce is only taken once this request is written
+	if verb == http.MethodPost {
+		nonce, nonceCtx, release, err := api.nonceOrder.Take(ctx, api.nextNonce)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		ctx = nonceCtx
+		if params == nil {
+			params = url.Values{}
+		}
+		params.Set("nonce", fmt.Sprint(nonce))
+		postData = params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, verb, reqURL, strings.NewReader(postData))
+	if err != nil {
+		return nil, err
+	}
+
+	if verb == http.MethodGet {
+		req.URL.RawQuery = params.Encode()
+	} else {
+		signature, err := api.sign(path, params.Get("nonce"), postData)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("API-Key", api.key)
+		req.Header.Set("API-Sign", signature)
+	}
+
+	if api.userAgent != "" {
+		req.Header.Set("User-Agent", api.userAgent)
+	}
+
+	logger.Info(location, "request verb:%s, url:%s, params:%+v", verb, reqURL, params)
+
+	client := api.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "response.body: %v", string(body))
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP Status Code: %d", resp.StatusCode)
+	}
+
+	response := &Response{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, err
+	}
+	if len(response.Error) > 0 {
+		return nil, newError(response.Error)
+	}
+
+	return response.Result, nil
+}
@@ -0,0 +1,234 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Example of the Kraken API documentation
+func TestApi_sign(t *testing.T) {
+	api := &Api{
+		secret: "kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg==",
+	}
+	got, err := api.sign(AddOrderURI, "1616492376594", "nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25")
+	assert.NoError(t, err)
+	assert.Equal(t, "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ==", got)
+}
+
+func TestApi_request(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	tests := []struct {
+		name    string
+		setup   func() func()
+		verb    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "ok_public",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{"error":[],"result":{"unixtime":1}}`)
+				httpmock.RegisterResponder(http.MethodGet, TickerURI, responder)
+				return httpmock.Reset
+			},
+			verb: http.MethodGet,
+			want: `{"unixtime":1}`,
+		},
+		{
+			name: "ok_private_signed",
+			setup: func() func() {
+				responder := func(req *http.Request) (*http.Response, error) {
+					if req.Header.Get("API-Key") != "key" || req.Header.Get("API-Sign") == "" {
+						return httpmock.NewStringResponse(http.StatusOK, `{"error":["EAPI:Invalid key"]}`), nil
+					}
+					return httpmock.NewStringResponse(http.StatusOK, `{"error":[],"result":{"count":1}}`), nil
+				}
+				httpmock.RegisterResponder(http.MethodPost, TickerURI, responder)
+				return httpmock.Reset
+			},
+			verb: http.MethodPost,
+			want: `{"count":1}`,
+		},
+		{
+			name: "error_array",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusOK, `{"error":["EGeneral:Invalid arguments"]}`)
+				httpmock.RegisterResponder(http.MethodGet, TickerURI, responder)
+				return httpmock.Reset
+			},
+			verb:    http.MethodGet,
+			wantErr: true,
+		},
+		{
+			name: "error_status_code",
+			setup: func() func() {
+				responder := httpmock.NewStringResponder(http.StatusBadGateway, ``)
+				httpmock.RegisterResponder(http.MethodGet, TickerURI, responder)
+				return httpmock.Reset
+			},
+			verb:    http.MethodGet,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &Api{
+				url:    "",
+				key:    "key",
+				secret: "c2VjcmV0",
+			}
+			teardown := tt.setup()
+			defer teardown()
+			got, err := api.request(util.TestContext(), tt.verb, TickerURI, url.Values{"nonce": {"1"}})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_newError(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []string
+		wantClass error
+		rejected  bool
+		retryable bool
+	}{
+		{
+			name:      "insufficient_funds",
+			messages:  []string{"EOrder:Insufficient funds"},
+			wantClass: exchange.ErrInsufficientFunds,
+			rejected:  true,
+		},
+		{
+			name:      "auth",
+			messages:  []string{"EAPI:Invalid key"},
+			wantClass: exchange.ErrAuth,
+			rejected:  true,
+		},
+		{
+			name:      "bad_request_with_details",
+			messages:  []string{"EGeneral:Invalid arguments:volume"},
+			wantClass: ErrBadRequest,
+			rejected:  true,
+		},
+		{
+			name:      "invalid_nonce",
+			messages:  []string{"EAPI:Invalid nonce"},
+			wantClass: ErrInvalidNonce,
+			rejected:  true,
+			retryable: true,
+		},
+		{
+			name:      "not_found",
+			messages:  []string{"EOrder:Unknown order"},
+			wantClass: ErrNotFound,
+			rejected:  true,
+		},
+		{
+			name:      "server",
+			messages:  []string{"EService:Unavailable"},
+			wantClass: ErrServer,
+			retryable: true,
+		},
+		{
+			name:      "first_known",
+			messages:  []string{"WGeneral:Deprecated", "EOrder:Rate limit exceeded"},
+			wantClass: ErrRateLimited,
+			rejected:  true,
+			retryable: true,
+		},
+		{
+			name:      "unknown",
+			messages:  []string{"EGeneral:Something new"},
+			wantClass: ErrUnknown,
+			retryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newError(tt.messages)
+			assert.ErrorIs(t, err, tt.wantClass)
+			assert.Equal(t, tt.rejected, exchange.IsRejected(err))
+			assert.Equal(t, tt.retryable, err.Retryable())
+			assert.Equal(t, strings.Join(tt.messages, ", "), err.Error())
+		})
+	}
+}
+
+func TestApi_request_options(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	t.Run("nonce_provider_and_user_agent", func(t *testing.T) {
+		defer httpmock.Reset()
+		path := filepath.Join(t.TempDir(), "nonce.json")
+		future := time.Now().Add(time.Hour).UnixNano()
+		assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`{"nonce":%d}`, future)), 0o644))
+		nonces, err := exchange.NewIncreasingNonceProvider(path)
+		assert.NoError(t, err)
+		var nonce, userAgent string
+		httpmock.RegisterResponder(http.MethodPost, BalanceExURI, func(req *http.Request) (*http.Response, error) {
+			_ = req.ParseForm()
+			nonce, userAgent = req.PostForm.Get("nonce"), req.Header.Get("User-Agent")
+			return httpmock.NewStringResponse(http.StatusOK, `{"error":[],"result":{}}`), nil
+		})
+
+		api := New("key", "c2VjcmV0", WithBaseURL(""), WithUserAgent("test"), WithNonceProvider(nonces))
+		_, err = api.request(util.TestContext(), http.MethodPost, BalanceExURI, url.Values{})
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(future+1), nonce)
+		assert.Equal(t, "test", userAgent)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		defer httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, TickerURI, func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done() // hung until abandoned
+			return nil, req.Context().Err()
+		})
+
+		api := New("key", "c2VjcmV0", WithBaseURL(""), WithTimeout(10*time.Millisecond))
+		_, err := api.request(util.TestContext(), http.MethodGet, TickerURI, url.Values{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestApi_request_invalidNonce(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var nonces []string
+	httpmock.RegisterResponder(http.MethodPost, BalanceExURI, func(req *http.Request) (*http.Response, error) {
+		_ = req.ParseForm()
+		nonces = append(nonces, req.PostForm.Get("nonce"))
+		if len(nonces) == 1 {
+			return httpmock.NewStringResponse(http.StatusOK, `{"error":["EAPI:Invalid nonce"]}`), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, `{"error":[],"result":{}}`), nil
+	})
+
+	api := New("key", "c2VjcmV0", WithBaseURL(""))
+	_, err := api.request(util.TestContext(), http.MethodPost, BalanceExURI, url.Values{})
+	assert.NoError(t, err)
+	if assert.Len(t, nonces, 2) {
+		assert.NotEqual(t, nonces[0], nonces[1], "should be resent with a fresh nonce")
+	}
+}
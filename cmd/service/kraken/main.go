@@ -0,0 +1,24 @@
+package kraken
+
+import (
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+var api *Api
+
+func MustInitClient() {
+	location := "kraken.MustInitClient"
+	c := config.Get().KrakenApi
+	nonces, err := exchange.NewIncreasingNonceProvider(c.NoncePath)
+	if err != nil {
+		logger.Panic(location, "Failed to initialise nonces", err)
+	}
+	api = New(c.ApiKey, c.ApiSecret, WithNonceProvider(nonces), WithTimeout(c.RequestTimeout))
+	exchange.Set(config.ExchangeKraken, api)
+}
+
+func GetClient() *Api {
+	return api
+}
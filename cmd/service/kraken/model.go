@@ -0,0 +1,97 @@
+package kraken
+
+import "encoding/json"
+
+// Every response is wrapped in this envelope, errors are reported in Error even with HTTP status code 200
+type Response struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+type AssetPair struct {
+	Altname      string  `json:"altname"`
+	Base         string  `json:"base"`
+	Quote        string  `json:"quote"`
+	PairDecimals int     `json:"pair_decimals"`
+	LotDecimals  int     `json:"lot_decimals"`
+	OrderMin     float64 `json:"ordermin,string"`
+	CostMin      float64 `json:"costmin,string"`
+	TickSize     float64 `json:"tick_size,string"`
+	Status       string  `json:"status"`
+}
+
+// Ask & Bid are [price, whole lot volume, lot volume]
+type TickerInfo struct {
+	Ask []string `json:"a"`
+	Bid []string `json:"b"`
+}
+
+// Every level is [price, volume, timestamp]
+type Depth struct {
+	Asks [][]any `json:"asks"`
+	Bids [][]any `json:"bids"`
+}
+
+type AddOrderResult struct {
+	Descr struct {
+		Order string `json:"order"`
+	} `json:"descr"`
+	TxID []string `json:"txid"`
+}
+
+type OrderInfo struct {
+	ClientOrderID string           `json:"cl_ord_id"`
+	Status        string           `json:"status"`
+	OpenTm        float64          `json:"opentm"`
+	Descr         OrderDescription `json:"descr"`
+	Vol           float64          `json:"vol,string"`
+	VolExec       float64          `json:"vol_exec,string"`
+	Cost          float64          `json:"cost,string"`
+	Fee           float64          `json:"fee,string"`
+	Price         float64          `json:"price,string"` // average execution price
+	Reason        string           `json:"reason"`
+}
+
+type OrderDescription struct {
+	Pair      string  `json:"pair"`
+	Type      string  `json:"type"`
+	OrderType string  `json:"ordertype"`
+	Price     float64 `json:"price,string"`
+}
+
+type OpenOrdersResult struct {
+	Open map[string]*OrderInfo `json:"open"`
+}
+
+type ClosedOrdersResult struct {
+	Closed map[string]*OrderInfo `json:"closed"`
+	Count  int                   `json:"count"`
+}
+
+type CancelOrderResult struct {
+	Count int `json:"count"`
+}
+
+type TradeInfo struct {
+	TradeID   int64   `json:"trade_id"`
+	OrderTxID string  `json:"ordertxid"`
+	Pair      string  `json:"pair"`
+	Time      float64 `json:"time"`
+	Type      string  `json:"type"`
+	OrderType string  `json:"ordertype"`
+	Price     float64 `json:"price,string"`
+	Cost      float64 `json:"cost,string"`
+	Fee       float64 `json:"fee,string"`
+	Vol       float64 `json:"vol,string"`
+	IsMaker   bool    `json:"maker"`
+}
+
+type TradesHistoryResult struct {
+	Trades map[string]*TradeInfo `json:"trades"`
+	Count  int                   `json:"count"`
+}
+
+type BalanceEx struct {
+	Balance   float64 `json:"balance,string"`
+	HoldTrade float64 `json:"hold_trade,string"`
+}
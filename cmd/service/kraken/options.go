@@ -0,0 +1,52 @@
+package kraken
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+)
+
+// Option configures the Api on New
+type Option func(*Api)
+
+// Requests are sent to the given URL instead of the live API, e.g. of a local fake exchange
+func WithBaseURL(url string) Option {
+	return func(api *Api) {
+		api.url = url
+	}
+}
+
+// Requests are sent with the given client instead of http.DefaultClient, the client's own timeout still applies
+func WithHTTPClient(client *http.Client) Option {
+	return func(api *Api) {
+		api.client = client
+	}
+}
+
+// Every request fails once it takes longer than the timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(api *Api) {
+		api.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(api *Api) {
+		api.userAgent = userAgent
+	}
+}
+
+// Middlewares wrap the transport in the given order, i.e. the first middleware sees the request first
+func WithMiddleware(middlewares ...exchange.Middleware) Option {
+	return func(api *Api) {
+		api.middlewares = append(api.middlewares, middlewares...)
+	}
+}
+
+// Nonces of private requests are the current time in nanoseconds unless given
+func WithNonceProvider(nonces exchange.NonceProvider) Option {
+	return func(api *Api) {
+		api.nonces = nonces
+	}
+}
@@ -0,0 +1,203 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Add Order - returns the transaction id of the order
+func (api *Api) addOrder(ctx context.Context, ticker, clientOrderID, price, volume, timeInForce string) (string, error) {
+	location := "kraken.addOrder"
+	params := url.Values{
+		"cl_ord_id": {clientOrderID},
+		"pair":      {appendTickerWithQuoteCurrency(ticker)},
+		"price":     {price},
+		"volume":    {volume},
+		"type":      {"buy"},
+		"ordertype": {"limit"},
+	}
+	if timeInForce != "" {
+		params.Set("timeinforce", timeInForce)
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	addOrderResult := &AddOrderResult{}
+
+	result, err := api.request(ctx, http.MethodPost, AddOrderURI, params)
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(result, addOrderResult); err != nil {
+		return "", err
+	}
+	if len(addOrderResult.TxID) != 1 {
+		return "", fmt.Errorf("unexpected_txid: %v", addOrderResult.TxID)
+	}
+
+	logger.Info(location, "addOrderResult: %v", util.SafeJsonDump(addOrderResult))
+
+	return addOrderResult.TxID[0], nil
+}
+
+// Open Orders - of the client order id
+func (api *Api) openOrders(ctx context.Context, clientOrderID string) (map[string]*OrderInfo, error) {
+	location := "kraken.openOrders"
+	params := url.Values{
+		"cl_ord_id": {clientOrderID},
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	openOrdersResult := &OpenOrdersResult{}
+
+	result, err := api.request(ctx, http.MethodPost, OpenOrdersURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, openOrdersResult); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "orders: %v", util.SafeJsonDump(openOrdersResult.Open))
+
+	return openOrdersResult.Open, nil
+}
+
+// Closed Orders - of every pair since a timestamp, restricted to the client order id if given
+func (api *Api) closedOrders(ctx context.Context, since time.Time, clientOrderID string) (map[string]*OrderInfo, error) {
+	location := "kraken.closedOrders"
+	params := url.Values{
+		"start": {fmt.Sprint(since.Unix())},
+	}
+	if clientOrderID != "" {
+		params.Set("cl_ord_id", clientOrderID)
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	closedOrdersResult := &ClosedOrdersResult{}
+
+	result, err := api.request(ctx, http.MethodPost, ClosedOrdersURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, closedOrdersResult); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "orders: %v", util.SafeJsonDump(closedOrdersResult.Closed))
+
+	return closedOrdersResult.Closed, nil
+}
+
+// Query Orders - of a single transaction id
+func (api *Api) queryOrder(ctx context.Context, txID string) (*OrderInfo, error) {
+	location := "kraken.queryOrder"
+	params := url.Values{
+		"txid": {txID},
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	var orders map[string]*OrderInfo
+
+	result, err := api.request(ctx, http.MethodPost, QueryOrdersURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &orders); err != nil {
+		return nil, err
+	}
+	order, ok := orders[txID]
+	if !ok || order == nil {
+		return nil, errors.New("order_not_found")
+	}
+
+	logger.Info(location, "order: %v", util.SafeJsonDump(order))
+
+	return order, nil
+}
+
+// Cancel Order - returns the number of orders cancelled
+func (api *Api) cancelOrder(ctx context.Context, txID string) (int, error) {
+	location := "kraken.cancelOrder"
+	params := url.Values{
+		"txid": {txID},
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	cancelOrderResult := &CancelOrderResult{}
+
+	result, err := api.request(ctx, http.MethodPost, CancelOrderURI, params)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := json.Unmarshal(result, cancelOrderResult); err != nil {
+		return 0, err
+	}
+
+	logger.Info(location, "cancelOrderResult: %v", util.SafeJsonDump(cancelOrderResult))
+
+	return cancelOrderResult.Count, nil
+}
+
+// Trades History - of every pair since a timestamp, the most recent 50 trades only
+func (api *Api) tradesHistory(ctx context.Context, since time.Time) (map[string]*TradeInfo, error) {
+	location := "kraken.tradesHistory"
+	params := url.Values{
+		"start": {fmt.Sprint(since.Unix())},
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	tradesHistoryResult := &TradesHistoryResult{}
+
+	result, err := api.request(ctx, http.MethodPost, TradesHistoryURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, tradesHistoryResult); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "trades: %v", util.SafeJsonDump(tradesHistoryResult.Trades))
+
+	return tradesHistoryResult.Trades, nil
+}
+
+// Extended Balances - keyed by Kraken's own asset names, e.g. ZUSD
+func (api *Api) balanceEx(ctx context.Context) (map[string]*BalanceEx, error) {
+	location := "kraken.balanceEx"
+	params := url.Values{}
+
+	var balances map[string]*BalanceEx
+
+	result, err := api.request(ctx, http.MethodPost, BalanceExURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &balances); err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "balances: %v", util.SafeJsonDump(balances))
+
+	return balances, nil
+}
@@ -0,0 +1,97 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Asset Pairs - results are keyed by Kraken's own pair name, e.g. XXBTZUSD, so only the single result is returned
+func (api *Api) assetPair(ctx context.Context, ticker string) (*AssetPair, error) {
+	location := "kraken.assetPair"
+	params := url.Values{"pair": {appendTickerWithQuoteCurrency(ticker)}}
+
+	logger.Info(location, "params:%+v", params)
+
+	var assetPairs map[string]*AssetPair
+
+	result, err := api.request(ctx, http.MethodGet, AssetPairsURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &assetPairs); err != nil {
+		return nil, err
+	}
+
+	assetPair, err := singleResult(assetPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "assetPair: %+v", assetPair)
+
+	return assetPair, nil
+}
+
+// Ticker
+func (api *Api) ticker(ctx context.Context, ticker string) (*TickerInfo, error) {
+	location := "kraken.ticker"
+	params := url.Values{"pair": {appendTickerWithQuoteCurrency(ticker)}}
+
+	logger.Info(location, "params:%+v", params)
+
+	var tickerInfos map[string]*TickerInfo
+
+	result, err := api.request(ctx, http.MethodGet, TickerURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &tickerInfos); err != nil {
+		return nil, err
+	}
+
+	tickerInfo, err := singleResult(tickerInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "tickerInfo: %+v", tickerInfo)
+
+	return tickerInfo, nil
+}
+
+// Order Book
+func (api *Api) depth(ctx context.Context, ticker string) (*Depth, error) {
+	location := "kraken.depth"
+	params := url.Values{
+		"pair":  {appendTickerWithQuoteCurrency(ticker)},
+		"count": {DepthCount},
+	}
+
+	logger.Info(location, "params:%+v", params)
+
+	var depths map[string]*Depth
+
+	result, err := api.request(ctx, http.MethodGet, DepthURI, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(result, &depths); err != nil {
+		return nil, err
+	}
+
+	depth, err := singleResult(depths)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(location, "depth: %+v", depth)
+
+	return depth, nil
+}
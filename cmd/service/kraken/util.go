@@ -0,0 +1,142 @@
+package kraken
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/shopspring/decimal"
+)
+
+// return orderPriceStr, orderVolumeStr
+func formAddOrderReq(orderPrice, fiatAmount float64, quoteIncrement, tickSize int) (string, string) {
+	orderVolume := decimal.NewFromFloat(fiatAmount).Div(decimal.NewFromFloat(orderPrice))
+	orderPriceStr := util.ConvertFloatToPrecString(orderPrice, quoteIncrement)
+	orderVolumeStr := util.ConvertFloatToPrecString(orderVolume, tickSize)
+
+	return orderPriceStr, orderVolumeStr
+}
+
+// Kraken takes a client order id as a UUID, which is derived from exchange.FormClientOrderID so that the order can
+// still be matched by it
+func formClientOrderID(clientOrderID string) string {
+	b := sha256.Sum256([]byte(clientOrderID))
+	b[6] = (b[6] & 0x0f) | 0x80 // version 8, i.e. custom
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func matchClientOrderID(orders map[string]*OrderInfo, ticker, clientOrderID string) *exchange.Order {
+	for txID, order := range orders {
+		if order != nil && order.ClientOrderID == clientOrderID && order.Descr.Pair == appendTickerWithQuoteCurrency(ticker) && order.Descr.Type == "buy" {
+			return toOrder(txID, order)
+		}
+	}
+	return nil
+}
+
+func toOrder(txID string, orderInfo *OrderInfo) *exchange.Order {
+	return &exchange.Order{
+		OrderID:           txID,
+		ClientOrderID:     orderInfo.ClientOrderID,
+		Symbol:            orderInfo.Descr.Pair,
+		Exchange:          exchangeName,
+		Price:             orderInfo.Descr.Price,
+		AvgExecutionPrice: orderInfo.Price,
+		Side:              orderInfo.Descr.Type,
+		Type:              orderInfo.Descr.OrderType,
+		Timestampms:       int64(orderInfo.OpenTm * 1000),
+		IsLive:            orderInfo.Status == OrderStatusPending || orderInfo.Status == OrderStatusOpen,
+		IsCancelled:       orderInfo.Status == OrderStatusCanceled || orderInfo.Status == OrderStatusExpired,
+		Reason:            orderInfo.Reason,
+		ExecutedAmount:    orderInfo.VolExec,
+		RemainingAmount:   orderInfo.Vol - orderInfo.VolExec,
+		OriginalAmount:    orderInfo.Vol,
+	}
+}
+
+// Kraken does not report fees in another currency, they are charged in the quote currency
+func toTrade(ticker string, tradeInfo *TradeInfo) (*exchange.Trade, error) {
+	if tradeInfo.Type == "" {
+		return nil, fmt.Errorf("empty_trade_type: %d", tradeInfo.TradeID)
+	}
+	return &exchange.Trade{
+		Timestamp:   int64(tradeInfo.Time),
+		Timestampms: int64(tradeInfo.Time * 1000),
+		TradeID:     tradeInfo.TradeID,
+		OrderID:     tradeInfo.OrderTxID,
+		Price:       tradeInfo.Price,
+		Amount:      tradeInfo.Vol,
+		Exchange:    exchangeName,
+		Type:        strings.ToUpper(tradeInfo.Type[:1]) + tradeInfo.Type[1:],
+		IsAggressor: !tradeInfo.IsMaker,
+		FeeCurrency: config.Get().GetQuoteCurrency(ticker),
+		FeeAmount:   tradeInfo.Fee,
+	}, nil
+}
+
+func toOrderBookEntries(levels [][]any) ([]exchange.OrderBookEntry, error) {
+	entries := make([]exchange.OrderBookEntry, 0, len(levels))
+	for _, level := range levels {
+		if len(level) < 2 {
+			return nil, fmt.Errorf("invalid_order_book_level: %v", level)
+		}
+		priceStr, ok := level[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid_order_book_level: %v", level)
+		}
+		amountStr, ok := level[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid_order_book_level: %v", level)
+		}
+		price, err := strconv.ParseFloat(priceStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, exchange.OrderBookEntry{Price: price, Amount: amount})
+	}
+	return entries, nil
+}
+
+// Public results are keyed by Kraken's own pair name, which is not known in advance
+func singleResult[T any](m map[string]*T) (*T, error) {
+	if len(m) != 1 {
+		return nil, fmt.Errorf("unexpected_result_count: %d", len(m))
+	}
+	for _, v := range m {
+		if v == nil {
+			return nil, errors.New("empty_result")
+		}
+		return v, nil
+	}
+	return nil, nil
+}
+
+// Kraken prefixes legacy asset names with X for crypto and Z for fiat, e.g. XXBT & ZUSD, and calls BTC XBT
+func normaliseAsset(asset string) string {
+	if len(asset) == 4 && (strings.HasPrefix(asset, "X") || strings.HasPrefix(asset, "Z")) {
+		asset = asset[1:]
+	}
+	if asset == "XBT" {
+		return "BTC"
+	}
+	return asset
+}
+
+// Pair as accepted by Kraken, e.g. XBTUSD
+func appendTickerWithQuoteCurrency(ticker string) string {
+	base := ticker
+	if base == "BTC" {
+		base = "XBT"
+	}
+	return base + config.Get().GetQuoteCurrency(ticker)
+}
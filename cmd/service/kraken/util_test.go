@@ -0,0 +1,46 @@
+package kraken
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formClientOrderID(t *testing.T) {
+	got := formClientOrderID("20241103_btcusd_w0_a0")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-8[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), got)
+	assert.Equal(t, got, formClientOrderID("20241103_btcusd_w0_a0"), "should be deterministic")
+	assert.NotEqual(t, got, formClientOrderID("20241103_btcusd_w0_a1"))
+}
+
+func Test_normaliseAsset(t *testing.T) {
+	tests := map[string]string{
+		"XXBT":  "BTC",
+		"ZUSD":  "USD",
+		"XETH":  "ETH",
+		"SOL":   "SOL",
+		"USDC":  "USDC",
+		"XBT.F": "XBT.F",
+	}
+	for asset, want := range tests {
+		assert.Equal(t, want, normaliseAsset(asset), asset)
+	}
+}
+
+func Test_toTrade(t *testing.T) {
+	config.TestInit(nil, nil)
+	t.Run("ok", func(t *testing.T) {
+		got, err := toTrade("BTC", &TradeInfo{TradeID: 1, OrderTxID: "OQCLML-BW3P3-BUCMWZ", Time: 1, Type: "buy", Price: 1000, Vol: 0.001, Fee: 0.0025, IsMaker: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "Buy", got.Type)
+		assert.Equal(t, "SGD", got.FeeCurrency)
+		assert.False(t, got.IsAggressor)
+	})
+	t.Run("error_empty_type", func(t *testing.T) {
+		got, err := toTrade("BTC", &TradeInfo{TradeID: 1})
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/exchange"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/sentry"
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Tickers to skip today as their order would exceed a spend cap, given the fiat deposits recorded this calendar month.
+// Tickers are kept by priority for the global caps, see sortTickersByPriority
+//
+// Monthly caps are in the quote currency of the ticker, while global caps are in the reporting currency, as they sum
+// fiat deposits across quote currencies
+//
+// Every ticker to order is skipped should the recorded orders or the FX rates be unavailable, as the caps cannot be
+// verified
+func getSpendCappedTickers(ctx context.Context, doneTickers map[string]bool, dailyFiatAmounts map[string]float64) map[string]bool {
+	location := "cmd.getSpendCappedTickers"
+	c := config.Get()
+	spendCaps := c.OrderMetadata.SpendCaps
+	cappedTickers := make(map[string]bool)
+	if len(spendCaps.MonthlyFiatCaps) == 0 && spendCaps.GlobalDailyFiatCap <= 0 && spendCaps.GlobalMonthlyFiatCap <= 0 {
+		return cappedTickers
+	}
+
+	tickers := make([]string, 0, len(c.CryptoTickers))
+	for ticker := range c.CryptoTickers {
+		if dailyFiatAmounts[ticker] > 0 && !doneTickers[ticker] && c.IsDueToday(ticker) {
+			tickers = append(tickers, ticker)
+		}
+	}
+	if len(tickers) == 0 {
+		return cappedTickers
+	}
+	sortTickersByPriority(tickers)
+
+	capEveryTicker := func(msg string, err error) map[string]bool {
+		logger.Error(location, "%s, skipping every ticker", err, msg)
+		sentry.CaptureErr(fmt.Errorf("unable to verify spend caps: %w", err))
+		for _, ticker := range tickers {
+			cappedTickers[ticker] = true
+		}
+		return cappedTickers
+	}
+	isGloballyCapped := spendCaps.GlobalDailyFiatCap > 0 || spendCaps.GlobalMonthlyFiatCap > 0
+
+	today := config.GetTime().GetTodayDate()
+	rows, err := db.Get().GetOrdersCreatedSince(config.GetTime().GetMonthStartDate())
+	if err != nil {
+		return capEveryTicker("Error getting orders created this month", err)
+	}
+
+	// Fiat deposits of crypto tickers only, which may be of different quote currencies
+	tickersByRow := make(map[string]string, len(c.CryptoTickers))
+	for ticker := range c.CryptoTickers {
+		tickersByRow[exchange.Pair(ticker)] = ticker
+	}
+	monthlySpent := make(map[string]float64)
+	globalDailySpent, globalMonthlySpent := float64(0), float64(0)
+	for _, row := range rows {
+		ticker, ok := tickersByRow[row.Ticker]
+		if !ok {
+			continue
+		}
+		monthlySpent[ticker] += row.FiatDeposit
+		if !isGloballyCapped {
+			continue
+		}
+		spent, err := getFiatDepositInReportingCurrency(ctx, ticker, row)
+		if err != nil {
+			return capEveryTicker("Error converting recorded fiat deposits into reporting currency", err)
+		}
+		globalMonthlySpent += spent
+		if row.CreatedForDay.Equal(today) {
+			globalDailySpent += spent
+		}
+	}
+
+	for _, ticker := range tickers {
+		// Trading fees are charged on top of the fiat amount
+		spend := dailyFiatAmounts[ticker] * (1 + exchange.Get(ticker).GetMakerTradingFee())
+		globalSpend := spend
+		if isGloballyCapped {
+			if globalSpend, _, err = toReportingCurrency(ctx, ticker, spend); err != nil {
+				return capEveryTicker("Error converting fiat amount into reporting currency", err)
+			}
+		}
+
+		var errStr string
+		if monthlyFiatCap, ok := spendCaps.MonthlyFiatCaps[ticker]; ok && roundFiatAmount(monthlySpent[ticker]+spend) > monthlyFiatCap {
+			errStr = fmt.Sprintf("'%s' monthly fiat cap of %v exceeded, spent: %v, to spend: %v", ticker, monthlyFiatCap, monthlySpent[ticker], spend)
+		} else if spendCaps.GlobalDailyFiatCap > 0 && roundFiatAmount(globalDailySpent+globalSpend) > spendCaps.GlobalDailyFiatCap {
+			errStr = fmt.Sprintf("'%s' global daily fiat cap of %v exceeded, spent: %v, to spend: %v", ticker, spendCaps.GlobalDailyFiatCap, globalDailySpent, globalSpend)
+		} else if spendCaps.GlobalMonthlyFiatCap > 0 && roundFiatAmount(globalMonthlySpent+globalSpend) > spendCaps.GlobalMonthlyFiatCap {
+			errStr = fmt.Sprintf("'%s' global monthly fiat cap of %v exceeded, spent: %v, to spend: %v", ticker, spendCaps.GlobalMonthlyFiatCap, globalMonthlySpent, globalSpend)
+		}
+		if errStr != "" {
+			err := errors.New(errStr)
+			logger.Warn(location, "Skipping ticker: %v", err)
+			sentry.CaptureErr(err)
+			cappedTickers[ticker] = true
+			continue
+		}
+
+		globalDailySpent += globalSpend
+		globalMonthlySpent += globalSpend
+	}
+
+	return cappedTickers
+}
+
+// Recorded fiat deposit in the current reporting currency, converted at the current rate if recorded in another one
+func getFiatDepositInReportingCurrency(ctx context.Context, ticker string, row *db.Order) (float64, error) {
+	c := config.Get()
+	reportingCurrency := c.GetReportingCurrency(ticker)
+	if row.ReportingCurrency == reportingCurrency {
+		return row.FiatDepositInReportingCurrency, nil
+	}
+	// Rows recorded before quote currencies were, are of the quote currency of the ticker
+	quoteCurrency := row.QuoteCurrency
+	if quoteCurrency == "" {
+		quoteCurrency = c.GetQuoteCurrency(ticker)
+	}
+	converted, _, err := fx.Convert(ctx, row.FiatDeposit, quoteCurrency, reportingCurrency)
+	return converted, err
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeraldyik/crypto_dca_go/cmd/config"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/util"
+	"github.com/jeraldyik/crypto_dca_go/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getSpendCappedTickers(t *testing.T) {
+	monthStart := config.TestNowDate.AddDate(0, 0, -2)
+	yesterday := config.TestNowDate.AddDate(0, 0, -1)
+
+	// BTC & ETH spend 1.002 & 2.004 SGD today, including trading fees
+	tests := []struct {
+		name        string
+		spendCaps   config.SpendCaps
+		priorities  map[string]int
+		doneTickers map[string]bool
+		setup       func(*mocks.MockOrderRepository)
+		want        map[string]bool
+	}{
+		{
+			name:  "ok_no_caps",
+			setup: func(orderDB *mocks.MockOrderRepository) {},
+			want:  map[string]bool{},
+		},
+		{
+			name:        "ok_all_done",
+			spendCaps:   config.SpendCaps{GlobalDailyFiatCap: 1},
+			doneTickers: map[string]bool{"BTC": true, "ETH": true},
+			setup:       func(orderDB *mocks.MockOrderRepository) {},
+			want:        map[string]bool{},
+		},
+		{
+			name:      "ok_monthly",
+			spendCaps: config.SpendCaps{MonthlyFiatCaps: map[string]float64{"BTC": 10, "ETH": 10}},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetOrdersCreatedSince(monthStart).Return([]*db.Order{
+					{Ticker: "btcsgd", CreatedForDay: monthStart, FiatDeposit: 8.998},
+					{Ticker: "ethsgd", CreatedForDay: monthStart, FiatDeposit: 4.5},
+					{Ticker: "ethsgd", CreatedForDay: yesterday, FiatDeposit: 4},
+				}, nil)
+			},
+			want: map[string]bool{"ETH": true},
+		},
+		{
+			name:       "ok_global_daily",
+			spendCaps:  config.SpendCaps{GlobalDailyFiatCap: 2.5},
+			priorities: map[string]int{"ETH": 1, "BTC": 2},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetOrdersCreatedSince(monthStart).Return([]*db.Order{
+					{Ticker: "btcsgd", CreatedForDay: yesterday, FiatDeposit: 100},
+				}, nil)
+			},
+			want: map[string]bool{"BTC": true},
+		},
+		{
+			name:        "ok_global_daily_done_ticker",
+			spendCaps:   config.SpendCaps{GlobalDailyFiatCap: 3},
+			doneTickers: map[string]bool{"ETH": true},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetOrdersCreatedSince(monthStart).Return([]*db.Order{
+					{Ticker: "ethsgd", CreatedForDay: config.TestNowDate, FiatDeposit: 2.004},
+				}, nil)
+			},
+			want: map[string]bool{"BTC": true},
+		},
+		{
+			name:      "ok_global_monthly",
+			spendCaps: config.SpendCaps{GlobalMonthlyFiatCap: 10},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetOrdersCreatedSince(monthStart).Return([]*db.Order{
+					{Ticker: "btcsgd", CreatedForDay: yesterday, FiatDeposit: 4},
+					{Ticker: "ethsgd", CreatedForDay: yesterday, FiatDeposit: 4},
+					// not a crypto ticker
+					{Ticker: "solusd", CreatedForDay: yesterday, FiatDeposit: 100},
+				}, nil)
+			},
+			want: map[string]bool{"ETH": true},
+		},
+		{
+			name:      "ok_db_error",
+			spendCaps: config.SpendCaps{GlobalMonthlyFiatCap: 10},
+			setup: func(orderDB *mocks.MockOrderRepository) {
+				orderDB.EXPECT().GetOrdersCreatedSince(monthStart).Return(nil, errors.New("error"))
+			},
+			want: map[string]bool{"BTC": true, "ETH": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.TestInit(&config.ConfigUpdateable{
+				SpendCaps:        &tt.spendCaps,
+				TickerPriorities: tt.priorities,
+			}, &config.TestNow)
+			ctrl := gomock.NewController(t)
+			mockOrderDB := mocks.NewMockOrderRepository(ctrl)
+			db.Set(mockOrderDB)
+			tt.setup(mockOrderDB)
+
+			got := getSpendCappedTickers(util.TestContext(), tt.doneTickers, config.Get().OrderMetadata.DailyFiatAmount)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
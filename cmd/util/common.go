@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 	"github.com/shopspring/decimal"
@@ -40,6 +41,23 @@ func IsTestFlow(ctx context.Context) bool {
 	return ctx.Value(contextTestKey) != nil
 }
 
+// Sleeps for the duration, or until the context is done. Returns the context's error in that case
+//
+// Does not sleep in unit tests
+func Sleep(ctx context.Context, d time.Duration) error {
+	if IsTestFlow(ctx) {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func NumDecimalPlaces(v float64) int {
 	return -int(decimal.NewFromFloat(v).Exponent())
 }
@@ -1,7 +1,10 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -110,3 +113,38 @@ func TestConvertFloatToPrecString(t *testing.T) {
 		})
 	}
 }
+
+func TestSleep(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		d       time.Duration
+		wantErr error
+	}{
+		{
+			name: "ok",
+			ctx:  context.Background(),
+			d:    time.Millisecond,
+		},
+		{
+			name: "ok_test_flow",
+			ctx:  TestContext(),
+			d:    time.Hour,
+		},
+		{
+			name:    "cancelled",
+			ctx:     cancelledCtx,
+			d:       time.Hour,
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Sleep(tt.ctx, tt.d); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sleep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
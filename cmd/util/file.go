@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Writes to a temp file in the same directory then renames it over path, so that a crash mid-write leaves either the
+// previous or the new content, never a truncated file
+func WriteFileAtomic(path string, b []byte) error {
+	location := "util.WriteFileAtomic"
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		logger.Error(location, "Failed to create temp file for '%s'", err, path)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		logger.Error(location, "Failed to replace file '%s'", err, path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jeraldyik/crypto_dca_go/internal/logger"
+)
+
+// Errors may tell whether retrying is of any use, e.g. not for insufficient funds. Other errors are retried
+type RetryableError interface {
+	Retryable() bool
+}
+
+// Errors may tell how long to wait at the least before retrying, e.g. the Retry-After of a rate limited request
+type RetryAfterError interface {
+	RetryAfterDelay() time.Duration
+}
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration // of the first retry, doubled on every retry after
+	MaxDelay    time.Duration
+	Jitter      float64 // fraction of the delay that is randomised, to spread out concurrent retries
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   1 * time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.5,
+}
+
+// Delay before the given retry (1-based), at least that of the error if it has one
+func (p RetryPolicy) delay(retry int, err error) time.Duration {
+	d := p.BaseDelay << (retry - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+
+	var retryAfterErr RetryAfterError
+	if errors.As(err, &retryAfterErr) && retryAfterErr.RetryAfterDelay() > d {
+		d = retryAfterErr.RetryAfterDelay()
+	}
+	return d
+}
+
+func isRetryable(err error) bool {
+	var retryableErr RetryableError
+	if errors.As(err, &retryableErr) {
+		return retryableErr.Retryable()
+	}
+	return true
+}
+
+// Retry calls fn with DefaultRetryPolicy until it succeeds, its error is not retryable, or the context is done
+func Retry[T any](ctx context.Context, fnName string, fn func() (T, error)) (T, error) {
+	return RetryWithPolicy(ctx, DefaultRetryPolicy, fnName, fn)
+}
+
+// Same as Retry, for functions of 2 return values besides the error
+func Retry2[T, U any](ctx context.Context, fnName string, fn func() (T, U, error)) (T, U, error) {
+	type results struct {
+		t T
+		u U
+	}
+	r, err := Retry(ctx, fnName, func() (results, error) {
+		t, u, err := fn()
+		return results{t, u}, err
+	})
+	return r.t, r.u, err
+}
+
+func RetryWithPolicy[T any](ctx context.Context, policy RetryPolicy, fnName string, fn func() (T, error)) (T, error) {
+	var zero T
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		// Not calling at all once cancelled, e.g. on shutdown
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			logger.Error(fnName, "Context is done, not calling", ctxErr)
+			return zero, ctxErr
+		}
+		var result T
+		result, err = fn()
+		retryMetrics.record(fnName, attempt, err)
+		if err == nil {
+			return result, nil
+		}
+
+		if !isRetryable(err) {
+			logger.Error(fnName, "Has some error that is not retryable", err)
+			return zero, err
+		}
+		if attempt == policy.MaxAttempts {
+			break
+		}
+
+		delay := policy.delay(attempt, err)
+		logger.Error(fnName, "Has some error, retrying in %v", err, delay)
+		if ctxErr := Sleep(ctx, delay); ctxErr != nil {
+			logger.Error(fnName, "Context is done, not retrying", ctxErr)
+			return zero, ctxErr
+		}
+	}
+
+	logger.Error(fnName, "Attempted %v times, returning error", err, policy.MaxAttempts)
+	return zero, err
+}
+
+// Counts of the calls of a function through Retry, since the process started
+type RetryMetrics struct {
+	Calls        int `json:"calls"`
+	Retries      int `json:"retries"`
+	Failures     int `json:"failures"`     // errors, including those retried
+	NotRetryable int `json:"notRetryable"` // errors that are not retryable
+}
+
+type retryMetricsByFn struct {
+	mu sync.Mutex
+	m  map[string]*RetryMetrics
+}
+
+var retryMetrics = &retryMetricsByFn{m: make(map[string]*RetryMetrics)}
+
+func (r *retryMetricsByFn) record(fnName string, attempt int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	metrics, ok := r.m[fnName]
+	if !ok {
+		metrics = &RetryMetrics{}
+		r.m[fnName] = metrics
+	}
+	if attempt == 1 {
+		metrics.Calls++
+	} else {
+		metrics.Retries++
+	}
+	if err != nil {
+		metrics.Failures++
+		if !isRetryable(err) {
+			metrics.NotRetryable++
+		}
+	}
+}
+
+// Snapshot of the retry metrics, keyed by function name
+func GetRetryMetrics() map[string]RetryMetrics {
+	retryMetrics.mu.Lock()
+	defer retryMetrics.mu.Unlock()
+	snapshot := make(map[string]RetryMetrics, len(retryMetrics.m))
+	for fnName, metrics := range retryMetrics.m {
+		snapshot[fnName] = *metrics
+	}
+	return snapshot
+}
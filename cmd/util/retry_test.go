@@ -0,0 +1,140 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRetryError struct {
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *testRetryError) Error() string {
+	return "test_retry_error"
+}
+
+func (e *testRetryError) Retryable() bool {
+	return e.retryable
+}
+
+func (e *testRetryError) RetryAfterDelay() time.Duration {
+	return e.retryAfter
+}
+
+func TestRetry(t *testing.T) {
+	ctx := TestContext()
+
+	t.Run("no_retry", func(t *testing.T) {
+		calls := 0
+		got, err := Retry(ctx, "no_retry", func() (string, error) {
+			calls++
+			return "ret1", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ret1", got)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, RetryMetrics{Calls: 1}, GetRetryMetrics()["no_retry"])
+	})
+
+	t.Run("retry_max_times_then_succeed", func(t *testing.T) {
+		calls := 0
+		got, err := Retry(ctx, "retry_max_times_then_succeed", func() (int, error) {
+			calls++
+			if calls < DefaultRetryPolicy.MaxAttempts {
+				return 0, errors.New("error")
+			}
+			return 10, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 10, got)
+		assert.Equal(t, DefaultRetryPolicy.MaxAttempts, calls)
+		assert.Equal(t, RetryMetrics{Calls: 1, Retries: 4, Failures: 4}, GetRetryMetrics()["retry_max_times_then_succeed"])
+	})
+
+	t.Run("retry_max_times_then_fail", func(t *testing.T) {
+		calls := 0
+		got, err := Retry(ctx, "retry_max_times_then_fail", func() (*int, error) {
+			calls++
+			return nil, errors.New("error")
+		})
+		assert.Error(t, err)
+		assert.Nil(t, got)
+		assert.Equal(t, DefaultRetryPolicy.MaxAttempts, calls)
+	})
+
+	t.Run("not_retryable", func(t *testing.T) {
+		calls := 0
+		_, err := Retry(ctx, "not_retryable", func() (int, error) {
+			calls++
+			return 0, &testRetryError{retryable: false}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, RetryMetrics{Calls: 1, Failures: 1, NotRetryable: 1}, GetRetryMetrics()["not_retryable"])
+	})
+
+	t.Run("retryable_wrapped", func(t *testing.T) {
+		calls := 0
+		_, err := Retry(ctx, "retryable_wrapped", func() (int, error) {
+			calls++
+			return 0, fmt.Errorf("wrapped: %w", &testRetryError{retryable: true})
+		})
+		assert.Error(t, err)
+		assert.Equal(t, DefaultRetryPolicy.MaxAttempts, calls)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		isCalled := false
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := Retry(cancelledCtx, "cancelled", func() (int, error) {
+			isCalled = true
+			return 0, nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, isCalled)
+	})
+
+	t.Run("retry2", func(t *testing.T) {
+		calls := 0
+		got1, got2, err := Retry2(ctx, "retry2", func() (string, int, error) {
+			calls++
+			if calls == 1 {
+				return "", 0, errors.New("error")
+			}
+			return "ret1", 10, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ret1", got1)
+		assert.Equal(t, 10, got2)
+	})
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second, Jitter: 0.5}
+
+	t.Run("exponential_with_jitter", func(t *testing.T) {
+		for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+			got := policy.delay(retry, errors.New("error"))
+			assert.LessOrEqual(t, got, want)
+			assert.GreaterOrEqual(t, got, want/2)
+		}
+	})
+
+	t.Run("capped", func(t *testing.T) {
+		got := policy.delay(8, errors.New("error"))
+		assert.LessOrEqual(t, got, policy.MaxDelay)
+		assert.GreaterOrEqual(t, got, policy.MaxDelay/2)
+	})
+
+	t.Run("retry_after", func(t *testing.T) {
+		got := policy.delay(1, &testRetryError{retryable: true, retryAfter: 30 * time.Second})
+		assert.Equal(t, 30*time.Second, got)
+	})
+}
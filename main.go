@@ -2,25 +2,46 @@ package main
 
 import (
 	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/jeraldyik/crypto_dca_go/cmd"
 	"github.com/jeraldyik/crypto_dca_go/cmd/config"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/db"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/fx"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/gemini"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/google_sheets"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/journal"
+	"github.com/jeraldyik/crypto_dca_go/cmd/service/kraken"
 	"github.com/jeraldyik/crypto_dca_go/cmd/service/sentry"
 	"github.com/jeraldyik/crypto_dca_go/cmd/util"
 	"github.com/jeraldyik/crypto_dca_go/internal/logger"
 )
 
+// Exit code of a run interrupted by SIGINT or SIGTERM, as shells report for SIGINT
+const exitCodeInterrupted = 130
+
 func main() {
+	os.Exit(run())
+}
+
+// Returns the exit code, after the deferred teardowns
+func run() int {
 	defer util.RecoverAndGraceFullyExit()
 	ctx := context.Background()
 
 	// setup
 	logger.Init()
 	config.MustInit()
+	journal.MustInit()
+	fx.MustInit()
 	gemini.MustInitClient()
+	gemini.MustValidateTradingPairs(ctx)
+	gemini.StartOrderEvents(ctx)
+	gemini.StartMarketData(ctx)
+	kraken.MustInitClient()
 	google_sheets.MustInit(ctx)
 	db.MustInit()
 	defer db.Close()
@@ -28,7 +49,23 @@ func main() {
 	defer sentry.Flush()
 
 	// run
+	if config.Get().Daemon.IsEnabled {
+		if err := cmd.RunDaemon(ctx); err != nil {
+			if errors.Is(err, cmd.ErrInterrupted) {
+				return exitCodeInterrupted
+			}
+			logger.Panic("main", "Daemon failed to start", err)
+		}
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	if err := cmd.Run(ctx); err != nil {
+		if errors.Is(err, cmd.ErrInterrupted) {
+			return exitCodeInterrupted
+		}
 		// sentry.CaptureErr(err)
 	}
+	return 0
 }
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	db "github.com/jeraldyik/crypto_dca_go/cmd/service/db"
@@ -35,18 +36,32 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
-// BulkInsert mocks base method.
-func (m *MockOrderRepository) BulkInsert(rows []*db.Order) error {
+// BulkUpsert mocks base method.
+func (m *MockOrderRepository) BulkUpsert(rows []*db.Order) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BulkInsert", rows)
+	ret := m.ctrl.Call(m, "BulkUpsert", rows)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// BulkInsert indicates an expected call of BulkInsert.
-func (mr *MockOrderRepositoryMockRecorder) BulkInsert(rows interface{}) *gomock.Call {
+// BulkUpsert indicates an expected call of BulkUpsert.
+func (mr *MockOrderRepositoryMockRecorder) BulkUpsert(rows interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkInsert", reflect.TypeOf((*MockOrderRepository)(nil).BulkInsert), rows)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpsert", reflect.TypeOf((*MockOrderRepository)(nil).BulkUpsert), rows)
+}
+
+// BulkUpsertCarryForwards mocks base method.
+func (m *MockOrderRepository) BulkUpsertCarryForwards(rows []*db.CarryForward) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpsertCarryForwards", rows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkUpsertCarryForwards indicates an expected call of BulkUpsertCarryForwards.
+func (mr *MockOrderRepositoryMockRecorder) BulkUpsertCarryForwards(rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpsertCarryForwards", reflect.TypeOf((*MockOrderRepository)(nil).BulkUpsertCarryForwards), rows)
 }
 
 // GetDB mocks base method.
@@ -62,3 +77,48 @@ func (mr *MockOrderRepositoryMockRecorder) GetDB() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDB", reflect.TypeOf((*MockOrderRepository)(nil).GetDB))
 }
+
+// GetOrdersCreatedForDay mocks base method.
+func (m *MockOrderRepository) GetOrdersCreatedForDay(day time.Time) ([]*db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersCreatedForDay", day)
+	ret0, _ := ret[0].([]*db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersCreatedForDay indicates an expected call of GetOrdersCreatedForDay.
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersCreatedForDay(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersCreatedForDay", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersCreatedForDay), day)
+}
+
+// GetOrdersCreatedSince mocks base method.
+func (m *MockOrderRepository) GetOrdersCreatedSince(day time.Time) ([]*db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersCreatedSince", day)
+	ret0, _ := ret[0].([]*db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersCreatedSince indicates an expected call of GetOrdersCreatedSince.
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersCreatedSince(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersCreatedSince", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersCreatedSince), day)
+}
+
+// GetRemainingCarryForwardsSince mocks base method.
+func (m *MockOrderRepository) GetRemainingCarryForwardsSince(day time.Time) ([]*db.CarryForward, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRemainingCarryForwardsSince", day)
+	ret0, _ := ret[0].([]*db.CarryForward)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRemainingCarryForwardsSince indicates an expected call of GetRemainingCarryForwardsSince.
+func (mr *MockOrderRepositoryMockRecorder) GetRemainingCarryForwardsSince(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemainingCarryForwardsSince", reflect.TypeOf((*MockOrderRepository)(nil).GetRemainingCarryForwardsSince), day)
+}